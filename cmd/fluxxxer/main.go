@@ -3,10 +3,10 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"fluxxxer/internal/app"
-	"github.com/joho/godotenv"
+	"fluxxxer/internal/cli"
+	"fluxxxer/internal/config"
 )
 
 // Version information (can be set at build time)
@@ -20,10 +20,20 @@ func main() {
 	// Try to load environment from different possible locations
 	loadEnvironment()
 
-	// Validate required environment variables
+	// Generate headlessly when asked, e.g. from scripts and pipelines
+	if cli.IsCLI(os.Args[1:]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	// Without an API URL the app starts in offline mode
 	if os.Getenv("FLUX_API_URL") == "" {
-		fmt.Fprintln(os.Stderr, "Error: FLUX_API_URL environment variable is not set")
-		fmt.Fprintln(os.Stderr, "Please set it in your .env file or environment")
+		fmt.Fprintln(os.Stderr, "Warning: FLUX_API_URL environment variable is not set")
+		fmt.Fprintln(os.Stderr, "Starting in offline mode. Set it in your .env file or environment to generate images")
+	}
+
+	// GTK ends the process when it cannot open a display, so check first
+	if err := app.CheckDisplay(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -34,45 +44,16 @@ func main() {
 	}
 }
 
-// loadEnvironment tries to load environment variables from multiple locations
+// loadEnvironment sets environment variables from the .env files found,
+// layered so more specific files override general ones
 func loadEnvironment() {
-	// Try current directory first
-	if err := godotenv.Load(); err == nil {
-		return
-	}
-
-	// Try user's home directory
-	home, err := os.UserHomeDir()
-	if err == nil {
-		homePath := filepath.Join(home, ".fluxxxer", ".env")
-		if err := godotenv.Load(homePath); err == nil {
-			return
-		}
-	}
-
-	// Try XDG config directory
-	xdgConfig := os.Getenv("XDG_CONFIG_HOME")
-	if xdgConfig == "" && home != "" {
-		xdgConfig = filepath.Join(home, ".config")
-	}
-	
-	if xdgConfig != "" {
-		xdgPath := filepath.Join(xdgConfig, "fluxxxer", ".env")
-		if err := godotenv.Load(xdgPath); err == nil {
-			return
-		}
-	}
-
-	// Try executable directory
-	execPath, err := os.Executable()
-	if err == nil {
-		execDir := filepath.Dir(execPath)
-		execEnvPath := filepath.Join(execDir, ".env")
-		if err := godotenv.Load(execEnvPath); err == nil {
-			return
-		}
+	loaded, err := config.LoadEnvFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
 
 	// Log that no .env file was found but continue anyway
-	fmt.Fprintf(os.Stderr, "Warning: No .env file found. Using environment variables.\n")
+	if len(loaded) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: No .env file found. Using environment variables.\n")
+	}
 }
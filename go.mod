@@ -5,10 +5,12 @@ go 1.23.2
 require (
 	github.com/diamondburned/gotk4/pkg v0.3.1
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/image v0.24.0
 )
 
 require (
 	github.com/KarpelesLab/weak v0.1.1 // indirect
 	go4.org/unsafe/assume-no-moving-gc v0.0.0-20231121144256-b99613f794b6 // indirect
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
 )
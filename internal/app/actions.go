@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// setupActions registers the window actions and their keyboard shortcuts
+func (a *App) setupActions() {
+	a.shortcuts = gtk.NewShortcutController()
+	a.shortcuts.SetScope(gtk.ShortcutScopeGlobal)
+	a.win.AddController(a.shortcuts)
+
+	a.addWindowAction("focus-prompt", a.focusPrompt)
+	a.addWindowAction("show-command-palette", a.showCommandPalette)
+
+	// Generating needs a backend
+	a.generateAction = a.addWindowAction("generate", a.onGenerateClicked)
+	a.generateAction.SetEnabled(!a.config.IsOffline())
+	a.multiPromptAction = a.addWindowAction("generate-prompts", a.showMultiPrompt)
+	a.multiPromptAction.SetEnabled(!a.config.IsOffline())
+
+	showMode := gio.NewSimpleAction("show-mode", glib.NewVariantType("s"))
+	showMode.ConnectActivate(func(parameter *glib.Variant) {
+		a.setMode(parameter.String())
+	})
+	a.win.AddAction(showMode)
+
+	// Duplicating is only possible once something has been generated
+	a.duplicateAction = a.addWindowAction("duplicate-last", a.duplicateLastGeneration)
+	a.duplicateAction.SetEnabled(a.lastGeneration != nil)
+
+	a.addWindowAction("reset-controls", a.resetControls)
+	a.addWindowAction("save-result", a.saveFocusedResult)
+	a.addWindowAction("toggle-favorite", a.toggleFavorite)
+	a.addWindowAction("copy-request-json", a.copyRequestJSON)
+	a.addWindowAction("show-last-response", a.showLastResponse)
+	a.addDryRunAction()
+	a.addWindowAction("edit-api-headers", a.showHeadersDialog)
+	a.addWindowAction("export-session", a.exportSession)
+	a.addWindowAction("import-session", a.importSession)
+	a.addWindowAction("stop-all", a.stopAll)
+	a.addWindowAction("show-stats", a.showStatsDialog)
+	a.addWindowAction("compare-previous", a.comparePreviousBatch)
+	a.addWindowAction("open-config-folder", a.openConfigFolder)
+	a.addWindowAction("open-cache-folder", a.openCacheFolder)
+	a.addSettingAction("prompt-after-generate", &a.settings.PromptAfterGenerate)
+	a.addSettingAction("existing-files", &a.settings.ExistingFiles)
+	a.addLowMemoryAction()
+	a.addWheelScrollAction()
+	a.addTrayAction()
+	a.addAutoSaveAction()
+	a.addBlurFlaggedAction()
+	a.addUIScaleAction()
+	a.addImageLoaderAction()
+	a.addPNGCompressionAction()
+
+	a.setupShortcutsWindow()
+
+	for _, shortcut := range windowShortcuts {
+		if shortcut.action != "" {
+			a.addShortcut(shortcut.action, shortcut.accel)
+		}
+	}
+}
+
+// addWindowAction adds a "win." action that runs activate. Its keyboard
+// shortcut, if any, comes from windowShortcuts.
+func (a *App) addWindowAction(name string, activate func()) *gio.SimpleAction {
+	action := gio.NewSimpleAction(name, nil)
+	action.ConnectActivate(func(parameter *glib.Variant) {
+		activate()
+	})
+	a.win.AddAction(action)
+	return action
+}
+
+// addShortcut binds a keyboard accelerator to the "win." action name
+func (a *App) addShortcut(name, accel string) {
+	a.shortcuts.AddShortcut(gtk.NewShortcut(
+		gtk.NewShortcutTriggerParseString(accel),
+		gtk.NewNamedAction("win."+name),
+	))
+}
+
+// addSettingAction adds a stateful action choosing the value of a string
+// setting, for radio items in menus. Changes are saved right away.
+func (a *App) addSettingAction(name string, setting *string) {
+	action := gio.NewSimpleActionStateful(
+		name,
+		glib.NewVariantType("s"),
+		glib.NewVariantString(*setting),
+	)
+	action.ConnectActivate(func(parameter *glib.Variant) {
+		action.SetState(parameter)
+		*setting = parameter.String()
+		a.saveSettings()
+	})
+	a.win.AddAction(action)
+}
+
+// saveSettings writes the settings, reporting failures in the status bar
+func (a *App) saveSettings() {
+	if err := config.SaveSettings(a.config.GetConfigDir(), a.settings); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error saving settings: %v"), err))
+	}
+}
+
+// applyPromptAfterGenerate clears or selects the prompt once a generation
+// from it has succeeded, as chosen in the settings. A prompt edited since it
+// was generated from is left alone.
+func (a *App) applyPromptAfterGenerate(typed string) {
+	if a.promptText() != typed {
+		return
+	}
+	switch a.settings.PromptAfterGenerate {
+	case config.PromptClear:
+		a.setPromptText("")
+	case config.PromptSelect:
+		a.selectPrompt()
+	}
+}
+
+// focusPrompt focuses the prompt entry and selects its text
+func (a *App) focusPrompt() {
+	if a.mode != modeGenerator {
+		a.setMode(modeGenerator)
+	}
+	a.selectPrompt()
+}
+
+// copyRequestJSON copies the request body the current prompt and settings
+// would send to the clipboard, for debugging or replaying with curl. Unless
+// a seed is kept, the body has none: generating adds a random one, which
+// cannot be known beforehand.
+func (a *App) copyRequestJSON() {
+	prompt := a.promptText()
+	if prompt == "" {
+		a.setStatus(tr("Please enter a prompt"))
+		return
+	}
+	prompt, err := expandVariables(prompt, a.variables)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		return
+	}
+
+	opts := a.selectedOptions()
+	payload, err := a.client.BuildPayload(prompt, opts)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		return
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, payload, "", "  "); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		return
+	}
+
+	message := tr("Request JSON copied to clipboard")
+	if opts.Seed == nil {
+		message = tr("Request JSON copied to clipboard without a seed; a random one is added when it is sent")
+	}
+	a.copyText(pretty.String(), message)
+}
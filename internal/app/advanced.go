@@ -0,0 +1,168 @@
+package app
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Ranges of the advanced generation parameters. Zero leaves the parameter
+// to the backend.
+const (
+	maxGuidance = 20.0
+	maxSteps    = 50
+)
+
+// Increments offered for the advanced parameters
+var (
+	guidanceStepChoices = []float64{0.01, 0.1, 0.5, 1}
+	stepsStepChoices    = []int{1, 5, 10}
+)
+
+// createAdvancedMenu creates the menu button with the guidance and inference
+// steps controls and their increments
+func (a *App) createAdvancedMenu() *gtk.MenuButton {
+	grid := gtk.NewGrid()
+	grid.SetRowSpacing(8)
+	grid.SetColumnSpacing(8)
+	grid.SetMarginTop(8)
+	grid.SetMarginBottom(8)
+	grid.SetMarginStart(8)
+	grid.SetMarginEnd(8)
+
+	a.guidanceSpin = gtk.NewSpinButton(gtk.NewAdjustment(0, 0, maxGuidance, 0.1, 1, 0), 0, 1)
+	a.guidanceSpin.SetTooltipText(tr("How closely images follow the prompt"))
+	showDefaultAtZero(a.guidanceSpin)
+
+	a.stepsSpin = gtk.NewSpinButton(gtk.NewAdjustment(0, 0, maxSteps, 1, 10, 0), 0, 0)
+	a.stepsSpin.SetTooltipText(tr("More steps add detail but take longer"))
+	showDefaultAtZero(a.stepsSpin)
+
+	guidanceStep := createStepDropDown(guidanceStepLabels(), indexOfFloat(guidanceStepChoices, a.settings.GuidanceStep))
+	guidanceStep.NotifyProperty("selected", func() {
+		a.settings.GuidanceStep = guidanceStepChoices[guidanceStep.Selected()]
+		a.applyAdvancedSteps()
+		a.saveSettings()
+	})
+
+	stepsStep := createStepDropDown(stepsStepLabels(), indexOfInt(stepsStepChoices, a.settings.StepsStep))
+	stepsStep.NotifyProperty("selected", func() {
+		a.settings.StepsStep = stepsStepChoices[stepsStep.Selected()]
+		a.applyAdvancedSteps()
+		a.saveSettings()
+	})
+
+	rows := []struct {
+		label   string
+		spin    *gtk.SpinButton
+		stepper *gtk.DropDown
+	}{
+		{"Guidance:", a.guidanceSpin, guidanceStep},
+		{"Steps:", a.stepsSpin, stepsStep},
+	}
+	for i, row := range rows {
+		label := gtk.NewLabel(tr(row.label))
+		label.SetXAlign(0)
+		grid.Attach(label, 0, i, 1, 1)
+		grid.Attach(row.spin, 1, i, 1, 1)
+		grid.Attach(gtk.NewLabel(tr("in steps of")), 2, i, 1, 1)
+		grid.Attach(row.stepper, 3, i, 1, 1)
+	}
+
+	a.applyAdvancedSteps()
+
+	popover := gtk.NewPopover()
+	popover.SetChild(grid)
+
+	menuBtn := gtk.NewMenuButton()
+	menuBtn.SetLabel(tr("Advanced"))
+	menuBtn.SetTooltipText(tr("Guidance and inference steps"))
+	menuBtn.SetPopover(popover)
+
+	return menuBtn
+}
+
+// applyAdvancedSteps sets the spin button increments and the number of
+// decimals shown from the settings
+func (a *App) applyAdvancedSteps() {
+	step := a.settings.GuidanceStep
+	a.guidanceSpin.SetIncrements(step, step*10)
+	a.guidanceSpin.SetDigits(uint(decimals(step)))
+
+	a.stepsSpin.SetIncrements(float64(a.settings.StepsStep), float64(a.settings.StepsStep*10))
+}
+
+// advancedOptions returns the guidance and steps to send, zero for the
+// backend defaults
+func (a *App) advancedOptions() (guidance float64, steps int) {
+	if a.guidanceSpin == nil || a.stepsSpin == nil {
+		return 0, 0
+	}
+	return a.guidanceSpin.Value(), a.stepsSpin.ValueAsInt()
+}
+
+// showDefaultAtZero shows "Default" in a spin button while its value is zero
+func showDefaultAtZero(spin *gtk.SpinButton) {
+	spin.ConnectOutput(func() bool {
+		if spin.Value() != 0 {
+			return false
+		}
+		spin.SetText(tr("Default"))
+		return true
+	})
+}
+
+// createStepDropDown creates a dropdown of increments with one selected
+func createStepDropDown(labels []string, selected int) *gtk.DropDown {
+	dropDown := gtk.NewDropDown(gtk.NewStringList(labels), nil)
+	dropDown.SetSelected(uint(selected))
+	return dropDown
+}
+
+func guidanceStepLabels() []string {
+	labels := make([]string, len(guidanceStepChoices))
+	for i, step := range guidanceStepChoices {
+		labels[i] = strconv.FormatFloat(step, 'f', -1, 64)
+	}
+	return labels
+}
+
+func stepsStepLabels() []string {
+	labels := make([]string, len(stepsStepChoices))
+	for i, step := range stepsStepChoices {
+		labels[i] = strconv.Itoa(step)
+	}
+	return labels
+}
+
+// indexOfFloat returns the index of v in values, or 0 if missing
+func indexOfFloat(values []float64, v float64) int {
+	for i, value := range values {
+		if math.Abs(value-v) < 1e-9 {
+			return i
+		}
+	}
+	return 0
+}
+
+// indexOfInt returns the index of v in values, or 0 if missing
+func indexOfInt(values []int, v int) int {
+	for i, value := range values {
+		if value == v {
+			return i
+		}
+	}
+	return 0
+}
+
+// decimals returns the number of decimal places needed to show step
+func decimals(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	for i := range s {
+		if s[i] == '.' {
+			return len(s) - i - 1
+		}
+	}
+	return 0
+}
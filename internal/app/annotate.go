@@ -0,0 +1,435 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of the annotation editor, like the comparison dialog
+const (
+	annotateMaxWidth  = 900
+	annotateMaxHeight = 700
+)
+
+// Annotation sizes as fractions of the shorter image side, so shapes look
+// the same on screen and in the saved image
+const (
+	annotationLineWidth = 0.006
+	annotationHeadSize  = 0.035
+	annotationTextSize  = 0.045
+)
+
+// annotationColor is the color shapes and labels are drawn in
+var annotationColor = color.RGBA{R: 0xe0, G: 0x1b, B: 0x24, A: 0xff}
+
+// annotationKind is a shape that can be drawn on a result
+type annotationKind int
+
+// The order matches the tool choices of the editor
+const (
+	annotateArrow annotationKind = iota
+	annotateRectangle
+	annotateText
+)
+
+// annotation is a shape drawn on a result image. Positions are fractions
+// of the image width and height, so they hold at any display size. Arrows
+// point from the first point to the second, rectangles span them and text
+// starts on its baseline at the first.
+type annotation struct {
+	kind           annotationKind
+	x1, y1, x2, y2 float64
+	text           string
+}
+
+// transformed returns the annotation moved along with the image by t
+func (s annotation) transformed(t imageTransform) annotation {
+	switch t {
+	case rotateClockwise:
+		s.x1, s.y1 = 1-s.y1, s.x1
+		s.x2, s.y2 = 1-s.y2, s.x2
+	case flipHorizontal:
+		s.x1, s.x2 = 1-s.x1, 1-s.x2
+	case flipVertical:
+		s.y1, s.y2 = 1-s.y1, 1-s.y2
+	}
+	return s
+}
+
+// transformAnnotations moves annotations along with the image by t
+func transformAnnotations(annotations []annotation, t imageTransform) []annotation {
+	moved := make([]annotation, len(annotations))
+	for i, s := range annotations {
+		moved[i] = s.transformed(t)
+	}
+	return moved
+}
+
+// untransformAnnotations moves annotations back to where they were before
+// transforms were applied
+func untransformAnnotations(annotations []annotation, transforms []imageTransform) []annotation {
+	for i := len(transforms) - 1; i >= 0; i-- {
+		// Three more clockwise turns undo one, flips undo themselves
+		steps := 1
+		if transforms[i] == rotateClockwise {
+			steps = 3
+		}
+		for range steps {
+			annotations = transformAnnotations(annotations, transforms[i])
+		}
+	}
+	return annotations
+}
+
+// annotationList returns a copy of the image's annotations
+func (img *resultImage) annotationList() []annotation {
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	return append([]annotation(nil), img.annotations...)
+}
+
+// setAnnotations replaces the image's annotations
+func (img *resultImage) setAnnotations(annotations []annotation) {
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	img.annotations = annotations
+}
+
+// createAnnotationOverlay puts a drawing area showing the image's
+// annotations on top of picture. The drawing area is returned to be
+// redrawn when the annotations change.
+func (a *App) createAnnotationOverlay(img *resultImage, picture *gtk.Picture) (*gtk.Overlay, *gtk.DrawingArea) {
+	area := gtk.NewDrawingArea()
+	area.SetCanTarget(false)
+	area.SetDrawFunc(func(area *gtk.DrawingArea, cr *cairo.Context, width, height int) {
+		paintable := picture.Paintable()
+		if paintable == nil {
+			return
+		}
+		// The picture fits the image inside its allocation, centered
+		x, y, w, h := containRect(paintable.IntrinsicWidth(), paintable.IntrinsicHeight(), width, height)
+		drawAnnotations(cr, img.annotationList(), x, y, w, h)
+	})
+
+	// Rotating or flipping moves the annotations with the image
+	picture.NotifyProperty("paintable", area.QueueDraw)
+
+	overlay := gtk.NewOverlay()
+	overlay.SetChild(picture)
+	overlay.AddOverlay(area)
+	return overlay, area
+}
+
+// createAnnotateButton creates the button opening the annotation editor
+// for a result, redrawing area when the annotations change
+func (a *App) createAnnotateButton(img *resultImage, area *gtk.DrawingArea) *gtk.Button {
+	annotateBtn := gtk.NewButtonWithLabel(tr("Annotate"))
+	annotateBtn.SetTooltipText(tr("Mark up the image with arrows, rectangles and labels, added to it when saving"))
+	annotateBtn.ConnectClicked(func() {
+		a.withDisplayTexture(img, func(texture *gdk.Texture) {
+			a.showAnnotator(img, texture, area.QueueDraw)
+		})
+	})
+	return annotateBtn
+}
+
+// showAnnotator shows the editor for a result's annotations. Dragging draws
+// an arrow or rectangle and clicking places the label text. The image is
+// left as it is; the annotations are only drawn into it when saving.
+func (a *App) showAnnotator(img *resultImage, texture *gdk.Texture, changed func()) {
+	width, height := fitSize(texture.Width(), texture.Height(), annotateMaxWidth, annotateMaxHeight)
+	annotations := img.annotationList()
+	var current *annotation // Shape being dragged out
+
+	picture := gtk.NewPicture()
+	picture.SetPaintable(texture)
+	picture.SetContentFit(gtk.ContentFitContain)
+	picture.SetSizeRequest(width, height)
+
+	area := gtk.NewDrawingArea()
+	area.SetDrawFunc(func(area *gtk.DrawingArea, cr *cairo.Context, areaWidth, areaHeight int) {
+		x, y, w, h := containRect(texture.Width(), texture.Height(), areaWidth, areaHeight)
+		shapes := annotations
+		if current != nil {
+			shapes = append(shapes[:len(shapes):len(shapes)], *current)
+		}
+		drawAnnotations(cr, shapes, x, y, w, h)
+	})
+
+	overlay := gtk.NewOverlay()
+	overlay.SetChild(picture)
+	overlay.AddOverlay(area)
+	overlay.SetHAlign(gtk.AlignCenter)
+	overlay.SetVAlign(gtk.AlignCenter)
+
+	toolCombo := gtk.NewDropDownFromStrings([]string{tr("Arrow"), tr("Rectangle"), tr("Text")})
+	textEntry := gtk.NewEntry()
+	textEntry.SetPlaceholderText(tr("Label text, placed where you click"))
+	textEntry.SetHExpand(true)
+	textEntry.SetSensitive(false)
+	toolCombo.NotifyProperty("selected", func() {
+		textEntry.SetSensitive(annotationKind(toolCombo.Selected()) == annotateText)
+		if textEntry.Sensitive() {
+			textEntry.GrabFocus()
+		}
+	})
+
+	undoBtn := gtk.NewButtonWithLabel(tr("Undo"))
+	clearBtn := gtk.NewButtonWithLabel(tr("Clear"))
+	updateButtons := func() {
+		undoBtn.SetSensitive(len(annotations) > 0)
+		clearBtn.SetSensitive(len(annotations) > 0)
+		area.QueueDraw()
+	}
+	undoBtn.ConnectClicked(func() {
+		if len(annotations) > 0 {
+			annotations = annotations[:len(annotations)-1]
+		}
+		updateButtons()
+	})
+	clearBtn.ConnectClicked(func() {
+		annotations = nil
+		updateButtons()
+	})
+	updateButtons()
+
+	// point converts a position on the drawing area to image fractions
+	point := func(x, y float64) (float64, float64) {
+		left, top, w, h := containRect(texture.Width(), texture.Height(), area.Width(), area.Height())
+		return math.Max(0, math.Min(1, (x-left)/w)), math.Max(0, math.Min(1, (y-top)/h))
+	}
+
+	drag := gtk.NewGestureDrag()
+	var startX, startY float64
+	drag.ConnectDragBegin(func(x, y float64) {
+		startX, startY = x, y
+		kind := annotationKind(toolCombo.Selected())
+		fx, fy := point(x, y)
+		if kind == annotateText {
+			if textEntry.Text() == "" {
+				textEntry.GrabFocus()
+				return
+			}
+			annotations = append(annotations, annotation{kind: kind, x1: fx, y1: fy, x2: fx, y2: fy, text: textEntry.Text()})
+			updateButtons()
+			return
+		}
+		current = &annotation{kind: kind, x1: fx, y1: fy, x2: fx, y2: fy}
+	})
+	drag.ConnectDragUpdate(func(offsetX, offsetY float64) {
+		if current == nil {
+			return
+		}
+		current.x2, current.y2 = point(startX+offsetX, startY+offsetY)
+		area.QueueDraw()
+	})
+	drag.ConnectDragEnd(func(offsetX, offsetY float64) {
+		if current == nil {
+			return
+		}
+		// A click without dragging draws nothing
+		if math.Hypot(offsetX, offsetY) >= 4 {
+			current.x2, current.y2 = point(startX+offsetX, startY+offsetY)
+			annotations = append(annotations, *current)
+		}
+		current = nil
+		updateButtons()
+	})
+	area.AddController(drag)
+
+	toolBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	toolBox.Append(toolCombo)
+	toolBox.Append(textEntry)
+	toolBox.Append(undoBtn)
+	toolBox.Append(clearBtn)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.Append(toolBox)
+	contentBox.Append(overlay)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Annotate Image"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Done"), int(gtk.ResponseAccept))
+	dialog.SetDefaultResponse(int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		defer dialog.Destroy()
+		if responseId != int(gtk.ResponseAccept) {
+			return
+		}
+		img.setAnnotations(annotations)
+		changed()
+		if len(annotations) > 0 {
+			a.setStatus(tr("Annotations are added to the image when it is saved"))
+		}
+	})
+	dialog.Show()
+}
+
+// containRect returns where an image of imageWidth x imageHeight is shown
+// when fitted into width x height and centered, as gtk.ContentFitContain
+// does
+func containRect(imageWidth, imageHeight, width, height int) (x, y, w, h float64) {
+	if imageWidth <= 0 || imageHeight <= 0 {
+		return 0, 0, float64(width), float64(height)
+	}
+	scale := math.Min(float64(width)/float64(imageWidth), float64(height)/float64(imageHeight))
+	w, h = float64(imageWidth)*scale, float64(imageHeight)*scale
+	return (float64(width) - w) / 2, (float64(height) - h) / 2, w, h
+}
+
+// drawAnnotations draws annotations on screen over an image shown at
+// x, y with size w x h
+func drawAnnotations(cr *cairo.Context, annotations []annotation, x, y, w, h float64) {
+	side := math.Min(w, h)
+	cr.SetSourceRGBA(
+		float64(annotationColor.R)/255,
+		float64(annotationColor.G)/255,
+		float64(annotationColor.B)/255,
+		float64(annotationColor.A)/255,
+	)
+	cr.SetLineWidth(math.Max(1, side*annotationLineWidth))
+	cr.SetLineCap(cairo.LineCapRound)
+	cr.SetLineJoin(cairo.LineJoinRound)
+	cr.SelectFontFace("Sans", cairo.FontSlantNormal, cairo.FontWeightBold)
+	cr.SetFontSize(side * annotationTextSize)
+
+	for _, s := range annotations {
+		x1, y1 := x+s.x1*w, y+s.y1*h
+		x2, y2 := x+s.x2*w, y+s.y2*h
+		switch s.kind {
+		case annotateArrow:
+			cr.MoveTo(x1, y1)
+			cr.LineTo(x2, y2)
+			for _, head := range arrowHead(x1, y1, x2, y2, side*annotationHeadSize) {
+				cr.MoveTo(x2, y2)
+				cr.LineTo(head.X, head.Y)
+			}
+			cr.Stroke()
+		case annotateRectangle:
+			cr.Rectangle(math.Min(x1, x2), math.Min(y1, y2), math.Abs(x2-x1), math.Abs(y2-y1))
+			cr.Stroke()
+		case annotateText:
+			cr.MoveTo(x1, y1)
+			cr.ShowText(s.text)
+		}
+	}
+}
+
+// arrowEnd is the outer end of a stroke of an arrow head
+type arrowEnd struct {
+	X, Y float64
+}
+
+// arrowHead returns the ends of the two strokes of an arrow head of the
+// given size at x2, y2, for an arrow coming from x1, y1
+func arrowHead(x1, y1, x2, y2, size float64) [2]arrowEnd {
+	angle := math.Atan2(y2-y1, x2-x1)
+	var head [2]arrowEnd
+	for i, spread := range []float64{math.Pi / 6, -math.Pi / 6} {
+		head[i] = arrowEnd{
+			X: x2 - size*math.Cos(angle+spread),
+			Y: y2 - size*math.Sin(angle+spread),
+		}
+	}
+	return head
+}
+
+// burnAnnotations decodes data, draws the annotations into it and
+// re-encodes it like a transform, keeping its color profile
+func burnAnnotations(data []byte, format imageFormat, annotations []annotation) ([]byte, imageFormat, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	img := toRGBA(src)
+	w, h := float64(img.Rect.Dx()), float64(img.Rect.Dy())
+	side := math.Min(w, h)
+	lineWidth := math.Max(1, side*annotationLineWidth)
+
+	var face font.Face
+	for _, s := range annotations {
+		x1, y1 := s.x1*w, s.y1*h
+		x2, y2 := s.x2*w, s.y2*h
+		switch s.kind {
+		case annotateArrow:
+			drawLine(img, x1, y1, x2, y2, lineWidth)
+			for _, head := range arrowHead(x1, y1, x2, y2, side*annotationHeadSize) {
+				drawLine(img, x2, y2, head.X, head.Y, lineWidth)
+			}
+		case annotateRectangle:
+			drawLine(img, x1, y1, x2, y1, lineWidth)
+			drawLine(img, x2, y1, x2, y2, lineWidth)
+			drawLine(img, x2, y2, x1, y2, lineWidth)
+			drawLine(img, x1, y2, x1, y1, lineWidth)
+		case annotateText:
+			if face == nil {
+				if face, err = annotationFace(side * annotationTextSize); err != nil {
+					return nil, format, err
+				}
+			}
+			drawer := font.Drawer{
+				Dst:  img,
+				Src:  image.NewUniform(annotationColor),
+				Face: face,
+				Dot:  fixed.P(int(x1), int(y1)),
+			}
+			drawer.DrawString(s.text)
+		}
+	}
+
+	return encodeImage(img, format, extractICCProfile(data))
+}
+
+// annotationFace returns the font labels are drawn in, size pixels tall
+func annotationFace(size float64) (font.Face, error) {
+	parsed, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load annotation font: %w", err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load annotation font: %w", err)
+	}
+	return face, nil
+}
+
+// drawLine draws a line with round ends into img by stamping discs along it
+func drawLine(img *image.RGBA, x1, y1, x2, y2, width float64) {
+	radius := width / 2
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := int(math.Ceil(length/math.Max(0.5, radius/2))) + 1
+	for i := 0; i < steps; i++ {
+		t := 0.0
+		if steps > 1 {
+			t = float64(i) / float64(steps-1)
+		}
+		cx, cy := x1+(x2-x1)*t, y1+(y2-y1)*t
+		for y := int(math.Floor(cy - radius)); y <= int(math.Ceil(cy+radius)); y++ {
+			for x := int(math.Floor(cx - radius)); x <= int(math.Ceil(cx+radius)); x++ {
+				if math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) <= radius && image.Pt(x, y).In(img.Rect) {
+					img.SetRGBA(x, y, annotationColor)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package app
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	"fluxxxer/internal/config"
 	"fluxxxer/internal/flux"
 	"fluxxxer/internal/upscaler"
@@ -14,15 +19,159 @@ type App struct {
 	*gtk.Application
 	win            *gtk.ApplicationWindow
 	entry          *gtk.Entry
+	promptView     *gtk.TextView       // Multi-line prompt editor
+	promptEditor   *gtk.ScrolledWindow // Shown instead of entry when expanded
 	spinner        *gtk.Spinner
+	progressBar    *gtk.ProgressBar // Replaces the spinner while the backend reports progress
 	imageBox       *gtk.Box
 	statusBar      *gtk.Label
+	copyErrorBtn   *gtk.Button
+	revealBtn      *gtk.Button
+	savedPath      string
 	currentWidth   int
+	appendToggle   *gtk.CheckButton
+	numbersToggle  *gtk.CheckButton
+	imageNumbers   []*gtk.Label
+	sweepCombo     *gtk.DropDown
+	sweepEntry     *gtk.Entry
+	
+	// Responsive layout
+	settings         config.Settings
+	compact          bool
+	optionsRow       *gtk.Box
+	resultGrids      []*resultGrid
+	resultSeparators []*gtk.Separator
+	
+	// Generation queue
+	queue         []*generationJob
+	queueRunning  int // Jobs running, at most FLUX_CONCURRENCY
+	queueList     *gtk.ListBox
+	queueExpander *gtk.Expander
+	savedQueue    []flux.QueuedGeneration // Left from the last session, not yet resumed
 	
 	// Mode tracking
-	isGeneratorMode bool
+	mode            string
+	stack           *gtk.Stack
 	generatorToggle *gtk.ToggleButton
 	upscalerToggle  *gtk.ToggleButton
+	galleryToggle   *gtk.ToggleButton
+	
+	// Keyboard shortcuts
+	shortcuts *gtk.ShortcutController
+	
+	// Last successful generation, for exact reruns
+	lastGeneration  *generationBatch
+	duplicateAction *gio.SimpleAction
+	generateAction  *gio.SimpleAction
+	
+	// Generating several prompts as one batch, offering the last ones again
+	multiPromptAction *gio.SimpleAction
+	multiPrompts      []string
+	
+	// Result last holding the keyboard focus or clicked, saved by Ctrl+S
+	focusedResult *resultImage
+	
+	// Result picked first for an overlay comparison
+	compareFirst *resultImage
+	
+	// Latest batch shown and the one before it, kept through clearing the
+	// results to compare them side by side
+	currentBatch  *shownBatch
+	previousBatch *shownBatch
+	
+	// Kept result whose seed new generations start from
+	keptBatch *generationBatch
+	keepBox   *gtk.Box
+	keepLabel *gtk.Label
+	
+	// Generation controls disabled while offline
+	generateBtn *gtk.Button
+	
+	// Edit and retry offer for prompts rejected by the safety filter
+	safetyBar   *gtk.Revealer
+	safetyLabel *gtk.Label
+	
+	// Estimated spend on paid backends
+	sessionCost float64
+	
+	// Usage statistics kept across sessions
+	stats config.Stats
+	
+	// Backend health checks
+	healthIndicator *gtk.Label
+	backendDown     bool
+	healthDelay     time.Duration
+	healthLatency   time.Duration // Response time of the last check
+	healthChecked   time.Time     // When the last check finished, zero before the first
+	
+	// Advanced generation parameters
+	guidanceSpin *gtk.SpinButton
+	stepsSpin    *gtk.SpinButton
+	
+	// Base image for image-to-image generation
+	baseImage        *baseImage
+	baseImageBtn     *gtk.MenuButton
+	baseImagePreview *gtk.Picture
+	
+	// Saved generation presets
+	presets        []config.Preset
+	presetList     *gtk.ListBox
+	presetsPopover *gtk.Popover
+
+	// Settings of the applied preset without a control of their own, used
+	// instead of the configured defaults until the controls are reset
+	presetFormat  string
+	presetQuality int
+	presetModel   string
+	
+	// Variables prompts can refer to as $name
+	variables []config.Variable
+	
+	// Prompts pinned to the bar above the entry
+	favorites    []string
+	favoritesBar *gtk.ScrolledWindow
+	favoritesBox *gtk.Box
+	favoriteBtn  *gtk.Button
+	
+	// Strip of the latest results across all generations this session
+	recentStrip *gtk.ScrolledWindow
+	recentBox   *gtk.Box
+	recentCount int
+	
+	// Results currently shown, in display order, for exporting the session
+	results []*resultImage
+	
+	// Pictures of the results, whose textures are released while scrolled
+	// far out of view in low memory mode
+	resultPictures map[*resultImage]*gtk.Picture
+	
+	// Gallery of saved images
+	galleryBox         *gtk.FlowBox
+	galleryLabel       *gtk.Label
+	galleryEntries     []galleryEntry // In the order of the images in galleryBox
+	galleryFilter      galleryFilter
+	galleryTags        []string // Offered by the tag filter, after "Any Tag"
+	galleryTagDropDown *gtk.DropDown
+	gallerySelected    map[string]bool // Images selected for finalizing, by path
+	finalizeBtn        *gtk.Button
+	finalizing         bool // Selected images are being finalized
+	
+	// Banner above the results for messages worth noticing
+	banner       *gtk.InfoBar
+	bannerLabel  *gtk.Label
+	bannerSerial int // Bumped by each message, so timeouts skip newer ones
+	
+	// Icon in the system tray, nil when not shown
+	tray *tray
+	
+	// Raw response to the last generation, for debugging
+	lastResponse *flux.RawResponse
+	
+	// Generate shows the request instead of sending it
+	dryRun bool
+	
+	// Style sheet sizing text and controls, nil until the UI is set up
+	scaleProvider *gtk.CSSProvider
 	
 	// Service clients
 	client         *flux.Client
@@ -30,6 +179,13 @@ type App struct {
 	config         *config.Config
 }
 
+// Application modes, named after their stack pages
+const (
+	modeGenerator = "generator"
+	modeUpscaler  = "upscaler"
+	modeGallery   = "gallery"
+)
+
 // New creates a new application instance
 func New() *App {
 	cfg := config.NewConfig()
@@ -39,7 +195,30 @@ func New() *App {
 		Application:     gtk.NewApplication("com.fluxxxer.app", gio.ApplicationFlagsNone),
 		client:          flux.NewClient(cfg),
 		config:          cfg,
-		isGeneratorMode: true, // Default to generator mode
+		mode:            modeGenerator, // Default to generator mode
+	}
+	
+	// Preferences saved from earlier sessions
+	settings, err := config.LoadSettings(cfg.GetConfigDir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading settings: %v\n", err)
+	}
+	app.settings = settings
+	
+	stats, err := config.LoadStats(cfg.GetConfigDir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading statistics: %v\n", err)
+	}
+	app.stats = stats
+	
+	// Predictions of asynchronous backends are kept so they can be resumed
+	if dir := cfg.GetConfigDir(); dir != "" {
+		app.client.SetPendingStore(flux.NewPendingStore(dir))
+	}
+	
+	// Without a backend only the gallery is useful
+	if cfg.IsOffline() {
+		app.mode = modeGallery
 	}
 	
 	// Initialize upscaler client if configured
@@ -53,33 +232,91 @@ func New() *App {
 	return app
 }
 
-// setStatus updates the status bar with a message
+// setStatus updates the status bar with a message. Error messages, which
+// start with "Error" or its translation, get a button to copy them.
 func (a *App) setStatus(message string) {
 	a.statusBar.SetText(message)
+	isError := strings.HasPrefix(message, "Error") || strings.HasPrefix(message, tr("Error"))
+	if a.copyErrorBtn != nil {
+		a.copyErrorBtn.SetVisible(isError)
+	}
+	if isError {
+		a.showBanner(gtk.MessageError, message)
+	}
+	if a.revealBtn != nil {
+		a.revealBtn.SetVisible(false)
+	}
 }
 
-// setMode switches between generator and upscaler modes
-func (a *App) setMode(isGeneratorMode bool) {
-	a.isGeneratorMode = isGeneratorMode
+// copyStatus copies the status bar message to the clipboard
+func (a *App) copyStatus() {
+	// Without a clipboard there is nowhere to report the failure but over
+	// the message being copied
+	if clip, err := clipboard(); err == nil {
+		clip.SetText(a.statusBar.Text())
+	}
+}
+
+// setMode switches between the generator, upscaler and gallery modes
+func (a *App) setMode(mode string) {
+	// Check if upscaler is configured
+	if mode == modeUpscaler && !a.config.IsUpscalerConfigured() {
+		a.setStatus(tr("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file."))
+		mode = modeGenerator
+	}
+	a.mode = mode
 	
 	// Update UI to reflect mode change
-	if a.generatorToggle != nil && a.upscalerToggle != nil {
-		a.generatorToggle.SetActive(isGeneratorMode)
-		a.upscalerToggle.SetActive(!isGeneratorMode)
+	if a.generatorToggle != nil && a.upscalerToggle != nil && a.galleryToggle != nil {
+		a.generatorToggle.SetActive(mode == modeGenerator)
+		a.upscalerToggle.SetActive(mode == modeUpscaler)
+		a.galleryToggle.SetActive(mode == modeGallery)
+	}
+	if a.stack != nil {
+		a.stack.SetVisibleChildName(mode)
 	}
 	
 	// Update status message
-	if isGeneratorMode {
-		a.setStatus("Image Generator Mode")
-	} else {
-		// Check if upscaler is configured
-		if a.config.IsUpscalerConfigured() {
-			a.setStatus("Image Upscaler Mode - Drag and drop an image to upscale")
+	switch mode {
+	case modeGenerator:
+		if a.config.IsOffline() {
+			a.setStatus(tr("Offline mode - set FLUX_API_URL in your .env file to generate images"))
 		} else {
-			a.setStatus("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.")
-			// Revert to generator mode if upscaler not configured
-			a.setMode(true)
+			a.setStatus(tr("Image Generator Mode"))
 		}
+	case modeUpscaler:
+		a.setStatus(tr("Image Upscaler Mode - Drag and drop an image to upscale"))
+	case modeGallery:
+		a.refreshGallery()
+	}
+}
+
+// setGenerationEnabled enables or disables the generation controls,
+// explaining why on the prompt and Generate button when disabled
+func (a *App) setGenerationEnabled(enabled bool, reason string) {
+	for _, w := range []gtk.Widgetter{aspectRatioCombo, numOutputsScale, a.appendToggle, a.sweepCombo} {
+		gtk.BaseWidget(w).SetSensitive(enabled)
+	}
+	
+	// The sweep values only apply when a sweep is selected
+	a.sweepEntry.SetSensitive(enabled && a.selectedSweepParameter() != sweepNone)
+	
+	if !enabled {
+		a.entry.SetTooltipText(reason)
+		a.generateBtn.SetTooltipText(reason)
+	} else {
+		a.entry.SetTooltipText("")
+	}
+	a.entry.SetSensitive(enabled)
+	a.promptView.SetSensitive(enabled)
+	a.generateBtn.SetSensitive(enabled)
+	if a.generateAction != nil {
+		a.generateAction.SetEnabled(enabled)
+		a.multiPromptAction.SetEnabled(enabled)
+	}
+	a.updateGenerateTooltip()
+	if a.duplicateAction != nil {
+		a.duplicateAction.SetEnabled(enabled && a.lastGeneration != nil)
 	}
 }
 
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// fileNamePromptChars is how much of the prompt a file name template uses
+const fileNamePromptChars = 48
+
+// unsafeFileNameChars matches runs of characters left out of file names
+// made from prompts
+var unsafeFileNameChars = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// templatePlaceholder matches a placeholder of the file name template
+var templatePlaceholder = regexp.MustCompile(`\{[a-z]+\}`)
+
+// repeatedSeparators matches the separators around an empty placeholder
+var repeatedSeparators = regexp.MustCompile(`([_-])[_-]+`)
+
+// addAutoSaveAction adds the toggle for saving every generated image as it
+// loads, saved with the settings
+func (a *App) addAutoSaveAction() {
+	action := gio.NewSimpleActionStateful("auto-save", nil, glib.NewVariantBoolean(a.settings.AutoSave))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.settings.AutoSave = !a.settings.AutoSave
+		action.SetState(glib.NewVariantBoolean(a.settings.AutoSave))
+		a.saveSettings()
+
+		if a.settings.AutoSave {
+			a.setStatus(fmt.Sprintf(tr("New images are saved to %s as they load"), a.config.GetOutputDir()))
+		} else {
+			a.setStatus(tr("New images are only saved when you click Save"))
+		}
+	})
+	a.win.AddAction(action)
+}
+
+// autoSaveResult writes a newly loaded result and its metadata to the
+// output directory, named from the file name template, and marks it saved
+// in box. Existing files are never replaced. Failures are reported in the
+// status bar.
+func (a *App) autoSaveResult(img *resultImage, number int, box *gtk.Box) {
+	data, format := img.saveData()
+	name := expandFileName(a.config.GetFileNameTemplate(), img.batch, number, time.Now())
+	dir := a.config.GetOutputDir()
+	copies := a.copyFormatsToSave()
+
+	go func() {
+		var err error
+		if data == nil {
+			data, format, err = a.downloadImage(img.url)
+		}
+		meta := newImageMetadata(img.batch, format)
+		path := uniquePath(filepath.Join(dir, withFormatExt(name, format)), besideExts(copies, meta != nil)...)
+		if err == nil {
+			err = writeImageFiles(path, data, copies)
+		}
+		if err == nil && meta != nil {
+			err = writeSidecar(path, meta)
+		}
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error auto-saving image: %v"), err))
+				return
+			}
+
+			savedLabel := gtk.NewLabel(tr("Saved"))
+			savedLabel.AddCSSClass("dim-label")
+			savedLabel.SetTooltipText(path)
+			box.Append(savedLabel)
+		})
+	}()
+}
+
+// expandFileName fills in the placeholders of template for image number
+// of batch, counting from 1: {date}, {time}, {seed}, {prompt}, {ratio} and
+// {n}. The seed is that of the image when the backend reported one per
+// image. Unknown placeholders are left out.
+func expandFileName(template string, batch *generationBatch, number int, now time.Time) string {
+	if template == "" {
+		template = config.DefaultFileNameTemplate
+	}
+	if batch != nil && number > 0 {
+		batch = batch.forImage(number - 1)
+	}
+
+	name := templatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		switch placeholder {
+		case "{date}":
+			return now.Format("2006-01-02")
+		case "{time}":
+			return now.Format("150405")
+		case "{n}":
+			return strconv.Itoa(number)
+		}
+		if batch == nil {
+			return ""
+		}
+		switch placeholder {
+		case "{seed}":
+			if batch.opts.Seed != nil {
+				return strconv.Itoa(*batch.opts.Seed)
+			}
+		case "{prompt}":
+			return promptFileName(batch.prompt)
+		case "{ratio}":
+			return strings.ReplaceAll(batch.opts.AspectRatio, ":", "x")
+		}
+		return ""
+	})
+
+	// Placeholders left empty must not leave separators behind, and the
+	// template must not reach outside the output directory
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.Trim(repeatedSeparators.ReplaceAllString(name, "$1"), "_-. ")
+	if name == "" {
+		return "generated_image"
+	}
+	return name
+}
+
+// promptFileName shortens prompt to the words of a file name, e.g.
+// "a-red-fox-in-the-snow"
+func promptFileName(prompt string) string {
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(strings.ToLower(prompt), "-"), "-")
+	if runes := []rune(name); len(runes) > fileNamePromptChars {
+		name = strings.TrimRight(string(runes[:fileNamePromptChars]), "-")
+	}
+	return name
+}
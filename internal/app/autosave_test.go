@@ -0,0 +1,47 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"fluxxxer/internal/flux"
+)
+
+func TestExpandFileName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 30, 12, 0, time.UTC)
+	seed := 42
+	batch := &generationBatch{
+		prompt: "A red fox, in the snow!",
+		opts:   flux.GenerateOptions{Seed: &seed, AspectRatio: "16:9"},
+	}
+	perImage := &generationBatch{
+		prompt: "fox",
+		opts:   flux.GenerateOptions{Seed: &seed},
+		seeds:  []int{7, 8},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		batch    *generationBatch
+		number   int
+		want     string
+	}{
+		{"default template", "", batch, 1, "2024-05-01_153012_42_1_a-red-fox-in-the-snow"},
+		{"per-image seed", "{seed}_{n}", perImage, 2, "8_2"},
+		{"batch seed without per-image seeds", "{seed}_{n}", batch, 3, "42_3"},
+		{"ratio", "{prompt}_{ratio}", batch, 1, "a-red-fox-in-the-snow_16x9"},
+		{"unknown placeholder", "{date}_{foo}_{n}", batch, 2, "2024-05-01_2"},
+		{"no seed", "{time}_{seed}_{n}", &generationBatch{prompt: "fox"}, 1, "153012_1"},
+		{"no batch", "{date}_{prompt}", nil, 1, "2024-05-01"},
+		{"path separators", "out/{n}", batch, 1, "out-1"},
+		{"empty", "{seed}", nil, 1, "generated_image"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandFileName(tt.template, tt.batch, tt.number, now); got != tt.want {
+				t.Errorf("expandFileName(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
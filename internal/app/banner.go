@@ -0,0 +1,67 @@
+package app
+
+import (
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// bannerTimeout is how long informational banners and warnings stay up.
+// Errors stay until dismissed.
+const bannerTimeout = 6 * time.Second
+
+// createBanner creates the banner shown above the results for errors and
+// other messages worth noticing, colored by severity. It stays hidden until
+// a message is shown.
+func (a *App) createBanner() *gtk.InfoBar {
+	a.bannerLabel = gtk.NewLabel("")
+	a.bannerLabel.SetXAlign(0)
+	a.bannerLabel.SetWrap(true)
+	a.bannerLabel.SetHExpand(true)
+	a.bannerLabel.SetSelectable(true)
+
+	a.banner = gtk.NewInfoBar()
+	a.banner.AddChild(a.bannerLabel)
+	a.banner.SetShowCloseButton(true)
+	a.banner.SetRevealed(false)
+	a.banner.ConnectResponse(func(responseId int) {
+		if responseId == int(gtk.ResponseClose) {
+			a.hideBanner()
+		}
+	})
+	// Escape while the banner has focus
+	a.banner.ConnectClose(a.hideBanner)
+
+	return a.banner
+}
+
+// showBanner shows message in the banner. Errors stay until dismissed,
+// anything else goes away on its own after a few seconds.
+func (a *App) showBanner(messageType gtk.MessageType, message string) {
+	if a.banner == nil {
+		return
+	}
+	a.bannerLabel.SetText(message)
+	a.banner.SetMessageType(messageType)
+	a.banner.SetRevealed(true)
+
+	// A newer message cancels the timeout of the one it replaces
+	a.bannerSerial++
+	if messageType == gtk.MessageError {
+		return
+	}
+	serial := a.bannerSerial
+	glib.TimeoutSecondsAdd(uint(bannerTimeout/time.Second), func() bool {
+		if a.bannerSerial == serial {
+			a.hideBanner()
+		}
+		return false
+	})
+}
+
+// hideBanner dismisses the banner
+func (a *App) hideBanner() {
+	a.bannerSerial++
+	a.banner.SetRevealed(false)
+}
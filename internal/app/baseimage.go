@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// baseImagePreviewSize is the size of the base image preview in the popover
+const baseImagePreviewSize = 160
+
+// baseImage is the image new generations start from in image-to-image mode
+type baseImage struct {
+	name   string // File name or URL shown to the user
+	value  string // Sent in the request: the URL itself or a data: URL
+	source string // URL or file path the image was loaded from
+}
+
+// createBaseImageMenu creates the menu button for choosing a base image from
+// a local file or a web URL
+func (a *App) createBaseImageMenu() *gtk.MenuButton {
+	panelBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	panelBox.SetMarginTop(8)
+	panelBox.SetMarginBottom(8)
+	panelBox.SetMarginStart(8)
+	panelBox.SetMarginEnd(8)
+
+	// Load an image from the web
+	urlBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	urlEntry := gtk.NewEntry()
+	urlEntry.SetPlaceholderText("https://example.com/image.png")
+	urlEntry.SetHExpand(true)
+	loadBtn := gtk.NewButtonWithLabel(tr("Load URL"))
+	load := func() {
+		a.loadBaseImageURL(urlEntry.Text())
+	}
+	loadBtn.ConnectClicked(load)
+	urlEntry.ConnectActivate(load)
+	urlBox.Append(urlEntry)
+	urlBox.Append(loadBtn)
+
+	fileBtn := gtk.NewButtonWithLabel(tr("Choose File..."))
+	fileBtn.ConnectClicked(a.showBaseImageChooser)
+
+	a.baseImagePreview = gtk.NewPicture()
+	a.baseImagePreview.SetCanShrink(true)
+	a.baseImagePreview.SetContentFit(gtk.ContentFitContain)
+	a.baseImagePreview.SetSizeRequest(baseImagePreviewSize, baseImagePreviewSize)
+	a.baseImagePreview.SetVisible(false)
+
+	clearBtn := gtk.NewButtonWithLabel(tr("Clear"))
+	clearBtn.ConnectClicked(a.clearBaseImage)
+
+	panelBox.Append(urlBox)
+	panelBox.Append(fileBtn)
+	panelBox.Append(a.baseImagePreview)
+	panelBox.Append(clearBtn)
+
+	popover := gtk.NewPopover()
+	popover.SetChild(panelBox)
+
+	a.baseImageBtn = gtk.NewMenuButton()
+	a.baseImageBtn.SetLabel(tr("Base Image"))
+	a.baseImageBtn.SetTooltipText(tr("Start from an existing image (image-to-image)"))
+	a.baseImageBtn.SetPopover(popover)
+
+	return a.baseImageBtn
+}
+
+// loadBaseImageURL uses the image at rawURL as the base image once it is
+// checked to be a web URL
+func (a *App) loadBaseImageURL(rawURL string) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		a.setStatus(tr("Error: enter an http or https image URL"))
+		return
+	}
+
+	a.loadBaseImage(rawURL)
+}
+
+// loadBaseImage reads the base image from source in the background and
+// uses it for new generations
+func (a *App) loadBaseImage(source string) {
+	a.setStatus(tr("Loading base image..."))
+	go func() {
+		img, data, err := a.readBaseImage(source)
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error loading base image: %v"), err))
+				return
+			}
+			a.setBaseImage(img, data)
+		})
+	}()
+}
+
+// readBaseImage reads a base image from source, an http or https URL or a
+// local file path, and returns it with the image data for previews. Photos
+// are turned upright as their EXIF orientation says. Call it off the main
+// thread.
+func (a *App) readBaseImage(source string) (*baseImage, []byte, error) {
+	var data []byte
+	var contentType string
+	var err error
+	remote := strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+	if remote {
+		data, contentType, err = a.client.Download(context.Background(), source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err == nil {
+		err = checkImageData(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, format, rotated, err := orientUpright(data, detectImageFormat(data, contentType, source))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Backends that fetch images themselves get the URL, the others the
+	// image data. A rotated image differs from the one at the URL, so its
+	// data is always sent. Local files are always sent as data.
+	if remote {
+		value := source
+		if !a.config.GetSendImageURLs() || rotated {
+			value = imageDataURL(data, format)
+		}
+		return &baseImage{name: source, value: value, source: source}, data, nil
+	}
+	return &baseImage{name: filepath.Base(source), value: imageDataURL(data, format), source: source}, data, nil
+}
+
+// showBaseImageChooser lets the user pick a local base image
+func (a *App) showBaseImageChooser() {
+	dialog := gtk.NewFileChooserNative(
+		tr("Select Base Image"),
+		&a.win.Window,
+		gtk.FileChooserActionOpen,
+		tr("_Open"),
+		tr("_Cancel"),
+	)
+	addImageFilters(dialog, imageFormats[0])
+
+	dialog.ConnectResponse(func(response int) {
+		if response == int(gtk.ResponseAccept) {
+			if file := dialog.File(); file != nil {
+				a.loadBaseImage(file.Path())
+			}
+		}
+		dialog.Destroy()
+	})
+
+	dialog.Show()
+}
+
+// setBaseImage makes img the base image for new generations and previews it
+func (a *App) setBaseImage(img *baseImage, data []byte) {
+	texture, err := newDisplayTexture(data, baseImagePreviewSize*2)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error loading base image: %v"), err))
+		return
+	}
+
+	a.baseImage = img
+	a.baseImagePreview.SetPaintable(texture)
+	a.baseImagePreview.SetVisible(true)
+	a.baseImageBtn.SetLabel(tr("Base Image ✓"))
+	a.baseImageBtn.SetTooltipText(img.name)
+	a.setStatus(fmt.Sprintf(tr("Generating from base image %s"), img.name))
+}
+
+// clearBaseImage goes back to generating from the prompt alone
+func (a *App) clearBaseImage() {
+	a.baseImage = nil
+	a.baseImagePreview.SetVisible(false)
+	a.baseImageBtn.SetLabel(tr("Base Image"))
+	a.baseImageBtn.SetTooltipText(tr("Start from an existing image (image-to-image)"))
+}
+
+// baseImageValue returns the base image as sent in requests, if any
+func (a *App) baseImageValue() string {
+	if a.baseImage == nil {
+		return ""
+	}
+	return a.baseImage.value
+}
+
+// baseImageSource returns where the base image was loaded from, if any
+func (a *App) baseImageSource() string {
+	if a.baseImage == nil {
+		return ""
+	}
+	return a.baseImage.source
+}
+
+// imageDataURL encodes image data as a base64 data: URL
+func imageDataURL(data []byte, format imageFormat) string {
+	return "data:" + format.MIME + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// Size of the images in the side by side comparison of batches
+const batchCompareImageSize = 240
+
+// shownBatch is a batch as shown in the results, with its images in the
+// order of its URLs. Images still loading or that failed to load are nil.
+type shownBatch struct {
+	batch   *generationBatch
+	results []*resultImage
+}
+
+// loaded returns the images of the batch that loaded
+func (b *shownBatch) loaded() []*resultImage {
+	var results []*resultImage
+	for _, result := range b.results {
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// startShownBatch records batch as the latest one shown, keeping the one
+// before it for comparison unless none of its images loaded
+func (a *App) startShownBatch(batch *generationBatch) *shownBatch {
+	if a.currentBatch != nil && len(a.currentBatch.loaded()) > 0 {
+		a.previousBatch = a.currentBatch
+	}
+	a.currentBatch = &shownBatch{batch: batch, results: make([]*resultImage, len(batch.urls))}
+	return a.currentBatch
+}
+
+// comparePreviousBatch shows the latest batch beside the one before it,
+// with the inputs that changed between them, to judge whether a change of
+// prompt or settings improved the results
+func (a *App) comparePreviousBatch() {
+	if a.previousBatch == nil || a.currentBatch == nil {
+		a.setStatus(tr("Generate twice to compare a batch with the previous one"))
+		return
+	}
+	previous, current := a.previousBatch, a.currentBatch
+
+	paned := gtk.NewPaned(gtk.OrientationHorizontal)
+	paned.SetStartChild(a.createBatchColumn(tr("Previous"), previous))
+	paned.SetEndChild(a.createBatchColumn(tr("Current"), current))
+	paned.SetShrinkStartChild(false)
+	paned.SetShrinkEndChild(false)
+	paned.SetWideHandle(true)
+	paned.SetVExpand(true)
+
+	changes := batchChanges(previous.batch, current.batch)
+	changesText := tr("Same prompt and settings")
+	if len(changes) > 0 {
+		changesText = fmt.Sprintf(tr("Changed: %s"), strings.Join(changes, ", "))
+	}
+	changesLabel := gtk.NewLabel(changesText)
+	changesLabel.SetWrap(true)
+	changesLabel.SetXAlign(0)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.Append(changesLabel)
+	contentBox.Append(paned)
+
+	window := gtk.NewWindow()
+	window.SetTitle(tr("Compare with Previous Batch"))
+	window.SetTransientFor(&a.win.Window)
+	window.SetDefaultSize(1100, 700)
+	window.SetChild(contentBox)
+	paned.SetPosition(1100 / 2)
+	window.Present()
+}
+
+// createBatchColumn creates one side of the batch comparison: a heading,
+// the batch's inputs and its images
+func (a *App) createBatchColumn(heading string, shown *shownBatch) *gtk.ScrolledWindow {
+	headingLabel := gtk.NewLabel(heading)
+	headingLabel.AddCSSClass("heading")
+	headingLabel.SetXAlign(0)
+
+	inputsLabel := gtk.NewLabel(batchInputs(shown.batch))
+	inputsLabel.AddCSSClass("dim-label")
+	inputsLabel.SetXAlign(0)
+	inputsLabel.SetWrap(true)
+	inputsLabel.SetWrapMode(pango.WrapWordChar)
+	inputsLabel.SetSelectable(true)
+
+	images := gtk.NewFlowBox()
+	images.SetSelectionMode(gtk.SelectionNone)
+	images.SetHomogeneous(true)
+	images.SetColumnSpacing(8)
+	images.SetRowSpacing(8)
+	images.SetMaxChildrenPerLine(4)
+	for _, result := range shown.loaded() {
+		picture := gtk.NewPicture()
+		if result.cover != nil {
+			picture.SetPaintable(result.cover)
+		} else {
+			a.withDisplayTexture(result, func(texture *gdk.Texture) {
+				picture.SetPaintable(texture)
+			})
+		}
+		picture.SetCanShrink(true)
+		picture.SetContentFit(gtk.ContentFitContain)
+		picture.SetSizeRequest(batchCompareImageSize, batchCompareImageSize)
+		picture.SetTooltipText(resultDescription(result))
+		images.Append(picture)
+	}
+
+	column := gtk.NewBox(gtk.OrientationVertical, 8)
+	column.SetMarginStart(4)
+	column.SetMarginEnd(4)
+	column.Append(headingLabel)
+	column.Append(inputsLabel)
+	column.Append(images)
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrollWin.SetChild(column)
+	scrollWin.SetSizeRequest(batchCompareImageSize, -1)
+	return scrollWin
+}
+
+// batchInputs describes the prompt and settings a batch was generated with
+func batchInputs(batch *generationBatch) string {
+	lines := []string{batch.prompt}
+	for _, input := range batchSettings(batch) {
+		lines = append(lines, input.name+": "+input.value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// batchSetting is a named setting of a batch, formatted for display
+type batchSetting struct {
+	name  string
+	value string
+}
+
+// batchSettings lists the settings a batch was generated with, leaving out
+// those left to the backend
+func batchSettings(batch *generationBatch) []batchSetting {
+	opts := batch.opts
+	settings := []batchSetting{
+		{tr("Aspect ratio"), opts.AspectRatio},
+		{tr("Images"), fmt.Sprint(len(batch.urls))},
+	}
+	if opts.Seed != nil {
+		settings = append(settings, batchSetting{tr("Seed"), fmt.Sprint(*opts.Seed)})
+	}
+	if opts.Guidance > 0 {
+		settings = append(settings, batchSetting{tr("Guidance"), fmt.Sprint(opts.Guidance)})
+	}
+	if opts.Steps > 0 {
+		settings = append(settings, batchSetting{tr("Steps"), fmt.Sprint(opts.Steps)})
+	}
+	if opts.OutputFormat != "" {
+		settings = append(settings, batchSetting{tr("Format"), opts.OutputFormat})
+	}
+	if opts.Image != "" {
+		settings = append(settings, batchSetting{tr("Base image"), tr("yes")})
+	}
+	return settings
+}
+
+// batchChanges names the inputs that differ between two batches
+func batchChanges(before, after *generationBatch) []string {
+	var changes []string
+	if before.prompt != after.prompt {
+		changes = append(changes, tr("Prompt"))
+	}
+
+	values := make(map[string]string)
+	for _, setting := range batchSettings(before) {
+		values[setting.name] = setting.value
+	}
+	seen := make(map[string]bool)
+	for _, setting := range batchSettings(after) {
+		seen[setting.name] = true
+		if values[setting.name] != setting.value {
+			changes = append(changes, setting.name)
+		}
+	}
+	for _, setting := range batchSettings(before) {
+		if !seen[setting.name] {
+			changes = append(changes, setting.name)
+		}
+	}
+	return changes
+}
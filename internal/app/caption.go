@@ -0,0 +1,76 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Caption stack pages
+const (
+	captionView = "view"
+	captionEdit = "edit"
+)
+
+// createBatchCaption creates the caption shown above a batch of results.
+// Double-clicking it edits the prompt; Enter regenerates with the edited
+// prompt and the batch's other options, Escape cancels.
+func (a *App) createBatchCaption(batch *generationBatch) *gtk.Stack {
+	text := batch.label
+	if text == "" {
+		text = batch.prompt
+	}
+
+	captionLabel := gtk.NewLabel(text)
+	captionLabel.SetXAlign(0)
+	captionLabel.SetWrap(true)
+	captionLabel.AddCSSClass("dim-label")
+	captionLabel.SetTooltipText(tr("Double-click to edit the prompt and regenerate"))
+
+	promptEntry := gtk.NewEntry()
+	promptEntry.SetText(batch.prompt)
+	promptEntry.SetHExpand(true)
+
+	caption := gtk.NewStack()
+	caption.SetMarginStart(8)
+	caption.SetMarginTop(8)
+	caption.SetHhomogeneous(false)
+	caption.AddNamed(captionLabel, captionView)
+	caption.AddNamed(promptEntry, captionEdit)
+	caption.SetVisibleChildName(captionView)
+
+	// Double-click switches to editing
+	click := gtk.NewGestureClick()
+	click.ConnectPressed(func(nPress int, x, y float64) {
+		if nPress != 2 || !a.generateBtn.Sensitive() {
+			return
+		}
+		promptEntry.SetText(batch.prompt)
+		caption.SetVisibleChildName(captionEdit)
+		promptEntry.GrabFocus()
+	})
+	captionLabel.AddController(click)
+
+	promptEntry.ConnectActivate(func() {
+		prompt := strings.TrimSpace(promptEntry.Text())
+		if prompt == "" {
+			a.setStatus(tr("Please enter a prompt"))
+			return
+		}
+		caption.SetVisibleChildName(captionView)
+		a.rerunGeneration(prompt, batch.opts)
+	})
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval, keycode uint, state gdk.ModifierType) bool {
+		if keyval != gdk.KEY_Escape {
+			return false
+		}
+		caption.SetVisibleChildName(captionView)
+		return true
+	})
+	promptEntry.AddController(keys)
+
+	return caption
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Largest size of the images in the comparison dialog
+const (
+	compareMaxWidth  = 900
+	compareMaxHeight = 700
+)
+
+// createCompareButton creates the button that picks a result for an
+// overlay comparison. The first result clicked is compared with the second.
+func (a *App) createCompareButton(img *resultImage) *gtk.Button {
+	compareBtn := gtk.NewButtonWithLabel(tr("Compare"))
+	compareBtn.SetTooltipText(tr("Compare with another result using a slider"))
+	compareBtn.ConnectClicked(func() {
+		first := a.compareFirst
+		switch {
+		case first == nil:
+			a.compareFirst = img
+			a.setStatus(tr("Click Compare on another result to compare it with this one"))
+		case first == img:
+			a.compareFirst = nil
+			a.setStatus(tr("Comparison cancelled"))
+		default:
+			a.compareFirst = nil
+			a.showComparison(first, img)
+		}
+	})
+	return compareBtn
+}
+
+// showComparison shows two results stacked in a dialog, with a divider
+// that can be dragged to reveal more of one or the other
+func (a *App) showComparison(before, after *resultImage) {
+	a.withDisplayTexture(before, func(beforeTexture *gdk.Texture) {
+		a.withDisplayTexture(after, func(afterTexture *gdk.Texture) {
+			a.showComparisonTextures(before, after, beforeTexture, afterTexture)
+		})
+	})
+}
+
+// showComparisonTextures shows the comparison of two results with their
+// display textures
+func (a *App) showComparisonTextures(before, after *resultImage, beforeTexture, afterTexture *gdk.Texture) {
+	// Both images are fitted into the same box so their pixels line up
+	width, height := fitSize(beforeTexture.Width(), beforeTexture.Height(), compareMaxWidth, compareMaxHeight)
+
+	afterPicture := gtk.NewPicture()
+	afterPicture.SetPaintable(afterTexture)
+	afterPicture.SetContentFit(gtk.ContentFitContain)
+	afterPicture.SetSizeRequest(width, height)
+
+	beforePicture := gtk.NewPicture()
+	beforePicture.SetPaintable(beforeTexture)
+	beforePicture.SetContentFit(gtk.ContentFitContain)
+	beforePicture.SetSizeRequest(width, height)
+
+	// A scrolled window without scrollbars clips the image on top to the
+	// left of the divider without scaling it
+	clip := gtk.NewScrolledWindow()
+	clip.SetPolicy(gtk.PolicyExternal, gtk.PolicyNever)
+	clip.SetChild(beforePicture)
+	clip.SetHAlign(gtk.AlignStart)
+	clip.SetCanTarget(false)
+
+	divider := gtk.NewSeparator(gtk.OrientationVertical)
+	divider.SetHAlign(gtk.AlignStart)
+	divider.SetCanTarget(false)
+
+	overlay := gtk.NewOverlay()
+	overlay.SetChild(afterPicture)
+	overlay.AddOverlay(clip)
+	overlay.AddOverlay(divider)
+	overlay.SetHAlign(gtk.AlignCenter)
+	overlay.SetVAlign(gtk.AlignCenter)
+
+	setDivider := func(x float64) {
+		x = math.Max(0, math.Min(x, float64(width)))
+		clip.SetSizeRequest(int(x), height)
+		divider.SetMarginStart(int(x))
+	}
+	setDivider(float64(width) / 2)
+
+	drag := gtk.NewGestureDrag()
+	var startX float64
+	drag.ConnectDragBegin(func(x, y float64) {
+		startX = x
+		setDivider(x)
+	})
+	drag.ConnectDragUpdate(func(offsetX, offsetY float64) {
+		setDivider(startX + offsetX)
+	})
+	overlay.AddController(drag)
+
+	caption := gtk.NewLabel(fmt.Sprintf(tr("Left: %s    Right: %s"), resultDescription(before), resultDescription(after)))
+	caption.SetWrap(true)
+	caption.AddCSSClass("dim-label")
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.Append(overlay)
+	contentBox.Append(caption)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Compare Results"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Close"), int(gtk.ResponseClose))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
+
+// resultDescription names a result by its seed, or its prompt when the seed
+// is unknown
+func resultDescription(img *resultImage) string {
+	if img.batch == nil {
+		return defaultImageName(img.url)
+	}
+	if img.batch.opts.Seed != nil {
+		return fmt.Sprintf("seed %d", *img.batch.opts.Seed)
+	}
+	return img.batch.prompt
+}
+
+// fitSize scales width x height down to fit within maxWidth x maxHeight,
+// keeping the aspect ratio
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return maxWidth, maxHeight
+	}
+	scale := math.Min(1, math.Min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height)))
+	return int(float64(width) * scale), int(float64(height) * scale)
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+)
+
+// copyFormats are the formats extra copies can be saved in. WebP is missing
+// because there is no encoder for it.
+var copyFormats = []imageFormat{imageFormats[0], imageFormats[1]}
+
+// addCopyFormatActions adds a toggle action per copy format, saved with the
+// settings, and returns the menu listing them
+func (a *App) addCopyFormatActions() *gio.Menu {
+	menu := gio.NewMenu()
+	for _, f := range copyFormats {
+		name := "also-save-as-" + strings.TrimPrefix(f.Ext, ".")
+		action := gio.NewSimpleActionStateful(name, nil, glib.NewVariantBoolean(a.savesCopyAs(f)))
+		action.ConnectActivate(func(*glib.Variant) {
+			enabled := !a.savesCopyAs(f)
+			action.SetState(glib.NewVariantBoolean(enabled))
+			a.setCopyFormat(f, enabled)
+		})
+		a.win.AddAction(action)
+		menu.Append(f.Name, "win."+name)
+	}
+	return menu
+}
+
+// savesCopyAs reports whether a copy in format f is saved beside each image
+func (a *App) savesCopyAs(f imageFormat) bool {
+	for _, mime := range a.settings.AlsoSaveAs {
+		if mime == f.MIME {
+			return true
+		}
+	}
+	return false
+}
+
+// setCopyFormat turns copies in format f on or off and saves the setting
+func (a *App) setCopyFormat(f imageFormat, enabled bool) {
+	var formats []string
+	for _, mime := range a.settings.AlsoSaveAs {
+		if mime != f.MIME {
+			formats = append(formats, mime)
+		}
+	}
+	if enabled {
+		formats = append(formats, f.MIME)
+	}
+	a.settings.AlsoSaveAs = formats
+
+	a.saveSettings()
+}
+
+// copyFormatsToSave returns the formats chosen for extra copies
+func (a *App) copyFormatsToSave() []imageFormat {
+	var formats []imageFormat
+	for _, f := range copyFormats {
+		if a.savesCopyAs(f) {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
+// writeImageFiles writes data to destPath and a copy in each of the given
+// formats beside it, named like destPath with the format's extension.
+// The image is decoded once for all copies, which keep its color profile.
+func writeImageFiles(destPath string, data []byte, copies []imageFormat) error {
+	if err := writeFileAtomic(destPath, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	// Animations and videos are kept as they are
+	if isMotion(data) {
+		return nil
+	}
+
+	primary, _ := formatForExt(filepath.Ext(destPath))
+	var img image.Image
+	var profile []byte
+	for _, f := range copies {
+		if f.MIME == primary.MIME {
+			continue
+		}
+
+		if img == nil {
+			var err error
+			if img, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+				return fmt.Errorf("failed to decode image for copies: %w", err)
+			}
+			profile = extractICCProfile(data)
+		}
+
+		encoded, _, err := encodeImage(img, f, profile)
+		if err != nil {
+			return err
+		}
+		copyPath := strings.TrimSuffix(destPath, filepath.Ext(destPath)) + f.Ext
+		if err := writeFileAtomic(copyPath, bytes.NewReader(encoded)); err != nil {
+			return fmt.Errorf("failed to save %s copy: %w", f.Name, err)
+		}
+	}
+	return nil
+}
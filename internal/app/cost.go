@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"os"
+)
+
+// setupCostEstimate keeps the Generate button tooltip showing the estimated
+// cost of the next generation. It does nothing unless a cost per image is set.
+func (a *App) setupCostEstimate() {
+	if a.config.GetCostPerImage() <= 0 {
+		return
+	}
+
+	numOutputsScale.ConnectValueChanged(a.updateGenerateTooltip)
+	a.sweepCombo.NotifyProperty("selected", a.updateGenerateTooltip)
+	a.sweepEntry.ConnectChanged(a.updateGenerateTooltip)
+
+	a.updateGenerateTooltip()
+}
+
+// costEstimate describes the estimated cost of the current settings for the
+// Generate button, or returns an empty string when costs are not tracked
+func (a *App) costEstimate() string {
+	cost := a.config.GetCostPerImage()
+	if cost <= 0 {
+		return ""
+	}
+
+	count := a.estimatedImageCount()
+	return fmt.Sprintf(tr("This will generate %d images (~%s)"), count, formatCost(float64(count)*cost))
+}
+
+// estimatedImageCount returns how many images the current settings would
+// generate, counting every run of a sweep
+func (a *App) estimatedImageCount() int {
+	opts := a.selectedOptions()
+	runs, err := a.buildSweep(opts)
+	if err != nil || len(runs) == 0 {
+		return opts.NumOutputs
+	}
+
+	count := 0
+	for _, run := range runs {
+		count += run.opts.NumOutputs
+	}
+	return count
+}
+
+// recordCost adds the cost of generated images to the session total
+func (a *App) recordCost(images int) {
+	cost := a.config.GetCostPerImage()
+	if cost <= 0 {
+		return
+	}
+
+	a.sessionCost += float64(images) * cost
+	fmt.Fprintf(os.Stderr, "Generated %d images (~%s), session total ~%s\n", images, formatCost(float64(images)*cost), formatCost(a.sessionCost))
+}
+
+// costSummary returns the session cost for status messages, or an empty
+// string when costs are not tracked
+func (a *App) costSummary() string {
+	if a.config.GetCostPerImage() <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (session total ~%s)", formatCost(a.sessionCost))
+}
+
+// formatCost formats an amount in dollars
+func formatCost(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// errNoDisplay is reported when there is no display to open windows on or
+// reach the clipboard through, as in some forwarded X or headless sessions
+var errNoDisplay = errors.New("no display is available")
+
+// CheckDisplay reports whether the GUI can open a display, so a missing one
+// is explained rather than ending the process inside GTK
+func CheckDisplay() error {
+	if !gtk.InitCheck() {
+		return fmt.Errorf("%w: set DISPLAY or WAYLAND_DISPLAY, or generate headlessly with --cli", errNoDisplay)
+	}
+	return nil
+}
+
+// clipboard returns the clipboard of the default display
+func clipboard() (*gdk.Clipboard, error) {
+	display := gdk.DisplayGetDefault()
+	if display == nil {
+		return nil, errNoDisplay
+	}
+	return display.Clipboard(), nil
+}
+
+// copyText copies text to the clipboard and reports copied in the status
+// bar, or why it could not be copied
+func (a *App) copyText(text, copied string) {
+	clip, err := clipboard()
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error copying to the clipboard: %v"), err))
+		return
+	}
+	clip.SetText(text)
+	a.setStatus(copied)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+)
+
+// addDryRunAction adds the toggle making Generate show the requests it
+// would send instead of sending them. It is not saved, so a new session
+// never silently skips generating.
+func (a *App) addDryRunAction() {
+	action := gio.NewSimpleActionStateful("dry-run", nil, glib.NewVariantBoolean(a.dryRun))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.dryRun = !a.dryRun
+		action.SetState(glib.NewVariantBoolean(a.dryRun))
+
+		if a.dryRun {
+			a.generateBtn.SetLabel(tr("Dry Run"))
+			a.setStatus(tr("Dry run: Generate shows the request without sending it"))
+		} else {
+			a.generateBtn.SetLabel(tr("Generate"))
+			a.setStatus(tr("Dry run off: Generate sends requests again"))
+		}
+	})
+	a.win.AddAction(action)
+}
+
+// showDryRun shows the request each run would send for prompt, built the
+// same way as when generating
+func (a *App) showDryRun(prompt string, runs []sweepRun) {
+	var requests []string
+	for i, run := range runs {
+		if run.prompt != "" {
+			prompt = run.prompt
+		}
+		request, err := a.client.DryRun(prompt, run.opts)
+		if err != nil {
+			a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+			return
+		}
+		if len(runs) > 1 {
+			request = fmt.Sprintf("# %d/%d %s\n%s", i+1, len(runs), run.label, request)
+		}
+		requests = append(requests, request)
+	}
+
+	text := strings.Join(requests, "\n\n")
+	if runs[0].opts.Seed == nil {
+		text += "\n\n" + tr("A random seed is added to the request when it is sent.")
+	}
+	a.showTextWindow(tr("Dry Run"), text, tr("Request copied to clipboard"))
+	a.setStatus(tr("Dry run: nothing was sent"))
+}
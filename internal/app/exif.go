@@ -0,0 +1,94 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// exifOrientationTag is the EXIF tag giving how the camera was held
+const exifOrientationTag = 0x0112
+
+// exifMarker starts JPEG APP1 segments carrying EXIF data
+var exifMarker = []byte("Exif\x00\x00")
+
+// orientationTransforms are the transforms that display an image upright for
+// each EXIF orientation. Orientation 1 is already upright.
+var orientationTransforms = map[int][]imageTransform{
+	2: {flipHorizontal},
+	3: {rotateClockwise, rotateClockwise},
+	4: {flipVertical},
+	5: {rotateClockwise, flipHorizontal}, // Transposed
+	6: {rotateClockwise},
+	7: {rotateClockwise, flipVertical}, // Transversed
+	8: {rotateClockwise, rotateClockwise, rotateClockwise},
+}
+
+// exifOrientation returns the EXIF orientation of a JPEG image, or 1 when it
+// has none
+func exifOrientation(data []byte) int {
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return 1
+	}
+
+	orientation := 1
+	forEachJPEGSegment(data, func(marker byte, segment []byte) {
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifMarker) {
+			if o, ok := tiffOrientation(segment[len(exifMarker):]); ok {
+				orientation = o
+			}
+		}
+	})
+	return orientation
+}
+
+// tiffOrientation reads the orientation entry of the first image directory
+// of the TIFF structure holding the EXIF data
+func tiffOrientation(tiff []byte) (int, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			return orientation, orientation >= 1 && orientation <= 8
+		}
+	}
+	return 0, false
+}
+
+// orientUpright applies the image's EXIF orientation to its pixels, so it
+// looks right wherever the orientation is ignored. It reports whether the
+// image changed; the new data carries no orientation and may be in a
+// different format.
+func orientUpright(data []byte, format imageFormat) ([]byte, imageFormat, bool, error) {
+	transforms, ok := orientationTransforms[exifOrientation(data)]
+	if !ok {
+		return data, format, false, nil
+	}
+
+	upright, format, err := applyTransforms(data, format, transforms)
+	if err != nil {
+		return nil, format, false, err
+	}
+	return upright, format, true, nil
+}
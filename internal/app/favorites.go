@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// favoriteChipChars is how many characters of a favorite prompt its chip shows
+const favoriteChipChars = 24
+
+// createFavoritesBar creates the row of favorite prompts shown above the
+// entry. It stays hidden while nothing is pinned.
+func (a *App) createFavoritesBar() *gtk.ScrolledWindow {
+	favorites, err := config.LoadFavorites(a.config.GetConfigDir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading favorites: %v\n", err)
+	}
+	a.favorites = favorites
+
+	a.favoritesBox = gtk.NewBox(gtk.OrientationHorizontal, 6)
+
+	a.favoritesBar = gtk.NewScrolledWindow()
+	a.favoritesBar.SetPolicy(gtk.PolicyAutomatic, gtk.PolicyNever)
+	a.favoritesBar.SetChild(a.favoritesBox)
+
+	a.refreshFavoritesBar()
+
+	return a.favoritesBar
+}
+
+// createFavoriteButton creates the star beside the prompt that pins the
+// current prompt as a favorite, or unpins it
+func (a *App) createFavoriteButton() *gtk.Button {
+	a.favoriteBtn = gtk.NewButton()
+	a.favoriteBtn.SetActionName("win.toggle-favorite")
+
+	// The star follows whichever editor is shown
+	a.entry.ConnectChanged(a.updateFavoriteButton)
+	a.promptView.Buffer().ConnectChanged(a.updateFavoriteButton)
+	a.updateFavoriteButton()
+
+	return a.favoriteBtn
+}
+
+// refreshFavoritesBar rebuilds the chips of the favorites bar
+func (a *App) refreshFavoritesBar() {
+	for child := a.favoritesBox.FirstChild(); child != nil; child = a.favoritesBox.FirstChild() {
+		a.favoritesBox.Remove(child)
+	}
+
+	for _, prompt := range a.favorites {
+		label := gtk.NewLabel(prompt)
+		label.SetEllipsize(pango.EllipsizeEnd)
+		label.SetMaxWidthChars(favoriteChipChars)
+
+		useBtn := gtk.NewButton()
+		useBtn.SetChild(label)
+		useBtn.SetTooltipText(prompt)
+		useBtn.ConnectClicked(func() {
+			a.setPromptText(prompt)
+			a.focusPromptEnd()
+		})
+
+		unpinBtn := gtk.NewButtonFromIconName("window-close-symbolic")
+		unpinBtn.SetTooltipText(tr("Remove from favorites"))
+		unpinBtn.ConnectClicked(func() {
+			a.unpinFavorite(prompt)
+		})
+
+		chip := gtk.NewBox(gtk.OrientationHorizontal, 0)
+		chip.AddCSSClass("linked")
+		chip.Append(useBtn)
+		chip.Append(unpinBtn)
+		a.favoritesBox.Append(chip)
+	}
+
+	a.favoritesBar.SetVisible(len(a.favorites) > 0)
+	a.updateFavoriteButton()
+}
+
+// updateFavoriteButton shows whether the current prompt is a favorite
+func (a *App) updateFavoriteButton() {
+	if a.favoriteBtn == nil {
+		return
+	}
+	if slices.Contains(a.favorites, strings.TrimSpace(a.promptText())) {
+		a.favoriteBtn.SetIconName("starred-symbolic")
+		a.favoriteBtn.SetTooltipText(tr("Remove the prompt from favorites"))
+		return
+	}
+	a.favoriteBtn.SetIconName("non-starred-symbolic")
+	a.favoriteBtn.SetTooltipText(tr("Pin the prompt to the favorites bar"))
+}
+
+// toggleFavorite pins the current prompt as a favorite, or unpins it when
+// it already is one
+func (a *App) toggleFavorite() {
+	prompt := strings.TrimSpace(a.promptText())
+	if prompt == "" {
+		a.setStatus(tr("Enter a prompt to pin it"))
+		return
+	}
+	if slices.Contains(a.favorites, prompt) {
+		a.unpinFavorite(prompt)
+		return
+	}
+
+	favorites := append(slices.Clip(a.favorites), prompt)
+	if a.saveFavorites(favorites) {
+		a.setStatus(tr("Pinned the prompt to favorites"))
+	}
+}
+
+// unpinFavorite removes prompt from the favorites
+func (a *App) unpinFavorite(prompt string) {
+	favorites := slices.DeleteFunc(slices.Clone(a.favorites), func(p string) bool {
+		return p == prompt
+	})
+	if a.saveFavorites(favorites) {
+		a.setStatus(tr("Removed the prompt from favorites"))
+	}
+}
+
+// saveFavorites stores favorites and shows them in the bar. It reports
+// whether they were saved.
+func (a *App) saveFavorites(favorites []string) bool {
+	if err := config.SaveFavorites(a.config.GetConfigDir(), favorites); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error saving favorites: %v"), err))
+		return false
+	}
+	a.favorites = favorites
+	a.refreshFavoritesBar()
+	return true
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Highest output quality offered when finalizing. PNG ignores it.
+const maxFinalizeQuality = 100
+
+// finalizeOptions describes how selected gallery images are generated again
+type finalizeOptions struct {
+	steps   int  // Inference steps, raised to at least the saved ones
+	quality int  // Output quality of lossy formats
+	png     bool // Ask for lossless PNG files
+	replace bool // Replace the originals instead of saving beside them
+}
+
+// finalizeOutcome counts what finalizing a selection did
+type finalizeOutcome struct {
+	improved  int // Saved at the higher settings
+	unchanged int // The backend returned the same image, so it was kept
+	failed    int
+	lastErr   error
+}
+
+// createFinalizeButton creates the gallery toolbar button that finalizes
+// the selected images
+func (a *App) createFinalizeButton() *gtk.Button {
+	a.finalizeBtn = gtk.NewButtonWithLabel(tr("Finalize Selected..."))
+	a.finalizeBtn.SetTooltipText(tr("Generate the selected images again from their metadata with more steps and higher quality"))
+	a.finalizeBtn.SetSensitive(false)
+	a.finalizeBtn.ConnectClicked(a.showFinalizeDialog)
+	return a.finalizeBtn
+}
+
+// createFinalizeCheck creates the check selecting a gallery image for
+// finalizing
+func (a *App) createFinalizeCheck(path string) *gtk.CheckButton {
+	check := gtk.NewCheckButton()
+	check.SetTooltipText(tr("Select for finalizing at higher quality"))
+	check.SetActive(a.gallerySelected[path])
+	check.ConnectToggled(func() {
+		if check.Active() {
+			a.gallerySelected[path] = true
+		} else {
+			delete(a.gallerySelected, path)
+		}
+		a.updateFinalizeButton()
+	})
+	return check
+}
+
+// updateFinalizeButton shows how many gallery images are selected
+func (a *App) updateFinalizeButton() {
+	if a.finalizeBtn == nil {
+		return
+	}
+	count := len(a.gallerySelected)
+	if count == 0 {
+		a.finalizeBtn.SetLabel(tr("Finalize Selected..."))
+	} else {
+		a.finalizeBtn.SetLabel(fmt.Sprintf(tr("Finalize %d Selected..."), count))
+	}
+	a.finalizeBtn.SetSensitive(count > 0 && !a.finalizing && !a.config.IsOffline())
+}
+
+// selectedGalleryPaths returns the selected gallery images in gallery order
+func (a *App) selectedGalleryPaths() []string {
+	var paths []string
+	for _, entry := range a.galleryEntries {
+		if a.gallerySelected[entry.path] {
+			paths = append(paths, entry.path)
+		}
+	}
+	return paths
+}
+
+// showFinalizeDialog asks how the selected gallery images are finalized
+func (a *App) showFinalizeDialog() {
+	paths := a.selectedGalleryPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	stepsSpin := gtk.NewSpinButton(gtk.NewAdjustment(maxSteps, 1, maxSteps, 1, 10, 0), 0, 0)
+	stepsSpin.SetTooltipText(tr("Images saved with more steps keep theirs"))
+	qualitySpin := gtk.NewSpinButton(gtk.NewAdjustment(maxFinalizeQuality, 1, maxFinalizeQuality, 1, 10, 0), 0, 0)
+	qualitySpin.SetTooltipText(tr("Output quality of lossy formats"))
+	pngCheck := gtk.NewCheckButtonWithLabel(tr("Ask for lossless PNG"))
+	pngCheck.SetActive(true)
+	replaceCheck := gtk.NewCheckButtonWithLabel(tr("Replace the originals"))
+	replaceCheck.SetTooltipText(tr("Otherwise the finalized images are saved beside the originals"))
+
+	grid := gtk.NewGrid()
+	grid.SetRowSpacing(8)
+	grid.SetColumnSpacing(12)
+	stepsLabel := gtk.NewLabel(tr("Steps"))
+	stepsLabel.SetXAlign(0)
+	qualityLabel := gtk.NewLabel(tr("Quality"))
+	qualityLabel.SetXAlign(0)
+	grid.Attach(stepsLabel, 0, 0, 1, 1)
+	grid.Attach(stepsSpin, 1, 0, 1, 1)
+	grid.Attach(qualityLabel, 0, 1, 1, 1)
+	grid.Attach(qualitySpin, 1, 1, 1, 1)
+
+	noteLabel := gtk.NewLabel(fmt.Sprintf(tr("Generates %d images again with their saved prompts and seeds, one at a time. Backends that ignore these settings return the same images, which are kept as they are."), len(paths)))
+	noteLabel.SetWrap(true)
+	noteLabel.SetXAlign(0)
+	noteLabel.SetMaxWidthChars(60)
+	noteLabel.AddCSSClass("dim-label")
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 12)
+	contentBox.SetMarginTop(12)
+	contentBox.SetMarginBottom(12)
+	contentBox.SetMarginStart(12)
+	contentBox.SetMarginEnd(12)
+	contentBox.Append(noteLabel)
+	contentBox.Append(grid)
+	contentBox.Append(pngCheck)
+	contentBox.Append(replaceCheck)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Finalize at Higher Quality"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Finalize"), int(gtk.ResponseAccept))
+	dialog.SetDefaultResponse(int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		if responseId == int(gtk.ResponseAccept) {
+			a.finalizeGalleryImages(paths, finalizeOptions{
+				steps:   stepsSpin.ValueAsInt(),
+				quality: qualitySpin.ValueAsInt(),
+				png:     pngCheck.Active(),
+				replace: replaceCheck.Active(),
+			})
+		}
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
+
+// finalizeGalleryImages generates the images at paths again in the
+// background, one at a time, and saves the improved versions. Images that
+// fail are counted and skipped.
+func (a *App) finalizeGalleryImages(paths []string, fopts finalizeOptions) {
+	a.finalizing = true
+	a.updateFinalizeButton()
+	go func() {
+		var outcome finalizeOutcome
+		for i, path := range paths {
+			glib.IdleAdd(func() {
+				a.setStatus(fmt.Sprintf(tr("Finalizing %d of %d: %s..."), i+1, len(paths), filepath.Base(path)))
+			})
+			improved, err := a.finalizeImage(path, fopts)
+			switch {
+			case err != nil:
+				outcome.failed++
+				outcome.lastErr = fmt.Errorf("%s: %w", filepath.Base(path), err)
+			case improved:
+				outcome.improved++
+			default:
+				outcome.unchanged++
+			}
+		}
+
+		glib.IdleAdd(func() {
+			a.finalizing = false
+			a.refreshGallery()
+			a.setStatus(outcome.summary())
+		})
+	}()
+}
+
+// summary describes the outcome of finalizing
+func (o *finalizeOutcome) summary() string {
+	message := fmt.Sprintf(tr("Finalized %d images"), o.improved)
+	if o.unchanged > 0 {
+		message += fmt.Sprintf(tr(", %d unchanged because the backend ignored the higher settings"), o.unchanged)
+	}
+	if o.failed > 0 {
+		message = fmt.Sprintf(tr("Error finalizing %d images: %v"), o.failed, o.lastErr) + "; " + message
+	}
+	return message
+}
+
+// finalizeImage generates the saved image at path again with the higher
+// settings of fopts and saves the result. It reports false, keeping the
+// original, when the backend returns the same image. Call it off the main
+// thread.
+func (a *App) finalizeImage(path string, fopts finalizeOptions) (improved bool, err error) {
+	defer recoverAsError(&err)
+
+	meta := readSidecar(path)
+	if meta == nil || meta.Prompt == "" {
+		return false, errors.New("no saved prompt")
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	data, format, opts, err := a.regenerateImage(meta, func(opts *flux.GenerateOptions) {
+		opts.Steps = max(meta.Steps, fopts.steps)
+		opts.Quality = max(opts.Quality, fopts.quality)
+		if fopts.png {
+			opts.OutputFormat = "png"
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// A backend ignoring the settings returns the same image for the same
+	// seed, which is not worth a second file
+	if result, err := compareImageData(original, data); err == nil && (result.sameBytes || result.changed == 0) {
+		return false, nil
+	}
+
+	// Only the original itself is replaced. Another image already having
+	// the new name, such as x.png when x.jpg becomes a PNG, is kept.
+	destPath := withFormatExt(path, format)
+	if !fopts.replace {
+		destPath = strings.TrimSuffix(path, filepath.Ext(path)) + "-hq" + format.Ext
+	}
+	if destPath != path {
+		destPath = uniquePath(destPath, ".json")
+	}
+	if err := writeImageFiles(destPath, data, nil); err != nil {
+		return false, err
+	}
+
+	meta.Format = format.MIME
+	meta.Steps = opts.Steps
+	meta.CreatedAt = time.Now()
+	if err := writeSidecar(destPath, meta); err != nil {
+		return false, err
+	}
+
+	// Replacing a file of another format leaves the original behind
+	if fopts.replace && destPath != path {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return true, fmt.Errorf("failed to remove original: %w", err)
+		}
+	}
+	return true, nil
+}
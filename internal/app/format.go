@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// imageFormat describes an image file type the app can save
+type imageFormat struct {
+	Name     string   // Display name, e.g. "PNG"
+	MIME     string   // MIME type, e.g. "image/png"
+	Ext      string   // Preferred file extension including the dot
+	Patterns []string // File chooser glob patterns
+}
+
+// imageFormats lists the supported image formats, PNG first as the default
+var imageFormats = []imageFormat{
+	{Name: "PNG", MIME: "image/png", Ext: ".png", Patterns: []string{"*.png"}},
+	{Name: "JPEG", MIME: "image/jpeg", Ext: ".jpg", Patterns: []string{"*.jpg", "*.jpeg"}},
+	{Name: "WebP", MIME: "image/webp", Ext: ".webp", Patterns: []string{"*.webp"}},
+}
+
+// detectImageFormat determines the format of image data by sniffing its bytes,
+// falling back to the Content-Type header and then the URL extension
+func detectImageFormat(data []byte, contentType, url string) imageFormat {
+	if len(data) > 0 {
+		if f, ok := formatForMIME(http.DetectContentType(data)); ok {
+			return f
+		}
+	}
+
+	if f, ok := formatForMIME(contentType); ok {
+		return f
+	}
+
+	if f, ok := formatForExt(path.Ext(url)); ok {
+		return f
+	}
+
+	return imageFormats[0]
+}
+
+// checkImageData returns an informative error when data is clearly not an
+// image or video, such as an HTML error page or a JSON error served with status 200.
+// Unrecognized binary data is allowed through for the image loader to try.
+func checkImageData(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("expected image, got an empty response")
+	}
+
+	// DetectContentType considers at most the first 512 bytes
+	contentType := http.DetectContentType(data)
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	if strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "video/") || mediaType == "application/octet-stream" {
+		return nil
+	}
+
+	hint := ""
+	switch {
+	case mediaType == "text/html":
+		hint = " — the URL may be an error page"
+	case strings.HasPrefix(mediaType, "text/"):
+		hint = fmt.Sprintf(": %q", snippet(data, 120))
+	}
+	return fmt.Errorf("expected image, got %s%s", mediaType, hint)
+}
+
+// snippet returns up to n bytes of data as trimmed text for error messages
+func snippet(data []byte, n int) string {
+	if len(data) > n {
+		data = data[:n]
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// formatForMIME returns the format matching a MIME type, ignoring parameters
+func formatForMIME(mimeType string) (imageFormat, bool) {
+	mimeType = strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0])
+	for _, f := range knownFormats() {
+		if strings.EqualFold(f.MIME, mimeType) {
+			return f, true
+		}
+	}
+	return imageFormat{}, false
+}
+
+// formatName returns the display name of an output format such as "webp",
+// or the format itself if it is unknown
+func formatName(format string) string {
+	if f, ok := formatForExt("." + format); ok {
+		return f.Name
+	}
+	return format
+}
+
+// formatForExt returns the format matching a file extension such as ".jpeg"
+func formatForExt(ext string) (imageFormat, bool) {
+	ext = strings.ToLower(ext)
+	for _, f := range knownFormats() {
+		for _, pattern := range f.Patterns {
+			if strings.TrimPrefix(pattern, "*") == ext {
+				return f, true
+			}
+		}
+	}
+	return imageFormat{}, false
+}
+
+// withDetectedExt adds the format's extension to path unless the user chose
+// an image extension explicitly
+func withDetectedExt(path string, format imageFormat) string {
+	if _, ok := formatForExt(filepath.Ext(path)); ok {
+		return path
+	}
+	return path + format.Ext
+}
+
+// detectFileFormat determines the format of the image file at path from its
+// first bytes, falling back to its extension
+func detectFileFormat(path string) imageFormat {
+	f, err := os.Open(path)
+	if err != nil {
+		return detectImageFormat(nil, "", path)
+	}
+	defer f.Close()
+
+	// DetectContentType considers at most the first 512 bytes
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return detectImageFormat(head[:n], "", path)
+}
+
+// withFormatExt replaces a file name's image extension with the format's extension
+func withFormatExt(name string, format imageFormat) string {
+	if _, ok := formatForExt(filepath.Ext(name)); ok {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name + format.Ext
+}
+
+// addImageFilters adds an "All images" filter and one filter per format,
+// selecting the filter for the primary format
+func addImageFilters(dialog *gtk.FileChooserNative, primary imageFormat) {
+	allFilter := gtk.NewFileFilter()
+	allFilter.SetName(tr("All images"))
+	for _, f := range imageFormats {
+		for _, pattern := range f.Patterns {
+			allFilter.AddPattern(pattern)
+		}
+	}
+	dialog.AddFilter(allFilter)
+
+	for _, f := range imageFormats {
+		filter := gtk.NewFileFilter()
+		filter.SetName(f.Name + " images")
+		for _, pattern := range f.Patterns {
+			filter.AddPattern(pattern)
+		}
+		dialog.AddFilter(filter)
+
+		if f.MIME == primary.MIME {
+			dialog.SetFilter(filter)
+		}
+	}
+
+	// Animations and videos are only saved in their own format
+	if slices.ContainsFunc(motionFormats, func(f imageFormat) bool { return f.MIME == primary.MIME }) {
+		filter := gtk.NewFileFilter()
+		filter.SetName(primary.Name + " files")
+		for _, pattern := range primary.Patterns {
+			filter.AddPattern(pattern)
+		}
+		dialog.AddFilter(filter)
+		dialog.SetFilter(filter)
+	}
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// galleryItemSize is the size of each image in the gallery grid
+const galleryItemSize = 200
+
+// createGalleryView creates the view listing images saved in the output directory
+func (a *App) createGalleryView() *gtk.Box {
+	galleryView := gtk.NewBox(gtk.OrientationVertical, 8)
+	galleryView.SetHExpand(true)
+	galleryView.SetVExpand(true)
+
+	// Toolbar with the gallery folder and actions
+	toolbar := gtk.NewBox(gtk.OrientationHorizontal, 8)
+
+	a.galleryLabel = gtk.NewLabel("")
+	a.galleryLabel.SetXAlign(0)
+	a.galleryLabel.SetHExpand(true)
+	a.galleryLabel.SetEllipsize(pango.EllipsizeMiddle)
+
+	refreshBtn := gtk.NewButtonWithLabel(tr("Refresh"))
+	refreshBtn.ConnectClicked(a.refreshGallery)
+
+	openFolderBtn := gtk.NewButtonWithLabel(tr("Open Folder"))
+	openFolderBtn.ConnectClicked(func() {
+		gtk.ShowURI(&a.win.Window, gio.NewFileForPath(a.config.GetOutputDir()).URI(), 0)
+	})
+
+	toolbar.Append(a.galleryLabel)
+	toolbar.Append(a.createFinalizeButton())
+	toolbar.Append(refreshBtn)
+	toolbar.Append(openFolderBtn)
+
+	// Grid of saved images
+	a.galleryBox = gtk.NewFlowBox()
+	a.galleryBox.SetSelectionMode(gtk.SelectionNone)
+	a.galleryBox.SetHomogeneous(true)
+	a.galleryBox.SetColumnSpacing(16)
+	a.galleryBox.SetRowSpacing(16)
+	a.galleryBox.SetMaxChildrenPerLine(8)
+	a.galleryBox.SetVAlign(gtk.AlignStart)
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetChild(a.galleryBox)
+	scrollWin.SetVExpand(true)
+	scrollWin.SetHExpand(true)
+
+	galleryView.Append(toolbar)
+	galleryView.Append(a.createGalleryFilters())
+	galleryView.Append(scrollWin)
+
+	return galleryView
+}
+
+// refreshGallery reloads the images shown in the gallery
+func (a *App) refreshGallery() {
+	a.galleryBox.RemoveAll()
+	a.galleryEntries = nil
+	a.gallerySelected = map[string]bool{}
+	a.updateFinalizeButton()
+
+	dir := a.config.GetOutputDir()
+	a.galleryLabel.SetText(dir)
+
+	paths, err := galleryImages(dir)
+	if err != nil && !os.IsNotExist(err) {
+		a.setStatus(fmt.Sprintf(tr("Error reading gallery: %v"), err))
+		return
+	}
+
+	if len(paths) == 0 {
+		a.refreshGalleryTags()
+		a.setStatus(fmt.Sprintf(tr("Gallery - no saved images in %s"), dir))
+		return
+	}
+
+	ratios := a.config.GetSupportedAspectRatios()
+	for _, path := range paths {
+		entry := newGalleryEntry(path, ratios)
+		a.galleryEntries = append(a.galleryEntries, entry)
+		a.addGalleryItem(entry)
+	}
+	a.refreshGalleryTags()
+	a.setGalleryFilter(a.galleryFilter)
+}
+
+// addGalleryItem adds a saved image to the gallery, loading it in the background
+func (a *App) addGalleryItem(entry galleryEntry) {
+	path := entry.path
+	itemBox := gtk.NewBox(gtk.OrientationVertical, 4)
+
+	// Add a placeholder while loading
+	placeholder := gtk.NewSpinner()
+	placeholder.Start()
+	placeholder.SetSizeRequest(galleryItemSize, galleryItemSize)
+	itemBox.Append(placeholder)
+
+	nameLabel := gtk.NewLabel(filepath.Base(path))
+	nameLabel.SetEllipsize(pango.EllipsizeMiddle)
+	nameLabel.SetMaxWidthChars(24)
+	nameLabel.SetTooltipText(path)
+	itemBox.Append(nameLabel)
+
+	tagsLabel := gtk.NewLabel(formatTags(entry.tags))
+	tagsLabel.AddCSSClass("dim-label")
+	tagsLabel.SetEllipsize(pango.EllipsizeEnd)
+	tagsLabel.SetMaxWidthChars(24)
+	tagsLabel.SetVisible(len(entry.tags) > 0)
+	itemBox.Append(tagsLabel)
+
+	a.galleryBox.Append(itemBox)
+
+	go func() {
+		texture, err := loadThumbnail(a.config.GetCacheDir(), path)
+		glib.IdleAdd(func() {
+			itemBox.Remove(placeholder)
+
+			if err != nil {
+				errorLabel := gtk.NewLabel(fmt.Sprintf(tr("Error: %v"), err))
+				errorLabel.SetWrap(true)
+				errorLabel.SetSizeRequest(galleryItemSize, galleryItemSize)
+				itemBox.Prepend(errorLabel)
+				return
+			}
+
+			picture := gtk.NewPicture()
+			picture.SetPaintable(texture)
+			picture.SetCanShrink(true)
+			picture.SetContentFit(gtk.ContentFitContain)
+			picture.SetSizeRequest(galleryItemSize, galleryItemSize)
+			picture.SetTooltipText(tr("Click to view full size"))
+			itemBox.Prepend(picture)
+
+			// The full resolution image is only loaded when clicked
+			click := gtk.NewGestureClick()
+			click.ConnectReleased(func(nPress int, x, y float64) {
+				a.showGalleryImage(path)
+			})
+			picture.AddController(click)
+
+			// Copy buttons; the prompt is only known from the metadata
+			buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 4)
+			buttonBox.SetHAlign(gtk.AlignCenter)
+			copyBtn := gtk.NewButtonWithLabel(tr("Copy"))
+			copyBtn.ConnectClicked(func() {
+				a.withFullImage(path, a.copyImageToClipboard)
+			})
+			buttonBox.Append(copyBtn)
+			if entry.prompt != "" {
+				buttonBox.Append(a.createCopyPromptButton(entry.prompt))
+			}
+			tagsBtn := gtk.NewButtonWithLabel(tr("Tags"))
+			tagsBtn.SetTooltipText(tr("Edit the tags saved with this image"))
+			tagsBtn.ConnectClicked(func() {
+				a.editGalleryTags(path, func(tags []string) {
+					tagsLabel.SetText(formatTags(tags))
+					tagsLabel.SetVisible(len(tags) > 0)
+				})
+			})
+			buttonBox.Append(tagsBtn)
+			if entry.prompt != "" {
+				verifyBtn := gtk.NewButtonWithLabel(tr("Verify"))
+				verifyBtn.SetTooltipText(tr("Generate this image again from its saved seed and settings to check that the backend reproduces it"))
+				verifyBtn.ConnectClicked(func() {
+					a.verifyGalleryImage(path)
+				})
+				buttonBox.Append(verifyBtn)
+				buttonBox.Append(a.createFinalizeCheck(path))
+			}
+			itemBox.Append(buttonBox)
+		})
+	}()
+}
+
+// withFullImage loads the full resolution image at path in the background
+// and passes its texture to fn on the main thread
+func (a *App) withFullImage(path string, fn func(texture *gdk.Texture)) {
+	go func() {
+		texture, err := loadTextureFromFile(path)
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error loading image: %v"), err))
+				return
+			}
+			fn(texture)
+		})
+	}()
+}
+
+// showGalleryImage shows a saved image at full resolution in a dialog
+func (a *App) showGalleryImage(path string) {
+	a.withFullImage(path, func(texture *gdk.Texture) {
+		a.showImageViewer(filepath.Base(path), texture)
+	})
+}
+
+// galleryImages lists the image files in dir, newest first
+func galleryImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type galleryFile struct {
+		path    string
+		modTime int64
+	}
+
+	var files []galleryFile
+	for _, entry := range entries {
+		if entry.IsDir() || !isImageFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, galleryFile{
+			path:    filepath.Join(dir, entry.Name()),
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime > files[j].modTime
+	})
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
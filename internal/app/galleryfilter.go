@@ -0,0 +1,230 @@
+package app
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// galleryEntry is a saved image in the gallery with what it can be
+// filtered by
+type galleryEntry struct {
+	path        string
+	prompt      string   // From the metadata sidecar, empty without one
+	tags        []string // From the metadata sidecar
+	aspectRatio string   // Closest supported ratio, empty if none is close
+	format      string   // MIME type
+	created     time.Time
+}
+
+// galleryFilter narrows the gallery to the entries matching all of its set
+// fields
+type galleryFilter struct {
+	text        string
+	tag         string
+	aspectRatio string
+	format      string
+	since       time.Time
+}
+
+// galleryDateRanges are the date ranges the gallery can be narrowed to, as
+// the age of the oldest image shown. Zero shows all images.
+var galleryDateRanges = []struct {
+	label string
+	age   time.Duration
+}{
+	{"Any Time", 0},
+	{"Today", 24 * time.Hour},
+	{"Past Week", 7 * 24 * time.Hour},
+	{"Past Month", 30 * 24 * time.Hour},
+}
+
+// aspectRatioTolerance is how far an image's ratio may be from a supported
+// aspect ratio to count as that ratio, relative to the ratio
+const aspectRatioTolerance = 0.03
+
+// createGalleryFilters creates the row of controls that filter the gallery
+// as they change
+func (a *App) createGalleryFilters() *gtk.Box {
+	filterBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+
+	search := gtk.NewSearchEntry()
+	search.SetPlaceholderText(tr("Filter by prompt, name or tag"))
+	search.SetHExpand(true)
+
+	ratios := a.config.GetSupportedAspectRatios()
+	ratioDropDown := gtk.NewDropDown(gtk.NewStringList(append([]string{tr("Any Ratio")}, ratios...)), nil)
+
+	formatLabels := []string{tr("Any Format")}
+	for _, f := range imageFormats {
+		formatLabels = append(formatLabels, f.Name)
+	}
+	formatDropDown := gtk.NewDropDown(gtk.NewStringList(formatLabels), nil)
+
+	dateLabels := make([]string, len(galleryDateRanges))
+	for i, r := range galleryDateRanges {
+		dateLabels[i] = tr(r.label)
+	}
+	dateDropDown := gtk.NewDropDown(gtk.NewStringList(dateLabels), nil)
+
+	// Filled with the tags of the images when the gallery is refreshed
+	a.galleryTagDropDown = gtk.NewDropDown(nil, nil)
+	a.refreshGalleryTags()
+
+	update := func() {
+		filter := galleryFilter{text: strings.ToLower(strings.TrimSpace(search.Text()))}
+		if i := int(a.galleryTagDropDown.Selected()); i > 0 && i <= len(a.galleryTags) {
+			filter.tag = a.galleryTags[i-1]
+		}
+		if i := int(ratioDropDown.Selected()); i > 0 {
+			filter.aspectRatio = ratios[i-1]
+		}
+		if i := int(formatDropDown.Selected()); i > 0 {
+			filter.format = imageFormats[i-1].MIME
+		}
+		if age := galleryDateRanges[dateDropDown.Selected()].age; age > 0 {
+			filter.since = time.Now().Add(-age)
+		}
+		a.setGalleryFilter(filter)
+	}
+	search.ConnectSearchChanged(update)
+	ratioDropDown.NotifyProperty("selected", update)
+	formatDropDown.NotifyProperty("selected", update)
+	dateDropDown.NotifyProperty("selected", update)
+	a.galleryTagDropDown.NotifyProperty("selected", update)
+
+	filterBox.Append(search)
+	filterBox.Append(a.galleryTagDropDown)
+	filterBox.Append(ratioDropDown)
+	filterBox.Append(formatDropDown)
+	filterBox.Append(dateDropDown)
+
+	// Children are appended in the order of the entries, so their index
+	// finds the entry
+	a.galleryBox.SetFilterFunc(func(child *gtk.FlowBoxChild) bool {
+		i := child.Index()
+		return i < 0 || i >= len(a.galleryEntries) || a.galleryFilter.matches(a.galleryEntries[i])
+	})
+
+	return filterBox
+}
+
+// refreshGalleryTags offers the tags of the gallery's images in the tag
+// filter, keeping the selected tag while any image still has it
+func (a *App) refreshGalleryTags() {
+	selected := a.galleryFilter.tag
+
+	var tags []string
+	for _, entry := range a.galleryEntries {
+		tags = append(tags, entry.tags...)
+	}
+	tags = sortTags(normalizeTags(tags))
+	a.galleryTags = tags
+
+	a.galleryTagDropDown.SetModel(gtk.NewStringList(append([]string{tr("Any Tag")}, tags...)))
+	for i, tag := range tags {
+		if strings.EqualFold(tag, selected) {
+			a.galleryTagDropDown.SetSelected(uint(i + 1))
+			break
+		}
+	}
+}
+
+// setGalleryFilter shows only the gallery images matching filter
+func (a *App) setGalleryFilter(filter galleryFilter) {
+	a.galleryFilter = filter
+	a.galleryBox.InvalidateFilter()
+
+	shown := 0
+	for _, entry := range a.galleryEntries {
+		if filter.matches(entry) {
+			shown++
+		}
+	}
+	if shown == len(a.galleryEntries) {
+		a.setStatus(fmt.Sprintf(tr("Gallery - %d saved images"), len(a.galleryEntries)))
+	} else {
+		a.setStatus(fmt.Sprintf(tr("Gallery - %d of %d saved images match"), shown, len(a.galleryEntries)))
+	}
+}
+
+// matches reports whether entry passes every filter that is set
+func (f galleryFilter) matches(entry galleryEntry) bool {
+	if f.text != "" &&
+		!strings.Contains(strings.ToLower(entry.prompt), f.text) &&
+		!strings.Contains(strings.ToLower(filepath.Base(entry.path)), f.text) &&
+		!strings.Contains(strings.ToLower(formatTags(entry.tags)), f.text) {
+		return false
+	}
+	if f.tag != "" && !hasTag(entry.tags, f.tag) {
+		return false
+	}
+	if f.aspectRatio != "" && entry.aspectRatio != f.aspectRatio {
+		return false
+	}
+	if f.format != "" && entry.format != f.format {
+		return false
+	}
+	if !f.since.IsZero() && entry.created.Before(f.since) {
+		return false
+	}
+	return true
+}
+
+// newGalleryEntry describes the image at path from its metadata sidecar,
+// falling back to the file itself for images saved without one
+func newGalleryEntry(path string, ratios []string) galleryEntry {
+	entry := galleryEntry{path: path}
+	if f, ok := formatForExt(filepath.Ext(path)); ok {
+		entry.format = f.MIME
+	}
+	if info, err := os.Stat(path); err == nil {
+		entry.created = info.ModTime()
+	}
+
+	if meta := readSidecar(path); meta != nil {
+		entry.prompt = meta.Prompt
+		entry.tags = normalizeTags(meta.Tags)
+		entry.aspectRatio = meta.AspectRatio
+		if !meta.CreatedAt.IsZero() {
+			entry.created = meta.CreatedAt
+		}
+	}
+
+	if entry.aspectRatio == "" {
+		if f, err := os.Open(path); err == nil {
+			if cfg, _, err := image.DecodeConfig(f); err == nil {
+				entry.aspectRatio = closestAspectRatio(cfg.Width, cfg.Height, ratios)
+			}
+			f.Close()
+		}
+	}
+	return entry
+}
+
+// closestAspectRatio returns the ratio of the form "16:9" in ratios closest
+// to width:height, or an empty string if none is within the tolerance
+func closestAspectRatio(width, height int, ratios []string) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	actual := float64(width) / float64(height)
+
+	best, bestDiff := "", aspectRatioTolerance
+	for _, ratio := range ratios {
+		var w, h float64
+		if _, err := fmt.Sscanf(ratio, "%g:%g", &w, &h); err != nil || h == 0 {
+			continue
+		}
+		if diff := math.Abs(actual-w/h) / (w / h); diff <= bestDiff {
+			best, bestDiff = ratio, diff
+		}
+	}
+	return best
+}
@@ -1,12 +1,15 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	neturl "net/url"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
+	"strconv"
 
 	"fluxxxer/internal/flux"
 
@@ -18,16 +21,51 @@ import (
 
 // onGenerateClicked handles the generate button click event
 func (a *App) onGenerateClicked() {
-	prompt := a.entry.Text()
+	prompt := a.promptText()
 	if prompt == "" {
-		a.setStatus("Please enter a prompt")
+		a.setStatus(tr("Please enter a prompt"))
 		return
 	}
+	a.hideSafetyRetry()
 
-	a.spinner.Start()
-	a.clearImages()
-	a.setStatus("Generating images...")
+	prompt, err := expandVariables(prompt, a.variables)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		return
+	}
+
+	// Collect the sweep runs before queueing so bad values are reported early
+	opts := a.selectedOptions()
+	runs, err := a.buildSweep(opts)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		return
+	}
+
+	// In append mode each batch is labeled with its prompt
+	appendMode := a.appendToggle != nil && a.appendToggle.Active()
+
+	// Without a sweep the generation is queued as a single run
+	if runs == nil {
+		label := ""
+		if appendMode {
+			label = prompt
+		}
+		runs = []sweepRun{{label: label, opts: opts}}
+	}
+
+	// A dry run only shows what would be sent
+	if a.dryRun {
+		a.showDryRun(prompt, runs)
+		return
+	}
+
+	group := a.enqueueGeneration(prompt, runs, appendMode)
+	group.typedPrompt = a.promptText()
+}
 
+// selectedOptions builds generation options from the current UI controls
+func (a *App) selectedOptions() flux.GenerateOptions {
 	// Find aspect ratio dropdown and number of images slider
 	aspectCombo := a.findAspectRatioCombo()
 	numOutputsScale := a.findNumOutputsScale()
@@ -50,25 +88,27 @@ func (a *App) onGenerateClicked() {
 		numOutputs = int(numOutputsScale.Adjustment().Value())
 	}
 
-	// Generate images with the selected options
-	go func() {
-		images, err := a.client.GenerateImagesWithOptions(prompt, flux.GenerateOptions{
-			NumOutputs:   numOutputs,
-			AspectRatio:  aspectRatio,
-			OutputFormat: a.config.GetDefaultFormat(),
-			Quality:      a.config.GetDefaultQuality(),
-		})
-		
-		glib.IdleAdd(func() {
-			a.spinner.Stop()
-			if err != nil {
-				a.setStatus(fmt.Sprintf("Error: %v", err))
-				return
-			}
-			a.displayImages(images)
-			a.setStatus(fmt.Sprintf("Generated %d images", len(images)))
-		})
-	}()
+	guidance, steps := a.advancedOptions()
+
+	opts := flux.GenerateOptions{
+		NumOutputs:   numOutputs,
+		AspectRatio:  aspectRatio,
+		OutputFormat: a.config.GetDefaultFormat(),
+		Quality:      a.config.GetDefaultQuality(),
+		Seed:         a.keptSeed(),
+		Image:        a.baseImageValue(),
+		ImageSource:  a.baseImageSource(),
+		Guidance:     guidance,
+		Steps:        steps,
+		Model:        a.presetModel,
+	}
+	if a.presetFormat != "" {
+		opts.OutputFormat = a.presetFormat
+	}
+	if a.presetQuality > 0 {
+		opts.Quality = a.presetQuality
+	}
+	return opts
 }
 
 // Store references to our UI controls for easy access
@@ -88,6 +128,20 @@ func (a *App) findAspectRatioCombo() *gtk.DropDown {
 	return nil
 }
 
+// limitNumOutputs lowers the most images the slider offers to the backend's
+// limit, reducing the current value if it is above it
+func (a *App) limitNumOutputs(limit int) {
+	scale := a.findNumOutputsScale()
+	if scale == nil {
+		return
+	}
+	adjustment := scale.Adjustment()
+	adjustment.SetUpper(float64(limit))
+	if adjustment.Value() > float64(limit) {
+		adjustment.SetValue(float64(limit))
+	}
+}
+
 // findNumOutputsScale finds the number of outputs scale in the UI
 func (a *App) findNumOutputsScale() *gtk.Scale {
 	// Return cached reference if available
@@ -99,8 +153,11 @@ func (a *App) findNumOutputsScale() *gtk.Scale {
 	return nil
 }
 
-// displayImages shows the generated images in the UI
-func (a *App) displayImages(urls []string) {
+// displayImages shows the generated images in the UI as a new batch,
+// captioned with its label or prompt
+func (a *App) displayImages(batch *generationBatch) {
+	urls := batch.urls
+
 	// Get the available width for the images
 	availableWidth := a.currentWidth
 	if availableWidth == 0 {
@@ -122,7 +179,7 @@ func (a *App) displayImages(urls []string) {
 	}
 	
 	// Minimum image size
-	minImageSize := 320
+	minImageSize := minResultSize
 	
 	// Create image grid
 	imageGrid := gtk.NewGrid()
@@ -131,12 +188,36 @@ func (a *App) displayImages(urls []string) {
 	imageGrid.SetRowHomogeneous(false)
 	imageGrid.SetColumnHomogeneous(true)
 	
-	a.imageBox.Append(imageGrid)
+	resultGrid := &resultGrid{grid: imageGrid, perRow: imagesPerRow}
+	a.resultGrids = append(a.resultGrids, resultGrid)
+	
+	// Separate this batch from any previous ones
+	if a.imageBox.FirstChild() != nil {
+		separator := gtk.NewSeparator(a.separatorOrientation())
+		a.imageBox.Append(separator)
+		a.resultSeparators = append(a.resultSeparators, separator)
+	}
+	
+	// Create the batch container with its caption and tags
+	if batch.tags == nil {
+		batch.tags = &tagList{}
+	}
+	captionRow := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	caption := a.createBatchCaption(batch)
+	caption.SetHExpand(true)
+	captionRow.Append(caption)
+	captionRow.Append(a.createTagsButton(batch.tags))
+	
+	batchBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	batchBox.Append(captionRow)
+	batchBox.Append(imageGrid)
+	
+	a.imageBox.Append(batchBox)
+	shown := a.startShownBatch(batch)
 	
 	// Display each image
 	for i, url := range urls {
-		row := i / imagesPerRow
-		col := i % imagesPerRow
+		imageBatch := batch.forImage(i)
 		
 		// Create a frame for the image
 		imageFrame := gtk.NewFrame("")
@@ -148,6 +229,12 @@ func (a *App) displayImages(urls []string) {
 		// Add styling to the frame
 		imageFrame.AddCSSClass("frame")
 		
+		// Number the image so it can be referred to, e.g. "#3"
+		numberLabel := gtk.NewLabel(fmt.Sprintf("#%d", i+1))
+		numberLabel.SetVisible(a.numbersToggle.Active())
+		imageFrame.SetLabelWidget(numberLabel)
+		a.imageNumbers = append(a.imageNumbers, numberLabel)
+		
 		// Create a container for the image and buttons
 		imageBox := gtk.NewBox(gtk.OrientationVertical, 8)
 		imageBox.SetMarginStart(8)
@@ -167,21 +254,36 @@ func (a *App) displayImages(urls []string) {
 		imageFrame.SetChild(imageBox)
 		
 		// Add the frame to the grid
-		imageGrid.Attach(imageFrame, col, row, 1, 1)
+		resultGrid.add(imageFrame, a.gridColumns(resultGrid))
 		
 		// Load the image in the background
 		go func(url string, imageBox *gtk.Box, placeholder *gtk.Spinner) {
-			texture, err := a.loadImageTexture(url)
+			result, err := a.loadImageTexture(url)
+			if result != nil {
+				result.batch = imageBatch
+				
+				// Images the backend flagged stay hidden until clicked
+				if batch.isFlagged(i) && a.settings.BlurFlagged {
+					if result.cover, err = newCoverTexture(result); err != nil {
+						result, err = nil, fmt.Errorf("failed to blur flagged image: %w", err)
+					}
+				}
+			}
 			if err != nil {
 				glib.IdleAdd(func() {
 					// Remove the spinner
 					imageBox.Remove(placeholder)
 					
 					// Show error message
-					errorLabel := gtk.NewLabel(fmt.Sprintf("Error: %v", err))
+					errorLabel := gtk.NewLabel(fmt.Sprintf(tr("Error: %v"), err))
 					errorLabel.SetWrap(true)
 					errorLabel.SetJustify(gtk.JustifyCenter)
 					imageBox.Append(errorLabel)
+					
+					// Slow or oversized downloads only affect their own image
+					if errors.Is(err, flux.ErrDownloadTimeout) || errors.Is(err, flux.ErrImageTooLarge) {
+						a.setStatus(fmt.Sprintf(tr("Error loading image #%d: %v"), i+1, err))
+					}
 				})
 				return
 			}
@@ -192,7 +294,7 @@ func (a *App) displayImages(urls []string) {
 				
 				// Create picture widget
 				picture := gtk.NewPicture()
-				picture.SetPaintable(texture)
+				picture.SetPaintable(result.texture)
 				picture.SetCanShrink(true)
 				picture.SetHExpand(true)
 				picture.SetVExpand(true)
@@ -207,200 +309,375 @@ func (a *App) displayImages(urls []string) {
 				buttonBox.SetMarginTop(8)
 				
 				// Save button
-				saveBtn := gtk.NewButtonWithLabel("Save")
+				saveBtn := gtk.NewButtonWithLabel(tr("Save"))
 				saveBtn.ConnectClicked(func() {
-					a.saveImage(url)
+					a.saveImage(result)
 				})
 				
 				// Copy button
-				copyBtn := gtk.NewButtonWithLabel("Copy")
+				copyBtn := gtk.NewButtonWithLabel(tr("Copy"))
 				copyBtn.ConnectClicked(func() {
-					a.copyImageToClipboard(texture)
+					// Copy at full resolution even if the display is scaled down
+					a.withResultTexture(result, tr("copying"), a.copyImageToClipboard)
+				})
+				
+				// View button opens the image zoomable at full resolution
+				viewBtn := gtk.NewButtonWithLabel(tr("View"))
+				viewBtn.ConnectClicked(func() {
+					a.withResultTexture(result, tr("viewing"), func(texture *gdk.Texture) {
+						a.showImageViewer(defaultImageName(result.url), texture)
+					})
 				})
 				
 				// Upscale button
-				upscaleBtn := gtk.NewButtonWithLabel("Upscale")
+				upscaleBtn := gtk.NewButtonWithLabel(tr("Upscale"))
 				
 				// Enable upscale button if the upscaler is configured
 				upscaleBtn.SetSensitive(a.isUpscalerConfigured())
 				
 				if a.isUpscalerConfigured() {
 					upscaleBtn.ConnectClicked(func() {
-						// Log which image we're trying to upscale
-						fmt.Printf("Attempting to upscale image from URL: %s\n", url)
-						
-						// Create a temporary file to save the image for upscaling
-						tmpFile, err := os.CreateTemp("", "temp-image-*.png")
-						if err != nil {
-							a.setStatus(fmt.Sprintf("Error preparing image for upscaling: %v", err))
-							return
-						}
-						tmpPath := tmpFile.Name()
-						tmpFile.Close()
-						
-						// Download the image to the temp file
+						// Upscale the image as displayed, including any rotation or flip,
+						// without downloading it again unless its bytes are not kept
 						go func() {
-							err := a.downloadAndSaveImage(url, tmpPath)
-							if err != nil {
-								glib.IdleAdd(func() {
-									a.setStatus(fmt.Sprintf("Error preparing image for upscaling: %v", err))
-								})
-								os.Remove(tmpPath)
-								return
+							data, format := result.saveData()
+							var err error
+							if data == nil {
+								data, err = result.original()
+							}
+							var tmpPath string
+							if err == nil {
+								tmpPath, err = writeTempImage(data, format)
 							}
-							
-							// Now handle the upscale
 							glib.IdleAdd(func() {
+								if err != nil {
+									a.setStatus(fmt.Sprintf(tr("Error preparing image for upscaling: %v"), err))
+									return
+								}
 								a.handleUpscaleFile(tmpPath)
 							})
 						}()
 					})
 				} else {
-					upscaleBtn.SetTooltipText("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.")
+					upscaleBtn.SetTooltipText(tr("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file."))
 				}
 				
+				// Keep button reuses this result's seed for the next generations
+				keepBtn := gtk.NewButtonWithLabel(tr("Keep"))
+				keepBtn.SetTooltipText(tr("Generate from this result's seed and settings until cleared"))
+				keepBtn.SetSensitive(imageBatch.opts.Seed != nil)
+				keepBtn.ConnectClicked(func() {
+					a.keepResult(imageBatch)
+				})
+				
 				// Add buttons to container
 				buttonBox.Append(saveBtn)
 				buttonBox.Append(copyBtn)
+				buttonBox.Append(a.createCopyPromptButton(batch.prompt))
+				buttonBox.Append(viewBtn)
+				buttonBox.Append(a.createCompareButton(result))
 				buttonBox.Append(upscaleBtn)
+				buttonBox.Append(keepBtn)
 				
-				// Add widgets to the image box
-				imageBox.Append(picture)
+				// Add widgets to the image box. Animations and videos play
+				// in place of the picture and are saved as they are, so they
+				// cannot be transformed or annotated.
+				var pictureOverlay *gtk.Overlay
+				var transformBox *gtk.Box
+				if result.motion {
+					player, playBtn := a.createMotionPlayer(result, minImageSize)
+					pictureOverlay = gtk.NewOverlay()
+					pictureOverlay.SetChild(player)
+					buttonBox.Append(playBtn)
+					for _, btn := range []*gtk.Button{copyBtn, viewBtn, upscaleBtn} {
+						btn.SetSensitive(false)
+						btn.SetTooltipText(tr("Not available for animations and videos"))
+					}
+				} else {
+					var annotationArea *gtk.DrawingArea
+					pictureOverlay, annotationArea = a.createAnnotationOverlay(result, picture)
+					transformBox = a.createTransformButtons(result, picture)
+					transformBox.Append(a.createAnnotateButton(result, annotationArea))
+				}
+				if result.cover != nil {
+					cover, hideBtn := a.createSensitiveCover(result)
+					pictureOverlay.AddOverlay(cover)
+					buttonBox.Append(hideBtn)
+				}
+				imageBox.Append(pictureOverlay)
+				if imageBatch.opts.Seed != nil {
+					imageBox.Append(a.createSeedRow(*imageBatch.opts.Seed))
+				}
 				imageBox.Append(buttonBox)
+				if transformBox != nil {
+					imageBox.Append(transformBox)
+				}
+				
+				a.results = append(a.results, result)
+				if !result.motion {
+					a.watchResultPicture(result, picture)
+				}
+				shown.results[i] = result
+				a.trackResultFocus(result, imageBox, picture)
+				a.addRecent(result)
+				if a.settings.AutoSave {
+					a.autoSaveResult(result, i+1, imageBox)
+				}
+				a.recordDownload(result.size)
 			})
 		}(url, imageBox, placeholder)
 	}
 }
 
-func (a *App) loadImageTexture(url string) (*gdk.Texture, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// saveImage shows a save dialog for a result image, offering its
+// format as the default
+func (a *App) saveImage(img *resultImage) {
+	url := img.url
+	data, format := img.saveData()
+	annotations := img.annotationList()
+	copies := a.copyFormatsToSave()
+	batch := img.batch
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	texture, err := gdk.NewTextureFromBytes(glib.NewBytesWithGo(data))
-	if err != nil {
-		return nil, err
-	}
-
-	return texture, nil
-}
-
-func (a *App) saveImage(url string) {
 	dialog := gtk.NewFileChooserNative(
-		"Save Image",
+		tr("Save Image"),
 		&a.win.Window,
 		gtk.FileChooserActionSave,
-		"_Save",
-		"_Cancel",
+		tr("_Save"),
+		tr("_Cancel"),
 	)
 
-	defaultName := filepath.Base(url)
-	if defaultName == "" || defaultName == "." {
-		defaultName = "generated_image.png"
-	}
-	dialog.SetCurrentName(defaultName)
+	dialog.SetCurrentName(withFormatExt(defaultImageName(url), format))
 
-	filter := gtk.NewFileFilter()
-	filter.AddPattern("*.png")
-	filter.SetName("PNG images")
-	dialog.AddFilter(filter)
+	addImageFilters(dialog, format)
 
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		picturesDir := filepath.Join(homeDir, "Pictures")
-		if _, err := os.Stat(picturesDir); err == nil {
-			gfile := gio.NewFileForPath(picturesDir)
-			dialog.SetCurrentFolder(gfile)
-		}
-	}
+	a.setDefaultSaveFolder(dialog)
 
-	responseChan := make(chan int)
 	dialog.ConnectResponse(func(response int) {
-		responseChan <- response
+		a.handleSaveResponse(dialog, response, func(chosen string) {
+			a.saveImageAs(url, chosen, data, format, annotations, copies, batch)
+		})
 	})
 
 	dialog.Show()
+}
 
-	go func() {
-		response := <-responseChan
-		if response == int(gtk.ResponseAccept) {
-			file := dialog.File()
-			if file == nil {
-				glib.IdleAdd(func() {
-					a.setStatus("Error: No file selected")
-				})
-				return
-			}
+// saveDialog is the part of a file chooser a save response needs
+type saveDialog interface {
+	File() *gio.File
+	Destroy()
+}
 
-			path := file.Path()
+// handleSaveResponse closes a save dialog and passes the chosen path to
+// save if it was accepted. It runs on the main thread; only the download
+// and write run in the background, so nothing is left waiting if the
+// dialog is dismissed.
+func (a *App) handleSaveResponse(dialog saveDialog, response int, save func(chosen string)) {
+	defer dialog.Destroy()
+	if response != int(gtk.ResponseAccept) {
+		return
+	}
 
-			if !strings.HasSuffix(strings.ToLower(path), ".png") {
-				path += ".png"
-			}
+	file := dialog.File()
+	if file == nil {
+		a.setStatus(tr("Error: No file selected"))
+		return
+	}
+	save(file.Path())
+}
 
-			go func() {
-				err := a.downloadAndSaveImage(url, path)
-				glib.IdleAdd(func() {
-					if err != nil {
-						a.setStatus(fmt.Sprintf("Error saving image: %v", err))
-					} else {
-						a.setStatus(fmt.Sprintf("Image saved to: %s", path))
-					}
-				})
-			}()
+// saveImageAs writes a result image to the path chosen in the save dialog,
+// downloading it first if its bytes are not cached and drawing in any
+// annotations. The generation settings of batch are saved beside it for
+// the gallery.
+func (a *App) saveImageAs(url, chosen string, data []byte, format imageFormat, annotations []annotation, copies []imageFormat, batch *generationBatch) {
+	go func() {
+		var err error
+		if data == nil {
+			data, format, err = a.downloadImage(url)
+		}
+		if err == nil && len(annotations) > 0 {
+			data, format, err = burnAnnotations(data, format, annotations)
 		}
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error saving image: %v"), err))
+				return
+			}
 
-		dialog.Destroy()
+			path := withDetectedExt(chosen, format)
+			meta := newImageMetadata(batch, format)
+			a.checkExistingFile(path, chosen, besideExts(copies, meta != nil), func(path string) {
+				go func() {
+					err := writeImageFiles(path, data, copies)
+					if err == nil && meta != nil {
+						if err := writeSidecar(path, meta); err != nil {
+							fmt.Fprintf(os.Stderr, "Error saving metadata for %s: %v\n", path, err)
+						}
+					}
+					glib.IdleAdd(func() {
+						if err != nil {
+							a.setStatus(fmt.Sprintf(tr("Error saving image: %v"), err))
+						} else {
+							a.setSavedStatus(fmt.Sprintf(tr("Image saved to: %s"), path), path)
+						}
+					})
+				}()
+			})
+		})
 	}()
 }
 
-func (a *App) downloadAndSaveImage(url, destPath string) error {
-	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// writeTempImage writes image data to a temporary file named with the
+// format's extension and returns its path
+func writeTempImage(data []byte, format imageFormat) (string, error) {
+	tmpFile, err := os.CreateTemp("", "temp-image-*"+format.Ext)
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
+
+// defaultImageName suggests a file name for the image at rawURL, using the
+// last path element of regular URLs and a generic name for inline images
+func defaultImageName(rawURL string) string {
+	name := ""
+	if u, err := neturl.Parse(rawURL); err == nil && u.Opaque == "" {
+		name = path.Base(u.Path)
+	}
+	if name == "" || name == "." || name == "/" {
+		name = "generated_image"
 	}
+	return name
+}
 
-	resp, err := http.Get(url)
+// downloadImage downloads the image at url and detects its format.
+// Incomplete downloads are reported as errors.
+func (a *App) downloadImage(url string) ([]byte, imageFormat, error) {
+	data, contentType, err := a.client.Download(context.Background(), url)
 	if err != nil {
-		return fmt.Errorf("failed to download image: %w", err)
+		return nil, imageFormat{}, err
 	}
-	defer resp.Body.Close()
+	return data, detectImageFormat(data, contentType, url), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download image: status code %d", resp.StatusCode)
+// writeFileAtomic writes the contents of r to a temporary file beside
+// destPath and renames it into place once complete. The temporary file is
+// removed on every failure, so a failed save leaves nothing behind.
+func writeFileAtomic(destPath string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	tmpFile, err := os.CreateTemp(filepath.Dir(destPath), "*.png")
+	// A hidden name without an image extension keeps an interrupted save
+	// out of the gallery
+	tmpFile, err := os.CreateTemp(filepath.Dir(destPath), ".fluxxxer-*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temporary file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 
+	renamed := false
 	defer func() {
 		tmpFile.Close()
-		os.Remove(tmpPath)
+		if !renamed {
+			os.Remove(tmpPath)
+		}
 	}()
 
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+	if _, err := io.Copy(tmpFile, r); err != nil {
 		return fmt.Errorf("failed to write image data: %w", err)
 	}
 
-	tmpFile.Close()
+	// Make sure the data is on disk before it replaces an existing file
+	if err := tmpFile.Sync(); err != nil {
+		return fmt.Errorf("failed to write image data: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write image data: %w", err)
+	}
 
 	if err := os.Rename(tmpPath, destPath); err != nil {
 		return fmt.Errorf("failed to save image: %w", err)
 	}
+	renamed = true
 
 	return nil
 }
 
+// createSeedRow shows the seed an image was generated with and lets the
+// user copy it or use it for the next generation
+func (a *App) createSeedRow(seed int) *gtk.Box {
+	seedBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	seedBox.SetHAlign(gtk.AlignCenter)
+
+	seedText := strconv.Itoa(seed)
+	seedLabel := gtk.NewLabel(tr("Seed: ") + seedText)
+	seedLabel.SetSelectable(true)
+	seedLabel.AddCSSClass("dim-label")
+
+	copySeedBtn := gtk.NewButtonWithLabel(tr("Copy Seed"))
+	copySeedBtn.ConnectClicked(func() {
+		a.copyText(seedText, fmt.Sprintf(tr("Seed %s copied to clipboard"), seedText))
+	})
+
+	useSeedBtn := gtk.NewButtonWithLabel(tr("Use Seed"))
+	useSeedBtn.SetTooltipText(tr("Generate from this seed next, as a seed sweep of one"))
+	useSeedBtn.ConnectClicked(func() {
+		a.useSeed(seed)
+	})
+
+	seedBox.Append(seedLabel)
+	seedBox.Append(copySeedBtn)
+	seedBox.Append(useSeedBtn)
+	return seedBox
+}
+
+// copyImageToClipboard puts texture on the clipboard
 func (a *App) copyImageToClipboard(texture *gdk.Texture) {
-	clipboard := gdk.DisplayGetDefault().Clipboard()
-	clipboard.SetTexture(texture)
-	a.setStatus("Image copied to clipboard")
+	clip, err := clipboard()
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error copying to the clipboard: %v"), err))
+		return
+	}
+	clip.SetTexture(texture)
+	a.setStatus(tr("Image copied to clipboard"))
+}
+
+// createCopyPromptButton creates a button copying the prompt that
+// generated an image to the clipboard as text
+func (a *App) createCopyPromptButton(prompt string) *gtk.Button {
+	copyPromptBtn := gtk.NewButtonWithLabel(tr("Copy Prompt"))
+	copyPromptBtn.SetTooltipText(prompt)
+	copyPromptBtn.ConnectClicked(func() {
+		a.copyText(prompt, tr("Prompt copied to clipboard"))
+	})
+	return copyPromptBtn
+}
+
+// setDefaultSaveFolder opens save dialogs in the output directory so saved
+// images appear in the gallery, falling back to the Pictures directory. The
+// output directory is only created by saving into it, so it is used once it
+// exists.
+func (a *App) setDefaultSaveFolder(dialog *gtk.FileChooserNative) {
+	if dir := a.config.GetOutputDir(); dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dialog.SetCurrentFolder(gio.NewFileForPath(dir))
+			return
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		picturesDir := filepath.Join(homeDir, "Pictures")
+		if _, err := os.Stat(picturesDir); err == nil {
+			gfile := gio.NewFileForPath(picturesDir)
+			dialog.SetCurrentFolder(gfile)
+		}
+	}
 }
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "image.png")
+	if err := os.WriteFile(destPath, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(destPath, strings.NewReader("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("got %q, want %q", data, "new")
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomicRenameFailure(t *testing.T) {
+	// A directory in the way of the destination makes the rename fail
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "image.png")
+	original := filepath.Join(destPath, "original.png")
+	if err := os.MkdirAll(destPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(destPath, strings.NewReader("new")); err == nil {
+		t.Fatal("got no error when the rename fails")
+	}
+
+	data, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatalf("original file lost: %v", err)
+	}
+	if string(data) != "original" {
+		t.Errorf("original file changed to %q", data)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// assertNoTempFiles fails the test if a temporary file was left in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+}
+
+// canceledDialog is a save dialog the user dismissed
+type canceledDialog struct {
+	destroyed int
+}
+
+func (d *canceledDialog) File() *gio.File { return nil }
+func (d *canceledDialog) Destroy()        { d.destroyed++ }
+
+func TestSaveDialogCancelLeavesNoGoroutines(t *testing.T) {
+	const cycles = 1000
+	a := &App{}
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < cycles; i++ {
+		dialog := &canceledDialog{}
+		a.handleSaveResponse(dialog, int(gtk.ResponseCancel), func(string) {
+			t.Fatal("canceled dialog saved the image")
+		})
+		if dialog.destroyed != 1 {
+			t.Fatalf("dialog destroyed %d times, want 1", dialog.destroyed)
+		}
+	}
+
+	// Let any goroutine that is finishing exit before counting
+	after := runtime.NumGoroutine()
+	for deadline := time.Now().Add(time.Second); after > before && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before {
+		t.Errorf("%d goroutines left after %d open/cancel cycles", after-before, cycles)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// showHeadersDialog edits the extra headers sent with generation requests,
+// saved for the current profile
+func (a *App) showHeadersDialog() {
+	profile := a.config.GetProfile()
+
+	// Only the saved headers are edited, so those set by FLUX_API_HEADERS
+	// are never written to disk
+	saved, err := config.LoadHeaders(a.config.GetConfigDir(), profile)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error loading headers: %v"), err))
+		return
+	}
+
+	hint := gtk.NewLabel(fmt.Sprintf(tr("One per line, like X-Org-ID: my-org. Saved for the profile %q; FLUX_API_HEADERS overrides them when set."), profile))
+	hint.SetWrap(true)
+	hint.SetMaxWidthChars(60)
+	hint.SetXAlign(0)
+
+	textView := gtk.NewTextView()
+	textView.SetMonospace(true)
+	textView.Buffer().SetText(formatHeaders(saved))
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetChild(textView)
+	scrollWin.SetSizeRequest(480, 200)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(12)
+	contentBox.SetMarginBottom(12)
+	contentBox.SetMarginStart(12)
+	contentBox.SetMarginEnd(12)
+	contentBox.Append(hint)
+	contentBox.Append(scrollWin)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("API Headers"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Save"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		if responseId == int(gtk.ResponseAccept) {
+			buffer := textView.Buffer()
+			start, end := buffer.Bounds()
+			a.saveHeaders(config.ParseHeaders(buffer.Text(start, end, false)))
+		}
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
+
+// saveHeaders saves headers for the current profile and applies them to the
+// following requests, below any set by FLUX_API_HEADERS
+func (a *App) saveHeaders(headers map[string]string) {
+	if err := config.SaveHeaders(a.config.GetConfigDir(), a.config.GetProfile(), headers); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error saving headers: %v"), err))
+		return
+	}
+	a.config.SetSavedHeaders(headers)
+	a.setStatus(fmt.Sprintf(tr("Saved %d API headers for the profile %q"), len(headers), a.config.GetProfile()))
+}
+
+// formatHeaders writes headers one per line in the form config.ParseHeaders
+// reads, sorted by name
+func formatHeaders(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, headers[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Health check timing. While the backend is down the retry delay doubles
+// up to healthRetryMax.
+const (
+	healthCheckInterval = 30 * time.Second
+	healthRetryMin      = 5 * time.Second
+	healthRetryMax      = 60 * time.Second
+)
+
+// Connection indicator colors
+const (
+	healthUpColor   = "#2ec27e"
+	healthDownColor = "#e01b24"
+)
+
+// createHealthIndicator creates the dot showing whether the backend is reachable
+func (a *App) createHealthIndicator() *gtk.Label {
+	a.healthIndicator = gtk.NewLabel("")
+	a.healthIndicator.SetMarginStart(4)
+	a.healthIndicator.SetTooltipText(tr("Checking backend connection..."))
+	return a.healthIndicator
+}
+
+// startHealthChecks begins checking the backend in the background.
+// Nothing is checked in offline mode.
+func (a *App) startHealthChecks() {
+	if a.config.IsOffline() {
+		a.healthIndicator.SetVisible(false)
+		return
+	}
+	a.checkHealth()
+}
+
+// checkHealth checks the backend once and schedules the next check
+func (a *App) checkHealth() {
+	go func() {
+		start := time.Now()
+		err := a.client.CheckHealth(context.Background())
+		latency := time.Since(start)
+		glib.IdleAdd(func() {
+			a.healthLatency = latency
+			a.healthChecked = time.Now()
+			a.updateHealth(err)
+		})
+	}()
+}
+
+// updateHealth updates the indicator and generation controls from a check
+func (a *App) updateHealth(err error) {
+	if err == nil {
+		a.setHealthIndicator(healthUpColor, "Backend reachable")
+		if a.backendDown {
+			a.backendDown = false
+			a.setGenerationEnabled(true, "")
+			a.setStatus(tr("Backend is reachable again"))
+			a.showBanner(gtk.MessageInfo, tr("Backend is reachable again"))
+		}
+		a.healthDelay = healthCheckInterval
+		a.updateGenerateTooltip()
+	} else {
+		// Back off while the backend stays down
+		if a.backendDown {
+			a.healthDelay = min(a.healthDelay*2, healthRetryMax)
+		} else {
+			a.healthDelay = healthRetryMin
+			a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+		}
+		a.backendDown = true
+
+		reason := fmt.Sprintf("%v. Retrying in %s", err, a.healthDelay)
+		a.setHealthIndicator(healthDownColor, reason)
+		a.setGenerationEnabled(false, reason)
+	}
+
+	glib.TimeoutSecondsAdd(uint(a.healthDelay/time.Second), func() bool {
+		a.checkHealth()
+		return false
+	})
+}
+
+// setHealthIndicator shows a colored dot with the given tooltip
+func (a *App) setHealthIndicator(color, tooltip string) {
+	a.healthIndicator.SetMarkup(fmt.Sprintf("<span foreground=\"%s\">●</span>", color))
+	a.healthIndicator.SetTooltipText(tooltip)
+}
+
+// healthSummary describes the last health check and how long generations
+// take for the Generate button, or returns an empty string before the
+// first check
+func (a *App) healthSummary() string {
+	if a.healthChecked.IsZero() {
+		return ""
+	}
+	summary := fmt.Sprintf(tr("Backend responded in %s (checked at %s)"),
+		a.healthLatency.Round(time.Millisecond), a.healthChecked.Format("15:04:05"))
+	if average := a.stats.AverageGenerationTime(); average > 0 {
+		summary += "\n" + fmt.Sprintf(tr("Generations take %s on average"), average.Round(100*time.Millisecond))
+	}
+	return summary
+}
+
+// updateGenerateTooltip shows the cost estimate and backend health on the
+// Generate button. While generation is disabled the button keeps the reason
+// instead.
+func (a *App) updateGenerateTooltip() {
+	if !a.generateBtn.Sensitive() {
+		return
+	}
+
+	var lines []string
+	for _, line := range []string{a.costEstimate(), a.healthSummary()} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	a.generateBtn.SetTooltipText(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// windowShortcut is a keyboard shortcut of the window. The shortcuts of
+// actions are registered from windowShortcuts, and all of them are listed
+// in the shortcuts window and beside their commands in the palette.
+type windowShortcut struct {
+	group  string // Title of its group in the shortcuts window
+	title  string
+	action string // "win." action it activates, empty when a widget handles the keys
+	accel  string // In the syntax of gtk.NewShortcutTriggerParseString
+}
+
+// windowShortcuts lists every keyboard shortcut of the window, in the order
+// of the shortcuts window
+var windowShortcuts = []windowShortcut{
+	{"Generation", "Generate images from the prompt", "", "Return"},
+	{"Generation", "Generate from the expanded prompt editor", "", "<Control>Return"},
+	{"Generation", "Run the last generation again", "duplicate-last", "<Control>d"},
+	{"Generation", "Reset the prompt and controls to their defaults", "reset-controls", "<Control><Shift>r"},
+	{"Results", "Save the focused result, or the first", "save-result", "<Control>s"},
+	{"Navigation", "Focus the prompt", "focus-prompt", "<Control>l"},
+	{"General", "Show keyboard shortcuts", "show-help-overlay", "<Control>question|F1"},
+	{"General", "Open the command palette", "show-command-palette", "<Control><Shift>p"},
+}
+
+// shortcutAccel returns the accelerator of a detailed action name such as
+// "win.save-result", or an empty string when it has none
+func shortcutAccel(action string) string {
+	for _, shortcut := range windowShortcuts {
+		if shortcut.action != "" && "win."+shortcut.action == action {
+			return shortcut.accel
+		}
+	}
+	return ""
+}
+
+// shortcutGroup is a titled group of shortcuts in the shortcuts window
+type shortcutGroup struct {
+	title     string
+	shortcuts []windowShortcut
+}
+
+// shortcutGroups returns windowShortcuts grouped by title, the groups in
+// the order they first appear
+func shortcutGroups() []shortcutGroup {
+	var groups []shortcutGroup
+	for _, shortcut := range windowShortcuts {
+		i := slices.IndexFunc(groups, func(g shortcutGroup) bool { return g.title == shortcut.group })
+		if i < 0 {
+			groups = append(groups, shortcutGroup{title: shortcut.group})
+			i = len(groups) - 1
+		}
+		groups[i].shortcuts = append(groups[i].shortcuts, shortcut)
+	}
+	return groups
+}
+
+// setupShortcutsWindow sets the window's help overlay listing the keyboard
+// shortcuts, shown by the win.show-help-overlay action
+func (a *App) setupShortcutsWindow() {
+	builder := gtk.NewBuilderFromString(shortcutsWindowXML())
+	window, ok := builder.GetObject("shortcuts").Cast().(*gtk.ShortcutsWindow)
+	if !ok {
+		return
+	}
+	a.win.SetHelpOverlay(window)
+}
+
+// shortcutsWindowXML returns the builder definition of the shortcuts window.
+// Shortcuts windows can only be filled in through a builder.
+func shortcutsWindowXML() string {
+	var b strings.Builder
+	b.WriteString(`<interface><object class="GtkShortcutsWindow" id="shortcuts"><property name="modal">1</property>`)
+	b.WriteString(`<child><object class="GtkShortcutsSection"><property name="section-name">shortcuts</property>`)
+	for _, group := range shortcutGroups() {
+		b.WriteString(`<child><object class="GtkShortcutsGroup"><property name="title">` + html.EscapeString(tr(group.title)) + `</property>`)
+		for _, s := range group.shortcuts {
+			b.WriteString(`<child><object class="GtkShortcutsShortcut">`)
+			b.WriteString(`<property name="title">` + html.EscapeString(tr(s.title)) + `</property>`)
+			// Shortcuts windows separate alternatives with spaces
+			accel := strings.ReplaceAll(s.accel, "|", " ")
+			b.WriteString(`<property name="accelerator">` + html.EscapeString(accel) + `</property>`)
+			b.WriteString(`</object></child>`)
+		}
+		b.WriteString(`</object></child>`)
+	}
+	b.WriteString(`</object></child></object></interface>`)
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShortcutGroups(t *testing.T) {
+	groups := shortcutGroups()
+
+	var titles []string
+	count := 0
+	for _, group := range groups {
+		if slices.Contains(titles, group.title) {
+			t.Errorf("group %q appears twice", group.title)
+		}
+		titles = append(titles, group.title)
+		for _, shortcut := range group.shortcuts {
+			if shortcut.group != group.title {
+				t.Errorf("shortcut %q of group %q listed under %q", shortcut.title, shortcut.group, group.title)
+			}
+		}
+		count += len(group.shortcuts)
+	}
+	if count != len(windowShortcuts) {
+		t.Errorf("got %d shortcuts in the groups, want all %d", count, len(windowShortcuts))
+	}
+	if want := []string{"Generation", "Results", "Navigation", "General"}; !slices.Equal(titles, want) {
+		t.Errorf("got groups %q, want %q", titles, want)
+	}
+}
+
+func TestShortcutAccel(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{"win.save-result", "<Control>s"},
+		{"win.show-help-overlay", "<Control>question|F1"},
+		{"win.generate", ""},
+		{"save-result", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := shortcutAccel(tt.action); got != tt.want {
+			t.Errorf("shortcutAccel(%q) = %q, want %q", tt.action, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package app
+
+import "fluxxxer/internal/i18n"
+
+// tr translates a user-facing string into the user's language. Format
+// strings are translated before formatting, e.g.
+// fmt.Sprintf(tr("Generated %d images"), n).
+func tr(message string) string {
+	return i18n.Tr(message)
+}
+
+// trAll translates each of messages, e.g. the choices of a dropdown
+func trAll(messages []string) []string {
+	translated := make([]string, len(messages))
+	for i, message := range messages {
+		translated[i] = tr(message)
+	}
+	return translated
+}
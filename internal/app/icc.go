@@ -0,0 +1,204 @@
+package app
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+var (
+	// jpegICCMarker starts JPEG APP2 segments carrying an ICC profile
+	jpegICCMarker = []byte("ICC_PROFILE\x00")
+	// pngSignature starts every PNG file
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// jpegICCChunkSize is the most profile data one APP2 segment can carry: the
+// segment length limit minus the length field, marker and sequence numbers
+const jpegICCChunkSize = 65535 - 2 - 12 - 2
+
+// extractICCProfile returns the ICC color profile embedded in a PNG, JPEG
+// or WebP image, or nil if it has none
+func extractICCProfile(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		return pngICCProfile(data)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return jpegICCProfile(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return webpICCProfile(data)
+	}
+	return nil
+}
+
+// pngICCProfile reads the profile from the iCCP chunk: a name, a null
+// byte, the compression method and the zlib compressed profile
+func pngICCProfile(data []byte) []byte {
+	for pos := len(pngSignature); pos+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		end := pos + 8 + length + 4
+		if end > len(data) || kind == "IDAT" {
+			return nil
+		}
+		if kind == "iCCP" {
+			chunk := data[pos+8 : pos+8+length]
+			nul := bytes.IndexByte(chunk, 0)
+			if nul < 0 || nul+2 > len(chunk) {
+				return nil
+			}
+			r, err := zlib.NewReader(bytes.NewReader(chunk[nul+2:]))
+			if err != nil {
+				return nil
+			}
+			profile, err := io.ReadAll(r)
+			if err != nil {
+				return nil
+			}
+			return profile
+		}
+		pos = end
+	}
+	return nil
+}
+
+// jpegICCProfile joins the profile chunks of the APP2 segments, which are
+// numbered in case they are out of order
+func jpegICCProfile(data []byte) []byte {
+	var chunks [][]byte
+	ok := forEachJPEGSegment(data, func(marker byte, segment []byte) {
+		if marker != 0xE2 || !bytes.HasPrefix(segment, jpegICCMarker) || len(segment) <= len(jpegICCMarker)+2 {
+			return
+		}
+		seq := int(segment[len(jpegICCMarker)])
+		count := int(segment[len(jpegICCMarker)+1])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		if seq >= 1 && seq <= len(chunks) {
+			chunks[seq-1] = segment[len(jpegICCMarker)+2:]
+		}
+	})
+	if !ok {
+		return nil
+	}
+
+	var profile []byte
+	for _, chunk := range chunks {
+		if chunk == nil {
+			return nil
+		}
+		profile = append(profile, chunk...)
+	}
+	return profile
+}
+
+// forEachJPEGSegment calls fn with the marker and contents of each metadata
+// segment before the image data. It reports false if the data is malformed.
+func forEachJPEGSegment(data []byte, fn func(marker byte, segment []byte)) bool {
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return false
+		}
+		marker := data[pos+1]
+		// Start of scan: the image data follows, no more metadata
+		if marker == 0xDA {
+			return true
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return false
+		}
+
+		fn(marker, data[pos+4:end])
+		pos = end
+	}
+	return true
+}
+
+// webpICCProfile reads the profile from the ICCP chunk of an extended WebP
+func webpICCProfile(data []byte) []byte {
+	for pos := 12; pos+8 <= len(data); {
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + length
+		if end > len(data) {
+			return nil
+		}
+		if string(data[pos:pos+4]) == "ICCP" {
+			return data[pos+8 : end]
+		}
+		// Chunks are padded to an even size
+		pos = end + length%2
+	}
+	return nil
+}
+
+// embedICCProfile adds profile to encoded PNG or JPEG data from the Go
+// encoders, which write none of their own. Other data is returned as is.
+func embedICCProfile(data []byte, format imageFormat, profile []byte) ([]byte, error) {
+	if len(profile) == 0 {
+		return data, nil
+	}
+	switch format.MIME {
+	case "image/png":
+		return embedPNGICCProfile(data, profile)
+	case "image/jpeg":
+		return embedJPEGICCProfile(data, profile), nil
+	}
+	return data, nil
+}
+
+// embedPNGICCProfile inserts an iCCP chunk after the IHDR chunk, where it
+// must come before the image data
+func embedPNGICCProfile(data, profile []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(profile); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	// Profile name, null terminator and compression method 0 (zlib)
+	body := append([]byte("ICC Profile\x00\x00"), compressed.Bytes()...)
+	chunk := make([]byte, 0, len(body)+12)
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(body)))
+	chunk = append(chunk, "iCCP"...)
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// The signature is followed by IHDR, always 13 bytes of data
+	ihdrEnd := len(pngSignature) + 8 + 13 + 4
+	if len(data) < ihdrEnd {
+		return data, nil
+	}
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...), nil
+}
+
+// embedJPEGICCProfile inserts the profile as numbered APP2 segments right
+// after the start of image marker
+func embedJPEGICCProfile(data, profile []byte) []byte {
+	count := (len(profile) + jpegICCChunkSize - 1) / jpegICCChunkSize
+	if count > 255 || len(data) < 2 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data)+len(profile)+count*18)
+	out = append(out, data[:2]...)
+	for i := 0; i < count; i++ {
+		chunk := profile[i*jpegICCChunkSize : min((i+1)*jpegICCChunkSize, len(profile))]
+		out = append(out, 0xFF, 0xE2)
+		out = binary.BigEndian.AppendUint16(out, uint16(2+len(jpegICCMarker)+2+len(chunk)))
+		out = append(out, jpegICCMarker...)
+		out = append(out, byte(i+1), byte(count))
+		out = append(out, chunk...)
+	}
+	return append(out, data[2:]...)
+}
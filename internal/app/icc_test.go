@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testProfile is a stand-in ICC profile, long enough to span two JPEG
+// APP2 segments
+var testProfile = bytes.Repeat([]byte("icc profile data "), jpegICCChunkSize/8)
+
+// encodedTestImages returns a small image encoded as PNG and as JPEG
+func encodedTestImages(t *testing.T) (pngData, jpegData []byte) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var pngBuf, jpegBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return pngBuf.Bytes(), jpegBuf.Bytes()
+}
+
+// webpWithChunk returns a WebP container holding a single chunk
+func webpWithChunk(kind string, data []byte, declared uint32) []byte {
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	out = append(out, kind...)
+	out = binary.LittleEndian.AppendUint32(out, declared)
+	return append(out, data...)
+}
+
+func TestICCProfileRoundTrip(t *testing.T) {
+	pngData, jpegData := encodedTestImages(t)
+
+	for _, tt := range []struct {
+		format imageFormat
+		data   []byte
+	}{
+		{imageFormats[0], pngData},
+		{imageFormats[1], jpegData},
+	} {
+		t.Run(tt.format.Name, func(t *testing.T) {
+			embedded, err := embedICCProfile(tt.data, tt.format, testProfile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := extractICCProfile(embedded); !bytes.Equal(got, testProfile) {
+				t.Errorf("got a profile of %d bytes, want the %d embedded", len(got), len(testProfile))
+			}
+			if _, _, err := image.Decode(bytes.NewReader(embedded)); err != nil {
+				t.Errorf("image with the profile does not decode: %v", err)
+			}
+		})
+	}
+
+	webp := webpWithChunk("ICCP", []byte("profile"), 7)
+	if got := extractICCProfile(webp); string(got) != "profile" {
+		t.Errorf("got WebP profile %q, want %q", got, "profile")
+	}
+}
+
+func TestICCProfileTruncated(t *testing.T) {
+	pngData, jpegData := encodedTestImages(t)
+	withPNG, err := embedICCProfile(pngData, imageFormats[0], testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJPEG, err := embedICCProfile(jpegData, imageFormats[1], testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withWebP := webpWithChunk("ICCP", testProfile, uint32(len(testProfile)))
+
+	// A cut-off image must never give a partial profile, nor panic
+	for name, data := range map[string][]byte{"PNG": withPNG, "JPEG": withJPEG, "WebP": withWebP} {
+		full := extractICCProfile(data)
+		for n := 0; n < len(data); n++ {
+			if got := extractICCProfile(data[:n]); got != nil && !bytes.Equal(got, full) {
+				t.Errorf("%s cut off after %d bytes: got a partial profile of %d bytes", name, n, len(got))
+				break
+			}
+		}
+	}
+}
+
+func TestICCProfileMalformed(t *testing.T) {
+	pngData, jpegData := encodedTestImages(t)
+
+	// pngWithICCP returns pngData with an iCCP chunk holding body, whose
+	// length field is declared
+	pngWithICCP := func(body []byte, declared uint32) []byte {
+		ihdrEnd := len(pngSignature) + 8 + 13 + 4
+		out := append([]byte{}, pngData[:ihdrEnd]...)
+		out = binary.BigEndian.AppendUint32(out, declared)
+		out = append(out, "iCCP"...)
+		out = append(out, body...)
+		out = append(out, 0, 0, 0, 0) // CRC, which is not checked
+		return append(out, pngData[ihdrEnd:]...)
+	}
+
+	// jpegWithSegments returns jpegData with APP2 segments right after the
+	// start of image marker
+	jpegWithSegments := func(segments ...[]byte) []byte {
+		out := append([]byte{}, jpegData[:2]...)
+		for _, segment := range segments {
+			out = append(out, 0xFF, 0xE2)
+			out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+			out = append(out, segment...)
+		}
+		return append(out, jpegData[2:]...)
+	}
+	iccSegment := func(seq, count byte, data string) []byte {
+		return append(append(append([]byte{}, jpegICCMarker...), seq, count), data...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"PNG without profile", pngData, nil},
+		{"PNG profile without name terminator", pngWithICCP([]byte("ICC Profile"), 11), nil},
+		{"PNG profile without compression method", pngWithICCP([]byte("ICC Profile\x00"), 12), nil},
+		{"PNG profile not zlib", pngWithICCP([]byte("ICC Profile\x00\x00not zlib"), 21), nil},
+		{"PNG chunk longer than the file", pngWithICCP([]byte("ICC Profile\x00\x00"), 1 << 31), nil},
+		{"JPEG without profile", jpegData, nil},
+		{"JPEG chunks out of order", jpegWithSegments(iccSegment(2, 2, "world"), iccSegment(1, 2, "hello ")), []byte("hello world")},
+		{"JPEG chunk missing", jpegWithSegments(iccSegment(1, 2, "hello ")), nil},
+		{"JPEG chunk numbered past the count", jpegWithSegments(iccSegment(1, 1, "hello "), iccSegment(3, 1, "world")), []byte("hello ")},
+		{"JPEG chunk count of zero", jpegWithSegments(iccSegment(1, 0, "hello")), nil},
+		{"JPEG segment without sequence numbers", jpegWithSegments(jpegICCMarker), nil},
+		{"JPEG segment length too short", append([]byte{0xFF, 0xD8, 0xFF, 0xE2, 0x00, 0x01}, jpegData[2:]...), nil},
+		{"JPEG segment longer than the file", []byte{0xFF, 0xD8, 0xFF, 0xE2, 0xFF, 0xFF, 'I', 'C', 'C'}, nil},
+		{"JPEG garbage between segments", append([]byte{0xFF, 0xD8, 0x00, 0x00, 0x00, 0x00}, jpegData[2:]...), nil},
+		{"WebP chunk longer than the file", webpWithChunk("ICCP", []byte("profile"), 1 << 31), nil},
+		{"WebP without profile", webpWithChunk("VP8 ", []byte("data"), 4), nil},
+		{"WebP header only", []byte("RIFF\x00\x00\x00\x00WEBP"), nil},
+		{"empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractICCProfile(tt.data); !bytes.Equal(got, tt.want) {
+				t.Errorf("got profile %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedICCProfileMalformed(t *testing.T) {
+	pngData, _ := encodedTestImages(t)
+	tests := []struct {
+		name    string
+		data    []byte
+		format  imageFormat
+		profile []byte
+	}{
+		{"PNG cut off in the header", pngData[:20], imageFormats[0], testProfile},
+		{"JPEG cut off in the marker", []byte{0xFF}, imageFormats[1], testProfile},
+		{"JPEG profile too large for the segments", []byte{0xFF, 0xD8}, imageFormats[1], make([]byte, 256*jpegICCChunkSize)},
+		{"WebP is left as is", []byte("RIFF\x00\x00\x00\x00WEBP"), imageFormats[2], testProfile},
+		{"empty profile", pngData, imageFormats[0], nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := embedICCProfile(tt.data, tt.format, tt.profile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("got %d bytes, want the %d given back unchanged", len(got), len(tt.data))
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"sync/atomic"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+)
+
+// imageLoader holds which decoders textures are created with, one of the
+// config ImageLoader constants. Textures are created off the main thread,
+// so it is kept apart from the settings.
+var imageLoader atomic.Value
+
+// addImageLoaderAction adds the radio choice of the decoders images are
+// loaded with, saved with the settings
+func (a *App) addImageLoaderAction() {
+	imageLoader.Store(a.settings.ImageLoader)
+
+	action := gio.NewSimpleActionStateful(
+		"image-loader",
+		glib.NewVariantType("s"),
+		glib.NewVariantString(a.settings.ImageLoader),
+	)
+	action.ConnectActivate(func(parameter *glib.Variant) {
+		action.SetState(parameter)
+		a.settings.ImageLoader = parameter.String()
+		imageLoader.Store(a.settings.ImageLoader)
+		a.saveSettings()
+		a.setStatus(tr("Images load with the new choice from now on"))
+	})
+	a.win.AddAction(action)
+}
+
+// createImageLoaderMenu creates the radio items choosing the image loader
+func createImageLoaderMenu() *gio.Menu {
+	menu := gio.NewMenu()
+	menu.Append(tr("GDK, Falling Back to Go"), "win.image-loader::"+config.ImageLoaderAuto)
+	menu.Append(tr("GDK Only"), "win.image-loader::"+config.ImageLoaderGDK)
+	menu.Append(tr("Go Only"), "win.image-loader::"+config.ImageLoaderGo)
+	return menu
+}
+
+// newTexture creates a texture from encoded image data with the chosen
+// decoders. GDK's loaders are faster but depend on what the system has
+// installed; Go's decoders read PNG, JPEG and WebP anywhere. By default
+// images GDK cannot load are decoded by Go instead.
+func newTexture(data []byte) (*gdk.Texture, error) {
+	loader, _ := imageLoader.Load().(string)
+	switch loader {
+	case config.ImageLoaderGDK:
+		return gdk.NewTextureFromBytes(glib.NewBytesWithGo(data))
+	case config.ImageLoaderGo:
+		return newGoTexture(data)
+	}
+
+	texture, err := gdk.NewTextureFromBytes(glib.NewBytesWithGo(data))
+	if err == nil {
+		return texture, nil
+	}
+	if texture, goErr := newGoTexture(data); goErr == nil {
+		return texture, nil
+	}
+	return nil, err
+}
+
+// newGoTexture creates a texture from encoded image data decoded by Go
+func newGoTexture(data []byte) (*gdk.Texture, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return memoryTexture(toRGBA(src)), nil
+}
+
+// memoryTexture creates a texture holding the pixels of img, which Go
+// keeps premultiplied by alpha
+func memoryTexture(img *image.RGBA) *gdk.Texture {
+	texture := gdk.NewMemoryTexture(
+		img.Rect.Dx(), img.Rect.Dy(),
+		gdk.MemoryR8G8B8A8Premultiplied,
+		glib.NewBytesWithGo(img.Pix),
+		uint(img.Stride),
+	)
+	return &texture.Texture
+}
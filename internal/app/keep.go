@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// createKeepIndicator creates the indicator shown while a kept result's seed
+// is used for new generations. It stays hidden until a result is kept.
+func (a *App) createKeepIndicator() *gtk.Box {
+	a.keepBox = gtk.NewBox(gtk.OrientationHorizontal, 4)
+	a.keepBox.SetVisible(false)
+
+	a.keepLabel = gtk.NewLabel("")
+
+	clearBtn := gtk.NewButtonWithLabel(tr("Clear"))
+	clearBtn.SetTooltipText(tr("Stop reusing the kept seed"))
+	clearBtn.ConnectClicked(a.clearKept)
+
+	a.keepBox.Append(a.keepLabel)
+	a.keepBox.Append(clearBtn)
+
+	return a.keepBox
+}
+
+// keepResult makes a result's seed and settings the base for the next
+// generations, so edits to the prompt evolve that result
+func (a *App) keepResult(batch *generationBatch) {
+	if batch.opts.Seed == nil {
+		return
+	}
+	a.keptBatch = batch
+
+	if aspectRatioCombo != nil {
+		for i, ratio := range a.config.GetSupportedAspectRatios() {
+			if ratio == batch.opts.AspectRatio {
+				aspectRatioCombo.SetSelected(uint(i))
+				break
+			}
+		}
+	}
+
+	a.keepLabel.SetText(fmt.Sprintf(tr("Keeping seed %d"), *batch.opts.Seed))
+	a.keepLabel.SetTooltipText(batch.prompt)
+	a.keepBox.SetVisible(true)
+	a.setStatus(fmt.Sprintf(tr("Kept seed %d - edit the prompt and generate to refine it"), *batch.opts.Seed))
+}
+
+// clearKept goes back to random seeds for new generations
+func (a *App) clearKept() {
+	a.keptBatch = nil
+	a.keepBox.SetVisible(false)
+}
+
+// keptSeed returns the seed of the kept result, if any
+func (a *App) keptSeed() *int {
+	if a.keptBatch == nil || a.keptBatch.opts.Seed == nil {
+		return nil
+	}
+	seed := *a.keptBatch.opts.Seed
+	return &seed
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// compactWidth is the window width below which the compact layout is used
+const compactWidth = 1200
+
+// resultGrid is the grid of images in one displayed batch, kept so the
+// images can be laid out again when the number of columns changes
+type resultGrid struct {
+	grid   *gtk.Grid
+	frames []*gtk.Frame
+	perRow int // Automatic images per row for the batch size
+}
+
+// add appends a frame to the grid, laid out with the given number of columns
+func (g *resultGrid) add(frame *gtk.Frame, columns int) {
+	g.frames = append(g.frames, frame)
+	g.place(len(g.frames)-1, columns)
+}
+
+// relayout attaches every frame again with the given number of columns
+func (g *resultGrid) relayout(columns int) {
+	for _, frame := range g.frames {
+		g.grid.Remove(frame)
+	}
+	for i := range g.frames {
+		g.place(i, columns)
+	}
+}
+
+// place attaches the frame at index i
+func (g *resultGrid) place(i, columns int) {
+	g.grid.Attach(g.frames[i], i%columns, i/columns, 1, 1)
+}
+
+// gridColumns returns the number of images per row for a grid: one in compact
+// mode, otherwise the configured count or the grid's automatic choice
+func (a *App) gridColumns(g *resultGrid) int {
+	switch {
+	case a.compact:
+		return 1
+	case a.settings.ResultsPerRow > 0:
+		return a.settings.ResultsPerRow
+	default:
+		return g.perRow
+	}
+}
+
+// relayoutResults lays out every displayed batch again
+func (a *App) relayoutResults() {
+	for _, grid := range a.resultGrids {
+		grid.relayout(a.gridColumns(grid))
+	}
+}
+
+// resultsPerRowChoices are the options of the per-row dropdown, "Auto" first
+var resultsPerRowChoices = []string{"Auto", "1", "2", "3", "4", "5", "6"}
+
+// createResultsPerRowDropDown creates the dropdown choosing how many images
+// each batch shows per row
+func (a *App) createResultsPerRowDropDown() *gtk.DropDown {
+	dropDown := gtk.NewDropDown(gtk.NewStringList(trAll(resultsPerRowChoices)), nil)
+	dropDown.SetTooltipText(tr("Images per row in the results"))
+	if n := a.settings.ResultsPerRow; n > 0 && n < len(resultsPerRowChoices) {
+		dropDown.SetSelected(uint(n))
+	}
+
+	// Index 0 is "Auto", the others equal their column count
+	dropDown.NotifyProperty("selected", func() {
+		a.settings.ResultsPerRow = int(dropDown.Selected())
+		a.saveSettings()
+		a.relayoutResults()
+	})
+
+	return dropDown
+}
+
+// setupCompactMode switches layouts as the window is resized
+func (a *App) setupCompactMode() {
+	a.win.NotifyProperty("default-width", a.updateCompactMode)
+	a.updateCompactMode()
+}
+
+// updateCompactMode switches to the compact layout when the window is narrower
+// than compactWidth: option groups stack vertically and results scroll in a
+// single column
+func (a *App) updateCompactMode() {
+	width, _ := a.win.DefaultSize()
+	if width <= 0 {
+		return
+	}
+	a.currentWidth = width
+
+	compact := width < compactWidth
+	if compact == a.compact {
+		return
+	}
+	a.compact = compact
+
+	a.optionsRow.SetOrientation(a.stackOrientation())
+	a.imageBox.SetOrientation(a.stackOrientation())
+	for _, separator := range a.resultSeparators {
+		separator.SetOrientation(a.separatorOrientation())
+	}
+	a.relayoutResults()
+}
+
+// stackOrientation returns the orientation for rows of option groups and
+// result batches, vertical in compact mode
+func (a *App) stackOrientation() gtk.Orientation {
+	if a.compact {
+		return gtk.OrientationVertical
+	}
+	return gtk.OrientationHorizontal
+}
+
+// separatorOrientation returns the orientation for separators between batches
+func (a *App) separatorOrientation() gtk.Orientation {
+	if a.compact {
+		return gtk.OrientationHorizontal
+	}
+	return gtk.OrientationVertical
+}
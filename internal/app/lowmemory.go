@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// lowMemoryDisplaySize is the largest dimension results are displayed at in
+// low memory mode
+const lowMemoryDisplaySize = 1024
+
+// addLowMemoryAction adds the toggle for low memory mode, saved with the
+// settings
+func (a *App) addLowMemoryAction() {
+	action := gio.NewSimpleActionStateful("low-memory", nil, glib.NewVariantBoolean(a.settings.LowMemory))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.settings.LowMemory = !a.settings.LowMemory
+		action.SetState(glib.NewVariantBoolean(a.settings.LowMemory))
+		a.saveSettings()
+
+		if a.settings.LowMemory {
+			a.setStatus(fmt.Sprintf(tr("Low memory mode: new results are shown at up to %d pixels and downloaded again to save, copy, view or transform them"), lowMemoryDisplaySize))
+		} else {
+			a.setStatus(tr("Low memory mode off: new results are kept in memory for instant saving"))
+		}
+	})
+	a.win.AddAction(action)
+}
+
+// newLowMemoryResultImage creates a result image that keeps only small
+// textures, downloading the image again whenever its bytes are needed
+func (a *App) newLowMemoryResultImage(url string, data []byte, contentType string) (*resultImage, error) {
+	img, err := newResultImage(url, data, contentType, min(a.config.GetMaxDisplaySize(), lowMemoryDisplaySize))
+	if err != nil {
+		return nil, err
+	}
+
+	// Animations and videos play from memory, so their bytes are kept
+	if img.motion {
+		return img, nil
+	}
+
+	// The recent strip outlives the results, so it gets its own small
+	// texture rather than holding on to the displayed one
+	if thumbnail, err := newDisplayTexture(data, recentThumbSize*2); err == nil {
+		img.thumbnail = thumbnail
+	}
+
+	img.data = nil
+	img.fetch = func() ([]byte, error) {
+		data, _, err := a.client.Download(context.Background(), url)
+		return data, err
+	}
+	return img, nil
+}
+
+// withResultTexture gets the full resolution texture of a result in the
+// background, as it may be downloaded again, and passes it to fn on the
+// main thread
+func (a *App) withResultTexture(img *resultImage, action string, fn func(texture *gdk.Texture)) {
+	go func() {
+		texture, err := img.fullTexture()
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error %s image: %v"), action, err))
+				return
+			}
+			fn(texture)
+		})
+	}()
+}
+
+// offscreenMargin is how far out of view, in pixels, a result can be
+// scrolled in low memory mode before its texture is released
+const offscreenMargin = 1000
+
+// watchResultPicture lets the texture of img shown in picture be released
+// while it is scrolled far out of view in low memory mode
+func (a *App) watchResultPicture(img *resultImage, picture *gtk.Picture) {
+	if !a.settings.LowMemory {
+		return
+	}
+	if a.resultPictures == nil {
+		a.resultPictures = map[*resultImage]*gtk.Picture{}
+	}
+	a.resultPictures[img] = picture
+}
+
+// watchOffscreenResults releases the textures of results scrolled far out
+// of scrollWin and loads them again as they come back into view
+func (a *App) watchOffscreenResults(scrollWin *gtk.ScrolledWindow) {
+	update := func() {
+		width, height := float32(scrollWin.Width()), float32(scrollWin.Height())
+		for img, picture := range a.resultPictures {
+			bounds, ok := picture.ComputeBounds(scrollWin)
+			if !ok {
+				continue
+			}
+			near := bounds.X()+bounds.Width() > -offscreenMargin && bounds.X() < width+offscreenMargin &&
+				bounds.Y()+bounds.Height() > -offscreenMargin && bounds.Y() < height+offscreenMargin
+
+			switch {
+			case !near && !img.restoring && img.displayTexture() != nil:
+				// Keep the space the image took so the layout does not move
+				picture.SetSizeRequest(picture.Width(), picture.Height())
+				picture.SetPaintable(nil)
+				img.releaseTexture()
+			case near && !img.restoring && img.displayTexture() == nil:
+				a.restoreResultTexture(img, picture)
+			}
+		}
+	}
+	scrollWin.HAdjustment().ConnectValueChanged(update)
+	scrollWin.VAdjustment().ConnectValueChanged(update)
+}
+
+// restoreResultTexture loads the released texture of img again in the
+// background and shows it in picture
+func (a *App) restoreResultTexture(img *resultImage, picture *gtk.Picture) {
+	img.restoring = true
+	go func() {
+		texture, err := img.restoreTexture()
+		glib.IdleAdd(func() {
+			img.restoring = false
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error loading image again: %v"), err))
+				return
+			}
+			picture.SetPaintable(texture)
+			picture.SetSizeRequest(minResultSize, minResultSize)
+		})
+	}()
+}
+
+// releaseResultTextures drops the textures of results removed from view.
+// Results kept for comparing batches load theirs again when compared.
+func (a *App) releaseResultTextures() {
+	for img, picture := range a.resultPictures {
+		picture.SetPaintable(nil)
+		img.releaseTexture()
+	}
+	a.resultPictures = nil
+}
+
+// withDisplayTexture passes the display texture of img to fn on the main
+// thread, loading it again first if it was released
+func (a *App) withDisplayTexture(img *resultImage, fn func(texture *gdk.Texture)) {
+	if texture := img.displayTexture(); texture != nil {
+		fn(texture)
+		return
+	}
+	go func() {
+		texture, err := img.restoreTexture()
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error loading image again: %v"), err))
+				return
+			}
+			fn(texture)
+		})
+	}()
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/flux"
+)
+
+// imageMetadata describes how a saved image was generated. It is written
+// beside the image as a JSON sidecar file with the same name.
+type imageMetadata struct {
+	Prompt      string    `json:"prompt"`
+	Seed        *int      `json:"seed,omitempty"`
+	AspectRatio string    `json:"aspect_ratio,omitempty"`
+	Format      string    `json:"format,omitempty"` // MIME type of the saved image
+	Guidance    float64   `json:"guidance,omitempty"`
+	Steps       int       `json:"steps,omitempty"`
+	BaseImage   string    `json:"base_image,omitempty"` // URL or file path of the image-to-image base
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// newImageMetadata returns the metadata of an image of batch saved in format
+func newImageMetadata(batch *generationBatch, format imageFormat) *imageMetadata {
+	if batch == nil {
+		return nil
+	}
+	return &imageMetadata{
+		Prompt:      batch.prompt,
+		Seed:        batch.opts.Seed,
+		AspectRatio: batch.opts.AspectRatio,
+		Format:      format.MIME,
+		Guidance:    batch.opts.Guidance,
+		Steps:       batch.opts.Steps,
+		BaseImage:   batch.opts.ImageSource,
+		Tags:        batch.tags.list(),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// metadataOptions returns the options generating the image described by
+// meta again: a single image with its seed, settings, format and base
+// image, and the defaults for what the metadata does not record. The base
+// image is loaded again, so call it off the main thread.
+func (a *App) metadataOptions(meta *imageMetadata) (flux.GenerateOptions, error) {
+	opts := flux.GenerateOptions{
+		NumOutputs:   1,
+		AspectRatio:  meta.AspectRatio,
+		OutputFormat: a.config.GetDefaultFormat(),
+		Quality:      a.config.GetDefaultQuality(),
+		Seed:         meta.Seed,
+		Guidance:     meta.Guidance,
+		Steps:        meta.Steps,
+	}
+	if opts.AspectRatio == "" {
+		opts.AspectRatio = a.config.GetDefaultAspectRatio()
+	}
+	if format, ok := formatForMIME(meta.Format); ok {
+		opts.OutputFormat = strings.TrimPrefix(format.Ext, ".")
+	}
+	if meta.BaseImage != "" {
+		img, _, err := a.readBaseImage(meta.BaseImage)
+		if err != nil {
+			return opts, fmt.Errorf("failed to load base image %s: %w", meta.BaseImage, err)
+		}
+		opts.Image, opts.ImageSource = img.value, img.source
+	}
+	return opts, nil
+}
+
+// sidecarPath returns the metadata file of the image at imagePath. Copies
+// in other formats share it.
+func sidecarPath(imagePath string) string {
+	return strings.TrimSuffix(imagePath, filepath.Ext(imagePath)) + ".json"
+}
+
+// marshalSidecar returns the contents of the sidecar file for meta
+func marshalSidecar(meta *imageMetadata) ([]byte, error) {
+	return json.MarshalIndent(meta, "", "  ")
+}
+
+// writeSidecar writes meta beside the image at imagePath
+func writeSidecar(imagePath string, meta *imageMetadata) error {
+	data, err := marshalSidecar(meta)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(sidecarPath(imagePath), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// readSidecar returns the metadata saved beside the image at imagePath, or
+// nil if there is none
+func readSidecar(imagePath string) *imageMetadata {
+	data, err := os.ReadFile(sidecarPath(imagePath))
+	if err != nil {
+		return nil
+	}
+	var meta imageMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil
+	}
+	return &meta
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// motionFormats lists the animation and video formats some backends return.
+// They are saved exactly as downloaded, never converted. Animated WebP
+// shares the WebP format of still images.
+var motionFormats = []imageFormat{
+	{Name: "GIF", MIME: "image/gif", Ext: ".gif", Patterns: []string{"*.gif"}},
+	{Name: "MP4", MIME: "video/mp4", Ext: ".mp4", Patterns: []string{"*.mp4"}},
+	{Name: "WebM", MIME: "video/webm", Ext: ".webm", Patterns: []string{"*.webm"}},
+}
+
+// knownFormats returns the still image formats followed by the motion ones
+func knownFormats() []imageFormat {
+	return slices.Concat(imageFormats, motionFormats)
+}
+
+// isMotion reports whether data is an animation or a video rather than a
+// still image. GIF and WebP files count only when they have several frames.
+func isMotion(data []byte) bool {
+	mediaType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	switch mediaType {
+	case "image/gif":
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		return err == nil && len(animation.Image) > 1
+	case "image/webp":
+		return isAnimatedWebP(data)
+	}
+	return strings.HasPrefix(mediaType, "video/")
+}
+
+// isAnimatedWebP reports whether WebP data sets the animation flag of its
+// extended header
+func isAnimatedWebP(data []byte) bool {
+	const animationFlag = 0x02
+	return len(data) > 20 && string(data[12:16]) == "VP8X" && data[20]&animationFlag != 0
+}
+
+// motionPlaceholder returns the texture standing in for a video's frames
+// where a still picture is needed, such as the recent strip
+func motionPlaceholder() *gdk.Texture {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 9))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 0x40}), image.Point{}, draw.Src)
+	return memoryTexture(img)
+}
+
+// createMotionPlayer creates the player looping an animated or video result
+// in place of its picture, muted until unmuted in its controls, and a
+// button playing or pausing it
+func (a *App) createMotionPlayer(img *resultImage, size int) (*gtk.Video, *gtk.Button) {
+	media := gtk.NewMediaFileForInputStream(gio.NewMemoryInputStreamFromBytes(glib.NewBytesWithGo(img.data)))
+	media.SetLoop(true)
+	media.SetMuted(true)
+
+	player := gtk.NewVideo()
+	player.SetMediaStream(media)
+	player.SetLoop(true)
+	// Flagged results stay still behind their cover until revealed
+	player.SetAutoplay(img.cover == nil)
+	player.SetHExpand(true)
+	player.SetVExpand(true)
+	player.SetSizeRequest(size, size)
+
+	playBtn := gtk.NewButtonWithLabel(tr("Play"))
+	playBtn.ConnectClicked(func() {
+		if media.Playing() {
+			media.Pause()
+		} else {
+			media.Play()
+		}
+	})
+	media.NotifyProperty("playing", func() {
+		if media.Playing() {
+			playBtn.SetLabel(tr("Pause"))
+		} else {
+			playBtn.SetLabel(tr("Play"))
+		}
+	})
+
+	// Systems without a media backend cannot play anything
+	media.NotifyProperty("error", func() {
+		if err := media.Error(); err != nil {
+			a.setStatus(fmt.Sprintf(tr("Error playing %s: %v"), defaultImageName(img.url), err))
+			playBtn.SetSensitive(false)
+		}
+	})
+	return player, playBtn
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// minPromptRows is how many prompt fields the multi-prompt dialog opens with
+const minPromptRows = 2
+
+// showMultiPrompt shows a dialog for filling several prompts that are
+// generated together, such as the shots of a storyboard. It opens with
+// the prompts of the last batch, or the typed prompt.
+func (a *App) showMultiPrompt() {
+	prompts := a.multiPrompts
+	if len(prompts) == 0 && a.promptText() != "" {
+		prompts = []string{a.promptText()}
+	}
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Generate Several Prompts"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(560, -1)
+
+	hintLabel := gtk.NewLabel(tr("Each prompt is generated with the current settings and its results are grouped under it."))
+	hintLabel.AddCSSClass("dim-label")
+	hintLabel.SetWrap(true)
+	hintLabel.SetXAlign(0)
+
+	rowsBox := gtk.NewBox(gtk.OrientationVertical, 6)
+	var entries []*gtk.Entry
+	var numberLabels []*gtk.Label
+
+	// Rows are numbered like the groups of results they become
+	var renumber func()
+	var addRow func(text string) *gtk.Entry
+	addRow = func(text string) *gtk.Entry {
+		numberLabel := gtk.NewLabel("")
+		numberLabel.SetWidthChars(3)
+		numberLabel.SetXAlign(1)
+
+		entry := gtk.NewEntry()
+		entry.SetText(text)
+		entry.SetHExpand(true)
+
+		removeBtn := gtk.NewButtonFromIconName("list-remove-symbolic")
+		removeBtn.SetTooltipText(tr("Remove prompt"))
+
+		row := gtk.NewBox(gtk.OrientationHorizontal, 6)
+		row.Append(numberLabel)
+		row.Append(entry)
+		row.Append(removeBtn)
+		rowsBox.Append(row)
+		entries = append(entries, entry)
+		numberLabels = append(numberLabels, numberLabel)
+
+		removeBtn.ConnectClicked(func() {
+			for i, e := range entries {
+				if e == entry {
+					entries = slices.Delete(entries, i, i+1)
+					numberLabels = slices.Delete(numberLabels, i, i+1)
+					break
+				}
+			}
+			rowsBox.Remove(row)
+			renumber()
+		})
+
+		// Enter moves on to the next prompt, adding one after the last
+		entry.ConnectActivate(func() {
+			for i, e := range entries {
+				if e != entry {
+					continue
+				}
+				if i+1 < len(entries) {
+					entries[i+1].GrabFocus()
+				} else {
+					addRow("").GrabFocus()
+				}
+				return
+			}
+		})
+
+		renumber()
+		return entry
+	}
+	renumber = func() {
+		for i, label := range numberLabels {
+			label.SetText(fmt.Sprintf("%d.", i+1))
+		}
+	}
+
+	for _, prompt := range prompts {
+		addRow(prompt)
+	}
+	for len(entries) < minPromptRows {
+		addRow("")
+	}
+
+	addBtn := gtk.NewButtonWithLabel(tr("Add Prompt"))
+	addBtn.SetHAlign(gtk.AlignStart)
+	addBtn.ConnectClicked(func() {
+		addRow("").GrabFocus()
+	})
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrollWin.SetPropagateNaturalHeight(true)
+	scrollWin.SetMaxContentHeight(400)
+	scrollWin.SetChild(rowsBox)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(12)
+	contentBox.SetMarginBottom(12)
+	contentBox.SetMarginStart(12)
+	contentBox.SetMarginEnd(12)
+	contentBox.Append(hintLabel)
+	contentBox.Append(scrollWin)
+	contentBox.Append(addBtn)
+
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Generate"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		defer dialog.Destroy()
+		if responseId != int(gtk.ResponseAccept) {
+			return
+		}
+
+		var filled []string
+		for _, entry := range entries {
+			if text := strings.TrimSpace(entry.Text()); text != "" {
+				filled = append(filled, text)
+			}
+		}
+		if len(filled) == 0 {
+			a.setStatus(tr("Please enter a prompt"))
+			return
+		}
+		a.multiPrompts = filled
+		a.generatePrompts(filled)
+	})
+	dialog.Show()
+	entries[0].GrabFocus()
+}
+
+// generatePrompts queues one generation per prompt as a single batch, each
+// labeled with its number so its results are grouped under a header
+func (a *App) generatePrompts(prompts []string) {
+	a.hideSafetyRetry()
+
+	opts := a.selectedOptions()
+	runs := make([]sweepRun, 0, len(prompts))
+	for i, prompt := range prompts {
+		prompt, err := expandVariables(prompt, a.variables)
+		if err != nil {
+			a.setStatus(fmt.Sprintf(tr("Error: %v"), err))
+			return
+		}
+		runs = append(runs, sweepRun{label: fmt.Sprintf(tr("Prompt %d"), i+1), prompt: prompt, opts: opts})
+	}
+
+	if a.dryRun {
+		a.showDryRun("", runs)
+		return
+	}
+	appendMode := a.appendToggle != nil && a.appendToggle.Active()
+	a.enqueueGeneration("", runs, appendMode)
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// checkExistingFile calls save with the path to write, after handling
+// existing files as chosen in the settings: either confirming before
+// replacing them or saving beside them with a numbered name. Besides path
+// itself this covers the files saved next to it with the extensions in
+// beside, such as copies and the metadata sidecar. The file chooser already
+// confirms replacing the name picked in it, so chosen itself is not checked
+// and is only renamed when the user asks to, since they picked that name.
+func (a *App) checkExistingFile(path, chosen string, beside []string, save func(path string)) {
+	existing := existingFile(path, beside, path == chosen)
+	if existing == "" {
+		save(path)
+		return
+	}
+
+	if a.settings.ExistingFiles == config.ExistingRename && path != chosen {
+		save(uniquePath(path, beside...))
+		return
+	}
+
+	dialog := gtk.NewMessageDialog(
+		&a.win.Window,
+		gtk.DialogModal|gtk.DialogDestroyWithParent,
+		gtk.MessageQuestion,
+		gtk.ButtonsNone,
+	)
+	dialog.SetObjectProperty("text", fmt.Sprintf(tr("Replace %q?"), filepath.Base(existing)))
+	dialog.SetObjectProperty("secondary-text", tr("A file with this name already exists. Replacing it overwrites its contents."))
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Save with a Number"), int(gtk.ResponseNo))
+	dialog.AddButton(tr("Replace"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+		switch responseId {
+		case int(gtk.ResponseAccept):
+			save(path)
+		case int(gtk.ResponseNo):
+			save(uniquePath(path, beside...))
+		default:
+			a.setStatus(tr("Save cancelled"))
+		}
+	})
+	dialog.Show()
+}
+
+// uniquePath returns path, or when it or a file beside it with one of the
+// extensions in beside exists the first free name of the form
+// "name (1).png", "name (2).png" and so on
+func uniquePath(path string, beside ...string) string {
+	if !anyExists(path, beside) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !anyExists(candidate, beside) {
+			return candidate
+		}
+	}
+}
+
+// anyExists reports whether path or a file named like it with one of the
+// extensions in beside exists
+func anyExists(path string, beside []string) bool {
+	return existingFile(path, beside, false) != ""
+}
+
+// existingFile returns the first of path and the files named like it with
+// the extensions in beside that exists, leaving out path itself with
+// besideOnly, or "" if there is none
+func existingFile(path string, beside []string, besideOnly bool) string {
+	var names []string
+	if !besideOnly {
+		names = append(names, path)
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range beside {
+		if name := base + ext; name != path {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+	}
+	return ""
+}
+
+// besideExts returns the extensions of the files saved next to an image:
+// a copy per format in copies and, with sidecar, the metadata
+func besideExts(copies []imageFormat, sidecar bool) []string {
+	var exts []string
+	for _, f := range copies {
+		exts = append(exts, f.Ext)
+	}
+	if sidecar {
+		exts = append(exts, ".json")
+	}
+	return exts
+}
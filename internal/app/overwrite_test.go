@@ -0,0 +1,67 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUniquePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.png")
+
+	if got := uniquePath(path); got != path {
+		t.Errorf("got %s for a free name, want %s", got, path)
+	}
+
+	for _, name := range []string{"image.png", "image (1).png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := uniquePath(path), filepath.Join(dir, "image (2).png"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestUniquePathBeside(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.png")
+	if err := os.WriteFile(filepath.Join(dir, "image.json"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := uniquePath(path); got != path {
+		t.Errorf("got %s without files beside, want %s", got, path)
+	}
+	if got, want := uniquePath(path, ".jpg", ".json"), filepath.Join(dir, "image (1).png"); got != want {
+		t.Errorf("got %s with a sidecar in the way, want %s", got, want)
+	}
+}
+
+func TestExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.png")
+	for _, name := range []string{"image.png", "image.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		beside     []string
+		besideOnly bool
+		want       string
+	}{
+		{nil, false, path},
+		{nil, true, ""},
+		{[]string{".json"}, true, ""},
+		{[]string{".json", ".jpg"}, true, filepath.Join(dir, "image.jpg")},
+		{[]string{".png"}, true, ""},
+	}
+	for _, tt := range tests {
+		if got := existingFile(path, tt.beside, tt.besideOnly); got != tt.want {
+			t.Errorf("existingFile(%v, %v) = %q, want %q", tt.beside, tt.besideOnly, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// paletteCommand is an entry of the command palette, running a window
+// action like the menus and shortcuts do
+type paletteCommand struct {
+	label  string
+	action string // Detailed action name, e.g. "win.existing-files::ask"
+}
+
+// paletteCommands lists the commands the palette offers, in display order
+func paletteCommands() []paletteCommand {
+	commands := []paletteCommand{
+		{tr("Generate"), "win.generate"},
+		{tr("Generate Several Prompts"), "win.generate-prompts"},
+		{tr("Duplicate Last"), "win.duplicate-last"},
+		{tr("Stop All"), "win.stop-all"},
+		{tr("Save Image"), "win.save-result"},
+		{tr("Reset to Defaults"), "win.reset-controls"},
+		{tr("Focus Prompt"), "win.focus-prompt"},
+		{tr("Pin or Unpin Prompt"), "win.toggle-favorite"},
+		{tr("Open Generator"), "win.show-mode::" + modeGenerator},
+		{tr("Open Upscaler"), "win.show-mode::" + modeUpscaler},
+		{tr("Open Gallery"), "win.show-mode::" + modeGallery},
+		{tr("Copy Request JSON"), "win.copy-request-json"},
+		{tr("Show Last Response"), "win.show-last-response"},
+		{tr("Dry Run"), "win.dry-run"},
+		{tr("Edit API Headers"), "win.edit-api-headers"},
+		{tr("Export Session"), "win.export-session"},
+		{tr("Import Session"), "win.import-session"},
+		{tr("Statistics"), "win.show-stats"},
+		{tr("Compare with Previous Batch"), "win.compare-previous"},
+		{tr("Open Config Folder"), "win.open-config-folder"},
+		{tr("Open Cache Folder"), "win.open-cache-folder"},
+		{tr("Keyboard Shortcuts"), "win.show-help-overlay"},
+		{tr("Auto-save All Generations"), "win.auto-save"},
+		{tr("Low Memory Mode"), "win.low-memory"},
+		{tr("Wheel Scrolls Results Sideways"), "win.wheel-scrolls-sideways"},
+		{tr("Blur Sensitive Images"), "win.blur-flagged"},
+		{tr("Show Tray Icon"), "win.tray-icon"},
+		{tr("Load Images with GDK, Falling Back to Go"), "win.image-loader::" + config.ImageLoaderAuto},
+		{tr("Load Images with GDK Only"), "win.image-loader::" + config.ImageLoaderGDK},
+		{tr("Load Images with Go Only"), "win.image-loader::" + config.ImageLoaderGo},
+		{tr("PNG Compression: Fastest Saves"), "win.png-compression::" + config.PNGCompressionFast},
+		{tr("PNG Compression: Default"), "win.png-compression::" + config.PNGCompressionDefault},
+		{tr("PNG Compression: Smallest Files"), "win.png-compression::" + config.PNGCompressionBest},
+	}
+	for _, scale := range uiScales {
+		commands = append(commands, paletteCommand{fmt.Sprintf(tr("UI Scale %d%%"), scale), fmt.Sprintf("win.ui-scale(%d)", scale)})
+	}
+	return commands
+}
+
+// showCommandPalette shows a searchable list of commands. Typing filters
+// it, Enter runs the selected command and Escape closes it.
+func (a *App) showCommandPalette() {
+	commands := paletteCommands()
+
+	search := gtk.NewSearchEntry()
+	search.SetPlaceholderText(tr("Type a command"))
+
+	list := gtk.NewListBox()
+	list.SetSelectionMode(gtk.SelectionBrowse)
+	for _, command := range commands {
+		row := gtk.NewBox(gtk.OrientationHorizontal, 8)
+		row.SetMarginTop(4)
+		row.SetMarginBottom(4)
+		row.SetMarginStart(8)
+		row.SetMarginEnd(8)
+
+		label := gtk.NewLabel(command.label)
+		label.SetXAlign(0)
+		label.SetHExpand(true)
+		row.Append(label)
+
+		if shortcut := shortcutAccel(command.action); shortcut != "" {
+			accel := gtk.NewLabel(accelLabel(shortcut))
+			accel.AddCSSClass("dim-label")
+			row.Append(accel)
+		}
+		list.Append(row)
+	}
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrollWin.SetChild(list)
+	scrollWin.SetVExpand(true)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.Append(search)
+	contentBox.Append(scrollWin)
+
+	window := gtk.NewWindow()
+	window.SetTitle(tr("Commands"))
+	window.SetTransientFor(&a.win.Window)
+	window.SetModal(true)
+	window.SetDefaultSize(420, 360)
+	window.SetChild(contentBox)
+
+	// Rows are appended in the order of the commands, so their index finds
+	// the command. Disabled actions are left out.
+	var words []string
+	list.SetFilterFunc(func(row *gtk.ListBoxRow) bool {
+		command := commands[row.Index()]
+		if !a.commandEnabled(command.action) {
+			return false
+		}
+		label := strings.ToLower(command.label)
+		for _, word := range words {
+			if !strings.Contains(label, word) {
+				return false
+			}
+		}
+		return true
+	})
+	selectFirst := func() {
+		for i := range commands {
+			if row := list.RowAtIndex(i); row.ChildVisible() {
+				list.SelectRow(row)
+				return
+			}
+		}
+	}
+	search.ConnectSearchChanged(func() {
+		words = strings.Fields(strings.ToLower(search.Text()))
+		list.InvalidateFilter()
+		selectFirst()
+	})
+
+	run := func(row *gtk.ListBoxRow) {
+		if row == nil {
+			return
+		}
+		window.Close()
+		a.runCommand(commands[row.Index()].action)
+	}
+	list.ConnectRowActivated(run)
+	search.ConnectActivate(func() {
+		run(list.SelectedRow())
+	})
+	search.ConnectStopSearch(window.Close)
+
+	// Arrow keys move through the list while typing in the search
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval, keycode uint, state gdk.ModifierType) bool {
+		var step int
+		switch keyval {
+		case gdk.KEY_Down:
+			step = 1
+		case gdk.KEY_Up:
+			step = -1
+		default:
+			return false
+		}
+		selected := list.SelectedRow()
+		if selected == nil {
+			return true
+		}
+		for i := selected.Index() + step; i >= 0 && i < len(commands); i += step {
+			if row := list.RowAtIndex(i); row.ChildVisible() {
+				list.SelectRow(row)
+				break
+			}
+		}
+		return true
+	})
+	search.AddController(keys)
+
+	selectFirst()
+	window.Present()
+	search.GrabFocus()
+}
+
+// commandEnabled reports whether the action a command runs is enabled
+func (a *App) commandEnabled(detailedName string) bool {
+	name, _, err := gio.ActionParseDetailedName(strings.TrimPrefix(detailedName, "win."))
+	if err != nil {
+		return false
+	}
+	action := a.win.LookupAction(name)
+	return action != nil && action.Enabled()
+}
+
+// runCommand activates the window action named by a command
+func (a *App) runCommand(detailedName string) {
+	name, target, err := gio.ActionParseDetailedName(detailedName)
+	if err != nil {
+		return
+	}
+	a.win.ActivateAction(name, target)
+}
+
+// accelLabel returns the readable form of the first accelerator in accel,
+// e.g. "Ctrl+D" for "<Control>d"
+func accelLabel(accel string) string {
+	first, _, _ := strings.Cut(accel, "|")
+	key, mods, ok := gtk.AcceleratorParse(first)
+	if !ok {
+		return ""
+	}
+	return gtk.AcceleratorGetLabel(key, mods)
+}
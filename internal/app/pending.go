@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+
+	"fluxxxer/internal/flux"
+)
+
+// resumePending queues the predictions left unfinished by an earlier session,
+// so their progress on the backend is not lost
+func (a *App) resumePending() {
+	if a.config.IsOffline() || a.config.GetConfigDir() == "" {
+		return
+	}
+
+	pending, err := flux.NewPendingStore(a.config.GetConfigDir()).Load()
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error loading pending generations: %v"), err))
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	group := &jobGroup{appendMode: true, total: len(pending)}
+	for i := range pending {
+		p := pending[i]
+		seed := p.Seed
+		job := &generationJob{
+			prompt: p.Prompt,
+			label:  "Resumed: " + p.Prompt,
+			opts: flux.GenerateOptions{
+				NumOutputs:   p.NumOutputs,
+				AspectRatio:  p.AspectRatio,
+				OutputFormat: a.config.GetDefaultFormat(),
+				Quality:      a.config.GetDefaultQuality(),
+				Seed:         &seed,
+			},
+			group:   group,
+			status:  jobQueued,
+			pending: &p,
+		}
+		a.queue = append(a.queue, job)
+		a.addJobRow(job)
+	}
+
+	a.updateQueueTitle()
+	a.setStatus(fmt.Sprintf(tr("Resuming %d unfinished generation(s)"), len(pending)))
+	a.processQueue()
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"sync/atomic"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+)
+
+// pngCompression holds the png.CompressionLevel PNG files are encoded
+// with. Images are encoded off the main thread, so it is kept apart from
+// the settings.
+var pngCompression atomic.Int32
+
+// pngCompressionLevel returns the encoder level of a PNGCompression setting
+func pngCompressionLevel(setting string) png.CompressionLevel {
+	switch setting {
+	case config.PNGCompressionFast:
+		return png.BestSpeed
+	case config.PNGCompressionBest:
+		return png.BestCompression
+	}
+	return png.DefaultCompression
+}
+
+// encodePNG writes img to w as PNG at the chosen compression level. It
+// encodes the PNG files the app writes itself: copies, conversions and
+// images rotated, flipped or annotated before saving. Downloaded PNG files
+// are saved as they are.
+func encodePNG(w io.Writer, img image.Image) error {
+	encoder := png.Encoder{CompressionLevel: png.CompressionLevel(pngCompression.Load())}
+	return encoder.Encode(w, img)
+}
+
+// addPNGCompressionAction adds the radio choice of how hard PNG files are
+// compressed when the app encodes them, saved with the settings
+func (a *App) addPNGCompressionAction() {
+	pngCompression.Store(int32(pngCompressionLevel(a.settings.PNGCompression)))
+
+	action := gio.NewSimpleActionStateful(
+		"png-compression",
+		glib.NewVariantType("s"),
+		glib.NewVariantString(a.settings.PNGCompression),
+	)
+	action.ConnectActivate(func(parameter *glib.Variant) {
+		action.SetState(parameter)
+		a.settings.PNGCompression = parameter.String()
+		pngCompression.Store(int32(pngCompressionLevel(a.settings.PNGCompression)))
+		a.saveSettings()
+	})
+	a.win.AddAction(action)
+}
+
+// createPNGCompressionMenu creates the radio items choosing the PNG
+// compression
+func createPNGCompressionMenu() *gio.Menu {
+	menu := gio.NewMenu()
+	menu.Append(tr("Fastest Saves"), "win.png-compression::"+config.PNGCompressionFast)
+	menu.Append(tr("Default"), "win.png-compression::"+config.PNGCompressionDefault)
+	menu.Append(tr("Smallest Files"), "win.png-compression::"+config.PNGCompressionBest)
+	return menu
+}
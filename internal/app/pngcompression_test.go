@@ -0,0 +1,71 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"fluxxxer/internal/config"
+)
+
+func TestPNGCompressionLevel(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    png.CompressionLevel
+	}{
+		{config.PNGCompressionFast, png.BestSpeed},
+		{config.PNGCompressionDefault, png.DefaultCompression},
+		{config.PNGCompressionBest, png.BestCompression},
+		{"", png.DefaultCompression},
+		{"fastest", png.DefaultCompression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			if got := pngCompressionLevel(tt.setting); got != tt.want {
+				t.Errorf("pngCompressionLevel(%q) = %v, want %v", tt.setting, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodePNG(t *testing.T) {
+	// Smooth gradients compress differently at each level
+	src := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			i := src.PixOffset(x, y)
+			src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = uint8(x*4), uint8(y*5), uint8(x*y), uint8(255-x)
+		}
+	}
+
+	defer pngCompression.Store(pngCompression.Load())
+
+	sizes := map[string]int{}
+	for _, setting := range []string{config.PNGCompressionFast, config.PNGCompressionDefault, config.PNGCompressionBest} {
+		pngCompression.Store(int32(pngCompressionLevel(setting)))
+
+		var buf bytes.Buffer
+		if err := encodePNG(&buf, src); err != nil {
+			t.Fatalf("%s: unexpected error: %v", setting, err)
+		}
+		sizes[setting] = buf.Len()
+
+		decoded, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: encoded PNG does not decode: %v", setting, err)
+		}
+		got, ok := decoded.(*image.NRGBA)
+		if !ok {
+			t.Fatalf("%s: decoded as %T, want *image.NRGBA", setting, decoded)
+		}
+		if got.Bounds() != src.Bounds() || !bytes.Equal(got.Pix, src.Pix) {
+			t.Errorf("%s: pixels changed in the round trip", setting)
+		}
+	}
+
+	if sizes[config.PNGCompressionBest] > sizes[config.PNGCompressionFast] {
+		t.Errorf("best compression gave %d bytes, more than the %d of the fastest", sizes[config.PNGCompressionBest], sizes[config.PNGCompressionFast])
+	}
+}
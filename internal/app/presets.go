@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// createPresetsMenu creates the menu button for saving and applying presets
+func (a *App) createPresetsMenu() *gtk.MenuButton {
+	presets, err := config.LoadPresets(a.config.GetConfigDir())
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error loading presets: %v"), err))
+	}
+	a.presets = presets
+
+	panelBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	panelBox.SetMarginTop(8)
+	panelBox.SetMarginBottom(8)
+	panelBox.SetMarginStart(8)
+	panelBox.SetMarginEnd(8)
+
+	a.presetList = gtk.NewListBox()
+	a.presetList.SetSelectionMode(gtk.SelectionNone)
+
+	// Save the current settings under a name
+	saveBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+
+	nameEntry := gtk.NewEntry()
+	nameEntry.SetPlaceholderText(tr("Preset name"))
+	nameEntry.SetHExpand(true)
+
+	saveBtn := gtk.NewButtonWithLabel(tr("Save Current"))
+	save := func() {
+		if a.savePreset(nameEntry.Text()) {
+			nameEntry.SetText("")
+		}
+	}
+	saveBtn.ConnectClicked(save)
+	nameEntry.ConnectActivate(save)
+
+	saveBox.Append(nameEntry)
+	saveBox.Append(saveBtn)
+
+	panelBox.Append(a.presetList)
+	panelBox.Append(saveBox)
+
+	a.presetsPopover = gtk.NewPopover()
+	a.presetsPopover.SetChild(panelBox)
+
+	menuBtn := gtk.NewMenuButton()
+	menuBtn.SetLabel(tr("Presets"))
+	menuBtn.SetTooltipText(tr("Save or apply the prompt and every generation setting together"))
+	menuBtn.SetPopover(a.presetsPopover)
+
+	a.refreshPresetList()
+
+	return menuBtn
+}
+
+// refreshPresetList rebuilds the rows of the presets menu
+func (a *App) refreshPresetList() {
+	for child := a.presetList.FirstChild(); child != nil; child = a.presetList.FirstChild() {
+		a.presetList.Remove(child)
+	}
+
+	if len(a.presets) == 0 {
+		emptyLabel := gtk.NewLabel(tr("No presets saved yet"))
+		emptyLabel.SetMarginTop(4)
+		emptyLabel.SetMarginBottom(4)
+		a.presetList.Append(emptyLabel)
+		return
+	}
+
+	for _, preset := range a.presets {
+		rowBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+
+		nameLabel := gtk.NewLabel(preset.Name)
+		nameLabel.SetXAlign(0)
+		nameLabel.SetEllipsize(pango.EllipsizeEnd)
+
+		applyBtn := gtk.NewButton()
+		applyBtn.SetChild(nameLabel)
+		applyBtn.SetHExpand(true)
+		applyBtn.SetTooltipText(fmt.Sprintf(tr("%s — %s, %d images"), preset.Prompt, preset.AspectRatio, preset.NumOutputs))
+		applyBtn.ConnectClicked(func() {
+			a.applyPreset(preset)
+		})
+
+		deleteBtn := gtk.NewButtonWithLabel(tr("Delete"))
+		deleteBtn.ConnectClicked(func() {
+			a.deletePreset(preset.Name)
+		})
+
+		rowBox.Append(applyBtn)
+		rowBox.Append(deleteBtn)
+		a.presetList.Append(rowBox)
+	}
+}
+
+// savePreset stores the current settings as a preset, replacing any preset
+// with the same name. It reports whether the preset was saved.
+func (a *App) savePreset(name string) bool {
+	if name == "" {
+		a.setStatus(tr("Enter a name for the preset"))
+		return false
+	}
+
+	opts := a.selectedOptions()
+	preset := config.Preset{
+		Name:         name,
+		Prompt:       a.promptText(),
+		AspectRatio:  opts.AspectRatio,
+		NumOutputs:   opts.NumOutputs,
+		OutputFormat: opts.OutputFormat,
+		Quality:      opts.Quality,
+		Guidance:     opts.Guidance,
+		Steps:        opts.Steps,
+		Model:        opts.Model,
+	}
+
+	presets := make([]config.Preset, 0, len(a.presets)+1)
+	replaced := false
+	for _, p := range a.presets {
+		if p.Name == name {
+			p = preset
+			replaced = true
+		}
+		presets = append(presets, p)
+	}
+	if !replaced {
+		presets = append(presets, preset)
+	}
+
+	if err := config.SavePresets(a.config.GetConfigDir(), presets); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error saving preset: %v"), err))
+		return false
+	}
+
+	a.presets = presets
+	a.refreshPresetList()
+	a.setStatus(fmt.Sprintf(tr("Saved preset %q"), name))
+	return true
+}
+
+// deletePreset removes the preset with the given name
+func (a *App) deletePreset(name string) {
+	presets := make([]config.Preset, 0, len(a.presets))
+	for _, p := range a.presets {
+		if p.Name != name {
+			presets = append(presets, p)
+		}
+	}
+
+	if err := config.SavePresets(a.config.GetConfigDir(), presets); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error deleting preset: %v"), err))
+		return
+	}
+
+	a.presets = presets
+	a.refreshPresetList()
+	a.setStatus(fmt.Sprintf(tr("Deleted preset %q"), name))
+}
+
+// applyPreset sets every generation control from the preset
+func (a *App) applyPreset(preset config.Preset) {
+	a.setPromptText(preset.Prompt)
+
+	if aspectRatioCombo != nil {
+		for i, ratio := range a.config.GetSupportedAspectRatios() {
+			if ratio == preset.AspectRatio {
+				aspectRatioCombo.SetSelected(uint(i))
+				break
+			}
+		}
+	}
+
+	if numOutputsScale != nil && preset.NumOutputs > 0 {
+		numOutputsScale.SetValue(float64(preset.NumOutputs))
+	}
+
+	// Zero leaves guidance and steps to the backend
+	if a.guidanceSpin != nil && a.stepsSpin != nil {
+		a.guidanceSpin.SetValue(preset.Guidance)
+		a.stepsSpin.SetValue(float64(preset.Steps))
+	}
+
+	a.presetFormat = preset.OutputFormat
+	a.presetQuality = preset.Quality
+	a.presetModel = preset.Model
+
+	a.presetsPopover.Popdown()
+	a.setStatus(fmt.Sprintf(tr("Applied preset %q"), preset.Name))
+}
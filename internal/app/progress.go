@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// createProgressBar creates the bar shown instead of the spinner while the
+// backend reports how far along a generation is
+func createProgressBar() *gtk.ProgressBar {
+	progressBar := gtk.NewProgressBar()
+	progressBar.SetMarginStart(8)
+	progressBar.SetVAlign(gtk.AlignCenter)
+	progressBar.SetSizeRequest(96, -1)
+	progressBar.SetVisible(false)
+	return progressBar
+}
+
+// setJobProgress records the progress the backend reported for a running
+// job and shows it
+func (a *App) setJobProgress(job *generationJob, fraction float64) {
+	// Reports may arrive after the job was stopped
+	if job.status != jobRunning {
+		return
+	}
+	job.progress = fraction
+	a.updateJobRow(job)
+	a.updateProgress()
+}
+
+// updateProgress shows the progress bar with the average progress of the
+// running jobs once any of them reports progress, and the spinner until
+// then. Jobs that have not reported count as not started.
+func (a *App) updateProgress() {
+	running, total := 0, 0.0
+	reported := false
+	for _, job := range a.queue {
+		if job.status != jobRunning {
+			continue
+		}
+		running++
+		if job.progress >= 0 {
+			total += job.progress
+			reported = true
+		}
+	}
+
+	if !reported {
+		a.progressBar.SetVisible(false)
+		a.spinner.SetVisible(true)
+		return
+	}
+	fraction := total / float64(running)
+	a.progressBar.SetFraction(fraction)
+	a.progressBar.SetTooltipText(fmt.Sprintf(tr("%d%% done"), int(fraction*100)))
+	a.spinner.SetVisible(false)
+	a.progressBar.SetVisible(true)
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// emphasisGroup matches emphasis in a prompt: (words), (words:1.3) or
+// [words] for less weight
+var emphasisGroup = regexp.MustCompile(`\([^()]*\)|\[[^\[\]]*\]`)
+
+// Names of the highlighting tags of the expanded prompt editor
+const (
+	emphasisTag = "emphasis"
+	variableTag = "variable"
+)
+
+// createPromptEditor creates the multi-line prompt editor shown instead of
+// the entry when the prompt is expanded. Ctrl+Enter generates from it.
+func (a *App) createPromptEditor() *gtk.ScrolledWindow {
+	a.promptView = gtk.NewTextView()
+	a.promptView.SetWrapMode(gtk.WrapWordChar)
+	a.promptView.SetAcceptsTab(false)
+	a.promptView.SetTopMargin(4)
+	a.promptView.SetBottomMargin(4)
+	a.promptView.SetLeftMargin(4)
+	a.promptView.SetRightMargin(4)
+	a.promptView.SetTooltipText(tr("Press Ctrl+Enter to generate"))
+
+	buffer := a.promptView.Buffer()
+	emphasis := gtk.NewTextTag(emphasisTag)
+	emphasis.SetObjectProperty("weight", int(pango.WeightBold))
+	buffer.TagTable().Add(emphasis)
+	variable := gtk.NewTextTag(variableTag)
+	variable.SetObjectProperty("foreground", "#3584e4")
+	buffer.TagTable().Add(variable)
+	buffer.ConnectChanged(func() {
+		highlightPrompt(buffer)
+	})
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval, keycode uint, state gdk.ModifierType) bool {
+		if (keyval != gdk.KEY_Return && keyval != gdk.KEY_KP_Enter) || state&gdk.ControlMask == 0 {
+			return false
+		}
+		a.onGenerateClicked()
+		return true
+	})
+	a.promptView.AddController(keys)
+
+	a.promptEditor = gtk.NewScrolledWindow()
+	a.promptEditor.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	a.promptEditor.SetChild(a.promptView)
+	a.promptEditor.SetSizeRequest(-1, 120)
+	a.promptEditor.SetVisible(false)
+
+	return a.promptEditor
+}
+
+// createExpandPromptButton creates the toggle between the single-line
+// prompt entry and the multi-line editor
+func (a *App) createExpandPromptButton() *gtk.ToggleButton {
+	expandBtn := gtk.NewToggleButton()
+	expandBtn.SetLabel(tr("Expand"))
+	expandBtn.SetTooltipText(tr("Edit the prompt over several lines, with emphasis and $variables highlighted"))
+	expandBtn.ConnectToggled(func() {
+		a.setPromptExpanded(expandBtn.Active())
+		a.settings.ExpandedPrompt = expandBtn.Active()
+		a.saveSettings()
+	})
+	expandBtn.SetActive(a.settings.ExpandedPrompt)
+	return expandBtn
+}
+
+// setPromptExpanded switches between the prompt entry and the multi-line
+// editor, carrying the text over
+func (a *App) setPromptExpanded(expanded bool) {
+	if expanded == a.promptEditor.Visible() {
+		return
+	}
+
+	text := a.promptText()
+	a.promptEditor.SetVisible(expanded)
+	a.entry.SetVisible(!expanded)
+	a.setPromptText(text)
+	a.focusPromptEnd()
+}
+
+// promptText returns the prompt from whichever editor is shown
+func (a *App) promptText() string {
+	if a.promptEditor == nil || !a.promptEditor.Visible() {
+		return a.entry.Text()
+	}
+	buffer := a.promptView.Buffer()
+	start, end := buffer.Bounds()
+	return buffer.Text(start, end, false)
+}
+
+// setPromptText replaces the prompt in whichever editor is shown
+func (a *App) setPromptText(text string) {
+	if a.promptEditor == nil || !a.promptEditor.Visible() {
+		a.entry.SetText(text)
+		return
+	}
+	a.promptView.Buffer().SetText(text)
+}
+
+// focusPromptEnd focuses the prompt with the cursor after its text
+func (a *App) focusPromptEnd() {
+	if a.promptEditor == nil || !a.promptEditor.Visible() {
+		a.entry.GrabFocus()
+		a.entry.SetPosition(-1)
+		return
+	}
+	buffer := a.promptView.Buffer()
+	buffer.PlaceCursor(buffer.EndIter())
+	a.promptView.GrabFocus()
+}
+
+// selectPrompt focuses the prompt with all of its text selected
+func (a *App) selectPrompt() {
+	if a.promptEditor == nil || !a.promptEditor.Visible() {
+		a.entry.GrabFocus()
+		a.entry.SelectRegion(0, -1)
+		return
+	}
+	buffer := a.promptView.Buffer()
+	start, end := buffer.Bounds()
+	buffer.SelectRange(start, end)
+	a.promptView.GrabFocus()
+}
+
+// highlightPrompt marks the emphasis groups and variable references in the
+// editor's text
+func highlightPrompt(buffer *gtk.TextBuffer) {
+	start, end := buffer.Bounds()
+	buffer.RemoveAllTags(start, end)
+
+	text := buffer.Text(start, end, false)
+	for _, highlight := range []struct {
+		tag     string
+		pattern *regexp.Regexp
+	}{
+		{emphasisTag, emphasisGroup},
+		{variableTag, variableRef},
+	} {
+		for _, m := range highlight.pattern.FindAllStringIndex(text, -1) {
+			// Buffer offsets count characters, not bytes
+			from := utf8.RuneCountInString(text[:m[0]])
+			to := from + utf8.RuneCountInString(text[m[0]:m[1]])
+			buffer.ApplyTagByName(highlight.tag, buffer.IterAtOffset(from), buffer.IterAtOffset(to))
+		}
+	}
+}
@@ -0,0 +1,559 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// jobStatus describes where a generation job is in the queue
+type jobStatus int
+
+const (
+	jobQueued jobStatus = iota
+	jobRunning
+	jobDone
+	jobFailed
+	jobCanceled
+)
+
+// String returns the label shown for the status in the queue panel
+func (s jobStatus) String() string {
+	switch s {
+	case jobQueued:
+		return tr("Queued")
+	case jobRunning:
+		return tr("Running")
+	case jobDone:
+		return tr("Done")
+	case jobFailed:
+		return tr("Failed")
+	case jobCanceled:
+		return tr("Canceled")
+	default:
+		return tr("Unknown")
+	}
+}
+
+// jobGroup ties together the jobs submitted by a single Generate click
+type jobGroup struct {
+	appendMode bool
+	cleared    bool // whether previous results have been replaced yet
+	total      int
+	finished   int
+	failed     int
+	images     int
+	duplicates int // duplicate result URLs hidden
+	lastErr    error
+	stopped    bool // Stopped with Stop All, so no outcome is reported
+
+	// Output format the backend rejected, so PNG was used instead
+	formatFallback string
+
+	// Requests sent again after the backend returned no images
+	emptyRetries int
+
+	// Prompt as typed, cleared or selected as chosen in the settings once a
+	// run succeeds. Empty when there is nothing to do.
+	typedPrompt string
+}
+
+// generationJob is a single queued generation request
+type generationJob struct {
+	prompt string
+	label  string
+	opts   flux.GenerateOptions
+	group  *jobGroup
+	status jobStatus
+
+	pending  *flux.Pending      // Prediction started in an earlier session to resume
+	started  time.Time          // When the job started running
+	progress float64            // Fraction done as reported by the backend, negative when unknown
+	cancel   context.CancelFunc // Stops the job while it runs
+	err      error              // Why the job failed, shown in its row
+
+	row         *gtk.ListBoxRow
+	statusLabel *gtk.Label
+	removeBtn   *gtk.Button
+}
+
+// createQueuePanel creates the collapsible list of queued generations
+func (a *App) createQueuePanel() *gtk.Expander {
+	a.queueExpander = gtk.NewExpander(tr("Queue"))
+
+	panelBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	panelBox.SetMarginTop(8)
+
+	a.queueList = gtk.NewListBox()
+	a.queueList.SetSelectionMode(gtk.SelectionNone)
+
+	// Clear finished jobs from the list
+	clearBtn := gtk.NewButtonWithLabel(tr("Clear Finished"))
+	clearBtn.ConnectClicked(a.clearFinishedJobs)
+
+	// Drop everything that has not started yet
+	clearQueueBtn := gtk.NewButtonWithLabel(tr("Clear Queue"))
+	clearQueueBtn.ConnectClicked(a.clearQueue)
+
+	// Also cancel the generation that is running
+	stopAllBtn := gtk.NewButtonWithLabel(tr("Stop All"))
+	stopAllBtn.SetTooltipText(tr("Cancel the running generation and remove every queued one, keeping the results shown"))
+	stopAllBtn.SetActionName("win.stop-all")
+
+	buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	buttonBox.SetHAlign(gtk.AlignStart)
+	buttonBox.Append(clearBtn)
+	buttonBox.Append(clearQueueBtn)
+	buttonBox.Append(stopAllBtn)
+
+	panelBox.Append(a.queueList)
+	panelBox.Append(buttonBox)
+	a.queueExpander.SetChild(panelBox)
+
+	a.updateQueueTitle()
+
+	return a.queueExpander
+}
+
+// enqueueGeneration queues one job per run and starts processing the queue.
+// A single generation is queued as one run. It returns the group of the jobs.
+func (a *App) enqueueGeneration(prompt string, runs []sweepRun, appendMode bool) *jobGroup {
+	group := &jobGroup{appendMode: appendMode, total: len(runs)}
+
+	for _, run := range runs {
+		if run.prompt != "" {
+			prompt = run.prompt
+		}
+		job := &generationJob{
+			prompt: prompt,
+			label:  run.label,
+			opts:   run.opts,
+			group:  group,
+			status: jobQueued,
+		}
+		a.queue = append(a.queue, job)
+		a.addJobRow(job)
+	}
+
+	a.updateQueueTitle()
+	a.processQueue()
+	a.saveQueue()
+	return group
+}
+
+// addJobRow adds a row for the job to the queue panel
+func (a *App) addJobRow(job *generationJob) {
+	rowBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	rowBox.SetMarginTop(4)
+	rowBox.SetMarginBottom(4)
+	rowBox.SetMarginStart(4)
+	rowBox.SetMarginEnd(4)
+
+	job.statusLabel = gtk.NewLabel("")
+	job.statusLabel.SetWidthChars(8)
+	job.statusLabel.SetXAlign(0)
+
+	text := job.prompt
+	if job.label != "" && job.label != job.prompt {
+		text = fmt.Sprintf("%s — %s", job.label, job.prompt)
+	}
+	promptLabel := gtk.NewLabel(text)
+	promptLabel.SetXAlign(0)
+	promptLabel.SetHExpand(true)
+	promptLabel.SetEllipsize(pango.EllipsizeEnd)
+	promptLabel.SetTooltipText(text)
+
+	// Queued jobs can be removed before they run
+	job.removeBtn = gtk.NewButtonWithLabel(tr("Remove"))
+	job.removeBtn.ConnectClicked(func() {
+		a.removeJob(job)
+	})
+
+	rowBox.Append(job.statusLabel)
+	rowBox.Append(promptLabel)
+	rowBox.Append(job.removeBtn)
+
+	job.row = gtk.NewListBoxRow()
+	job.row.SetChild(rowBox)
+	a.queueList.Append(job.row)
+
+	a.updateJobRow(job)
+}
+
+// updateJobRow refreshes the row to reflect the job's status
+func (a *App) updateJobRow(job *generationJob) {
+	if job.status == jobRunning && job.progress >= 0 {
+		job.statusLabel.SetText(fmt.Sprintf("%s %d%%", job.status, int(job.progress*100)))
+	} else {
+		job.statusLabel.SetText(job.status.String())
+	}
+	if job.status == jobFailed && job.err != nil {
+		job.statusLabel.SetTooltipText(generationErrorMessage(job.err))
+	}
+	job.removeBtn.SetSensitive(job.status == jobQueued)
+}
+
+// updateQueueTitle shows the number of pending jobs in the panel title
+func (a *App) updateQueueTitle() {
+	pending := 0
+	for _, job := range a.queue {
+		if job.status == jobQueued || job.status == jobRunning {
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		a.queueExpander.SetLabel(tr("Queue"))
+	} else {
+		a.queueExpander.SetLabel(fmt.Sprintf(tr("Queue (%d pending)"), pending))
+	}
+	a.updateTray()
+}
+
+// removeJob removes a job that has not started yet
+func (a *App) removeJob(job *generationJob) {
+	if job.status != jobQueued {
+		return
+	}
+
+	for i, j := range a.queue {
+		if j == job {
+			a.queue = append(a.queue[:i], a.queue[i+1:]...)
+			break
+		}
+	}
+	a.queueList.Remove(job.row)
+	a.saveQueue()
+
+	job.group.total--
+	a.updateQueueTitle()
+	a.finishGroupIfDone(job.group)
+}
+
+// clearFinishedJobs removes completed and failed jobs from the list
+func (a *App) clearFinishedJobs() {
+	remaining := a.queue[:0]
+	for _, job := range a.queue {
+		if job.status == jobDone || job.status == jobFailed || job.status == jobCanceled {
+			a.queueList.Remove(job.row)
+			continue
+		}
+		remaining = append(remaining, job)
+	}
+	a.queue = remaining
+}
+
+// processQueue starts queued jobs in order while fewer than the configured
+// number are running
+func (a *App) processQueue() {
+	for a.queueRunning < a.config.GetMaxConcurrent() {
+		var job *generationJob
+		for _, j := range a.queue {
+			if j.status == jobQueued {
+				job = j
+				break
+			}
+		}
+		if job == nil {
+			if a.queueRunning == 0 {
+				a.spinner.Stop()
+			}
+			a.updateProgress()
+			return
+		}
+		a.startJob(job)
+	}
+}
+
+// startJob sends the job's request in the background
+func (a *App) startJob(job *generationJob) {
+	a.queueRunning++
+	job.status = jobRunning
+	job.started = time.Now()
+	job.progress = -1
+	a.updateJobRow(job)
+	a.spinner.Start()
+	a.updateProgress()
+	a.saveQueue()
+
+	if job.group.total > 1 {
+		a.setStatus(fmt.Sprintf(tr("Generating %s (%d/%d)..."), job.label, job.group.finished+1, job.group.total))
+	} else {
+		a.setStatus(tr("Generating images..."))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = flux.WithProgress(ctx, func(fraction float64) {
+		glib.IdleAdd(func() {
+			a.setJobProgress(job, fraction)
+		})
+	})
+	job.cancel = cancel
+	go func() {
+		defer cancel()
+		result, err := a.runJob(ctx, job)
+		glib.IdleAdd(func() {
+			a.finishJob(job, result, err)
+		})
+	}()
+}
+
+// runJob sends the job's request. A panic is returned as an error so the
+// job still finishes and the spinner stops.
+func (a *App) runJob(ctx context.Context, job *generationJob) (result *flux.GenerateResult, err error) {
+	defer recoverAsError(&err)
+
+	if job.pending != nil {
+		return a.client.Resume(ctx, *job.pending)
+	}
+	return a.client.Generate(ctx, job.prompt, job.opts)
+}
+
+// generateOutsideQueue sends a generation that is not shown in the queue,
+// such as one verifying or finalizing a saved image, and counts it in the
+// statistics and session cost like queued ones. Call it off the main thread.
+func (a *App) generateOutsideQueue(ctx context.Context, prompt string, opts flux.GenerateOptions) (*flux.GenerateResult, error) {
+	job := &generationJob{prompt: prompt, opts: opts, started: time.Now()}
+	result, err := a.runJob(ctx, job)
+	glib.IdleAdd(func() {
+		if last := a.client.LastResponse(); last != nil {
+			a.lastResponse = last
+		}
+		switch {
+		case errors.Is(err, context.Canceled):
+		case err != nil:
+			a.recordGeneration(job, 0, err)
+		default:
+			urls := result.URLs
+			if a.config.GetDedupeResults() {
+				urls, _ = dedupeURLs(urls)
+			}
+			a.recordCost(len(urls))
+			a.recordGeneration(job, len(urls), nil)
+		}
+	})
+	return result, err
+}
+
+// recoverAsError turns a panic in the calling function into an error stored
+// in *err. Use it deferred in background work whose result the UI waits for.
+func recoverAsError(err *error) {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "Recovered from panic: %v\n%s", r, debug.Stack())
+		*err = fmt.Errorf("unexpected failure: %v", r)
+	}
+}
+
+// finishJob records the result of a job, displays its images and moves on
+func (a *App) finishJob(job *generationJob, result *flux.GenerateResult, err error) {
+	a.queueRunning--
+	job.cancel = nil
+	group := job.group
+	if last := a.client.LastResponse(); last != nil {
+		a.lastResponse = last
+	}
+	group.finished++
+
+	if errors.Is(err, context.Canceled) {
+		// Stopped on purpose, so neither a failure nor worth counting
+		job.status = jobCanceled
+	} else if err != nil {
+		// Keep the previous results on failure
+		job.status = jobFailed
+		job.err = err
+		group.failed++
+		group.lastErr = err
+		fmt.Fprintf(os.Stderr, "Generation %q failed: %v\n", job.prompt, err)
+		a.recordGeneration(job, 0, err)
+
+		// Let the user fix a prompt the safety filter rejected
+		if errors.Is(err, flux.ErrSafetyRejected) {
+			a.showSafetyRetry(job.prompt, err)
+		}
+
+		// Never offer more images than the backend accepts again
+		if limit, ok := flux.OutputLimit(err); ok {
+			a.limitNumOutputs(limit)
+		}
+	} else {
+		job.status = jobDone
+
+		// Backends occasionally repeat a URL in the output. The repeats are
+		// not counted as images, nor in the cost.
+		urls := result.URLs
+		if a.config.GetDedupeResults() {
+			var removed int
+			urls, removed = dedupeURLs(urls)
+			group.duplicates += removed
+		}
+		group.images += len(urls)
+		a.recordCost(len(urls))
+		a.recordGeneration(job, len(urls), nil)
+
+		// Tell the webhook, if any, without holding up the queue
+		completion := flux.NewCompletion(job.prompt, job.opts, result)
+		go func() {
+			if err := a.client.NotifyWebhook(context.Background(), completion); err != nil {
+				fmt.Fprintf(os.Stderr, "Error notifying webhook: %v\n", err)
+			}
+		}()
+
+		// Replace the old results only once the first job succeeds
+		if !group.appendMode && !group.cleared {
+			a.clearImages()
+		}
+		group.cleared = true
+
+		label := job.label
+		if group.total > 1 && label != "" {
+			label = fmt.Sprintf("%s — %s", job.label, job.prompt)
+		}
+
+		// Record the effective seed and format so the images can be
+		// reproduced
+		opts := job.opts
+		seed := result.Seed
+		opts.Seed = &seed
+		if result.FormatFallback != "" {
+			opts.OutputFormat = "png"
+			group.formatFallback = result.FormatFallback
+		}
+		group.emptyRetries += result.EmptyRetries
+
+		batch := &generationBatch{
+			prompt: job.prompt,
+			label:  label,
+			opts:   opts,
+			urls:   urls,
+			seeds:  imageSeeds(urls, result),
+
+			flagged: imageFlags(urls, result),
+		}
+		a.displayImages(batch)
+
+		a.lastGeneration = batch
+		a.duplicateAction.SetEnabled(true)
+
+		// A failed generation leaves the prompt to be fixed and retried
+		if group.typedPrompt != "" {
+			a.applyPromptAfterGenerate(group.typedPrompt)
+			group.typedPrompt = ""
+		}
+	}
+
+	a.updateJobRow(job)
+	a.updateQueueTitle()
+	a.finishGroupIfDone(group)
+	a.processQueue()
+	a.saveQueue()
+}
+
+// finishGroupIfDone reports the outcome once every job in a group has finished
+func (a *App) finishGroupIfDone(group *jobGroup) {
+	if group.finished < group.total || group.finished == 0 || group.stopped {
+		return
+	}
+
+	notes := a.costSummary()
+	if group.duplicates > 0 {
+		notes += fmt.Sprintf(tr(" (%d duplicate results hidden)"), group.duplicates)
+	}
+	if group.formatFallback != "" {
+		notes += fmt.Sprintf(tr(" (%s not supported by this backend, used PNG instead)"), formatName(group.formatFallback))
+	}
+	if group.emptyRetries > 0 {
+		notes += fmt.Sprintf(tr(" (retried %d times after empty results)"), group.emptyRetries)
+	}
+
+	// Failed runs and images in another format than asked for are easy to
+	// miss in the status bar, so they are raised in the banner too
+	warn := group.formatFallback != ""
+	switch {
+	case group.total == 1 && group.failed == 1:
+		a.setStatus(tr("Error: ") + generationErrorMessage(group.lastErr))
+		return
+	case group.total == 1:
+		a.setStatus(fmt.Sprintf(tr("Generated %d images%s"), group.images, notes))
+	case group.failed > 0:
+		// Each failed run keeps its error in the tooltip of its queue row
+		a.setStatus(fmt.Sprintf(tr("Sweep finished: %d of %d runs failed%s"), group.failed, group.total, notes))
+		warn = true
+	default:
+		a.setStatus(fmt.Sprintf(tr("Sweep finished: %d runs%s"), group.total, notes))
+	}
+	if warn {
+		a.showBanner(gtk.MessageWarning, a.statusBar.Text())
+	}
+}
+
+// generationErrorMessage explains a failed generation, with a hint at the
+// likely cause for the kinds of failure the client distinguishes
+func generationErrorMessage(err error) string {
+	var statusErr *flux.APIStatusError
+	switch {
+	case errors.Is(err, flux.ErrSafetyRejected):
+		return err.Error()
+	case isOutputLimit(err):
+		limit, _ := flux.OutputLimit(err)
+		return fmt.Sprintf(tr("The backend allows at most %d images per request, the Images slider now stops there (%v)"), limit, err)
+	case errors.As(err, &statusErr) && (statusErr.Code == 401 || statusErr.Code == 403):
+		return fmt.Sprintf(tr("The backend refused the request, check FLUX_API_HEADERS (%v)"), err)
+	case errors.As(err, &statusErr) && statusErr.Code == 429:
+		return fmt.Sprintf(tr("The backend is rate limiting requests, try again shortly (%v)"), err)
+	case errors.As(err, &statusErr) && statusErr.Code >= 500:
+		return fmt.Sprintf(tr("The backend failed, try again later (%v)"), err)
+	case errors.Is(err, flux.ErrAuthRequired):
+		return fmt.Sprintf(tr("The backend wants you to log in, set the Authorization header in FLUX_API_HEADERS (%v)"), err)
+	case errors.Is(err, flux.ErrNetwork):
+		return fmt.Sprintf(tr("Could not reach the backend, check FLUX_API_URL and your connection (%v)"), err)
+	case errors.Is(err, flux.ErrDecode):
+		return fmt.Sprintf(tr("The backend sent a response that could not be read (%v)"), err)
+	case errors.Is(err, flux.ErrEmptyResult):
+		if retries := flux.EmptyRetries(err); retries > 0 {
+			return fmt.Sprintf(tr("The backend returned no images, also after %d retries"), retries)
+		}
+		return tr("The backend returned no images")
+	default:
+		return err.Error()
+	}
+}
+
+// isOutputLimit reports whether err is the backend rejecting the number of
+// images requested
+func isOutputLimit(err error) bool {
+	_, ok := flux.OutputLimit(err)
+	return ok
+}
+
+// duplicateLastGeneration queues the last successful generation again with
+// exactly the same prompt, seed and options
+func (a *App) duplicateLastGeneration() {
+	last := a.lastGeneration
+	if last == nil {
+		a.setStatus(tr("Nothing to duplicate yet"))
+		return
+	}
+
+	a.rerunGeneration(last.prompt, last.opts)
+}
+
+// rerunGeneration queues a single generation with the given prompt and options,
+// honoring the Append toggle
+func (a *App) rerunGeneration(prompt string, opts flux.GenerateOptions) {
+	appendMode := a.appendToggle != nil && a.appendToggle.Active()
+	label := ""
+	if appendMode {
+		label = prompt
+	}
+
+	a.enqueueGeneration(prompt, []sweepRun{{label: label, opts: opts}}, appendMode)
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecoverAsError(t *testing.T) {
+	run := func() (err error) {
+		defer recoverAsError(&err)
+		panic("boom")
+	}
+
+	err := run()
+	if err == nil {
+		t.Fatal("got no error after a panic")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got error %q, want it to mention the panic value", err)
+	}
+}
+
+func TestRecoverAsErrorWithoutPanic(t *testing.T) {
+	run := func() (err error) {
+		defer recoverAsError(&err)
+		return nil
+	}
+
+	if err := run(); err != nil {
+		t.Errorf("got error %v without a panic", err)
+	}
+}
@@ -0,0 +1,17 @@
+package app
+
+import (
+	"fmt"
+)
+
+// showLastResponse shows the raw response to the last generation, for
+// finding out why a backend's answer was read the way it was. Secrets are
+// redacted and embedded images shortened.
+func (a *App) showLastResponse() {
+	if a.lastResponse == nil {
+		a.setStatus(tr("No response received yet"))
+		return
+	}
+	title := fmt.Sprintf(tr("Last Response (%s)"), a.lastResponse.ReceivedAt.Format("15:04:05"))
+	a.showTextWindow(title, a.lastResponse.Format(), tr("Response copied to clipboard"))
+}
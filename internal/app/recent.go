@@ -0,0 +1,62 @@
+package app
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Recent outputs strip sizing
+const (
+	recentLimit     = 30 // Thumbnails kept before the oldest is dropped
+	recentThumbSize = 64
+)
+
+// createRecentStrip creates the strip of thumbnails of the latest results.
+// Unlike the results view it is not cleared by new generations. It stays
+// hidden until the first image arrives.
+func (a *App) createRecentStrip() *gtk.ScrolledWindow {
+	a.recentBox = gtk.NewBox(gtk.OrientationHorizontal, 4)
+
+	a.recentStrip = gtk.NewScrolledWindow()
+	a.recentStrip.SetPolicy(gtk.PolicyAutomatic, gtk.PolicyNever)
+	a.recentStrip.SetChild(a.recentBox)
+	a.recentStrip.SetVisible(false)
+
+	return a.recentStrip
+}
+
+// addRecent puts a result at the front of the recent strip
+func (a *App) addRecent(img *resultImage) {
+	texture := img.thumbnail
+	if texture == nil {
+		texture = img.displayTexture()
+	}
+	if img.cover != nil {
+		texture = img.cover
+	}
+
+	picture := gtk.NewPicture()
+	picture.SetPaintable(texture)
+	picture.SetCanShrink(true)
+	picture.SetContentFit(gtk.ContentFitCover)
+	picture.SetSizeRequest(recentThumbSize, recentThumbSize)
+	picture.SetTooltipText(tr("Click to view full size"))
+
+	click := gtk.NewGestureClick()
+	click.ConnectReleased(func(nPress int, x, y float64) {
+		a.withResultTexture(img, tr("viewing"), func(texture *gdk.Texture) {
+			a.showImageViewer(defaultImageName(img.url), texture)
+		})
+	})
+	picture.AddController(click)
+
+	a.recentBox.Prepend(picture)
+	a.recentCount++
+	if a.recentCount > recentLimit {
+		a.recentBox.Remove(a.recentBox.LastChild())
+		a.recentCount--
+	}
+
+	a.recentStrip.SetVisible(true)
+	a.recentStrip.HAdjustment().SetValue(0)
+}
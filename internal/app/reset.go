@@ -0,0 +1,41 @@
+package app
+
+// resetControls puts the prompt and every generation control back to its
+// configured default. Display preferences such as images per row are kept.
+func (a *App) resetControls() {
+	a.setPromptText("")
+
+	if aspectRatioCombo != nil {
+		for i, ratio := range a.config.GetSupportedAspectRatios() {
+			if ratio == a.config.GetDefaultAspectRatio() {
+				aspectRatioCombo.SetSelected(uint(i))
+				break
+			}
+		}
+	}
+
+	if numOutputsScale != nil {
+		numOutputsScale.SetValue(float64(a.config.GetDefaultNumOutputs()))
+	}
+
+	a.appendToggle.SetActive(false)
+	a.numbersToggle.SetActive(false)
+
+	a.sweepCombo.SetSelected(0)
+	a.sweepEntry.SetText("")
+
+	// Zero leaves guidance and steps to the backend
+	if a.guidanceSpin != nil && a.stepsSpin != nil {
+		a.guidanceSpin.SetValue(0)
+		a.stepsSpin.SetValue(0)
+	}
+
+	a.presetFormat, a.presetQuality, a.presetModel = "", 0, ""
+
+	a.clearBaseImage()
+	a.clearKept()
+	a.compareFirst = nil
+
+	a.setStatus(tr("Controls reset to their defaults"))
+	a.focusPrompt()
+}
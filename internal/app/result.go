@@ -0,0 +1,314 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"sync"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+)
+
+// generationBatch is the set of images returned by one generation
+type generationBatch struct {
+	prompt string
+	label  string               // Label shown above the batch, if any
+	opts   flux.GenerateOptions // Options used, including the effective seed
+	urls   []string
+	seeds  []int    // Seed of each image when the backend reports them, else nil
+	tags   *tagList // Shared with the batches of each image once shown
+
+	flagged []bool // Whether each image was flagged as NSFW, nil when none was
+}
+
+// forImage returns the batch as it applies to image i, with the seed of
+// that image when the backend reported one per image
+func (b *generationBatch) forImage(i int) *generationBatch {
+	if i >= len(b.seeds) {
+		return b
+	}
+	image := *b
+	seed := b.seeds[i]
+	image.opts.Seed = &seed
+	return &image
+}
+
+// imageSeeds returns the seeds of urls, a subset of the result's images,
+// or nil when the backend did not report a seed per image
+func imageSeeds(urls []string, result *flux.GenerateResult) []int {
+	if len(result.Seeds) != len(result.URLs) {
+		return nil
+	}
+	byURL := make(map[string]int, len(result.URLs))
+	for i, url := range result.URLs {
+		if _, ok := byURL[url]; !ok {
+			byURL[url] = result.Seeds[i]
+		}
+	}
+	seeds := make([]int, len(urls))
+	for i, url := range urls {
+		seeds[i] = byURL[url]
+	}
+	return seeds
+}
+
+// isFlagged reports whether image i was flagged as NSFW by the backend
+func (b *generationBatch) isFlagged(i int) bool {
+	return i < len(b.flagged) && b.flagged[i]
+}
+
+// dedupeURLs returns urls without repeated entries, keeping the first of
+// each in order, and the number of duplicates removed
+func dedupeURLs(urls []string) ([]string, int) {
+	seen := make(map[string]bool, len(urls))
+	unique := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		unique = append(unique, url)
+	}
+	return unique, len(urls) - len(unique)
+}
+
+// minResultSize is the smallest size results are shown at
+const minResultSize = 320
+
+// resultImage is a generated image shown in the results area
+type resultImage struct {
+	mu sync.Mutex // Guards the transform state, which changes off the main thread
+
+	batch  *generationBatch // Generation the image belongs to
+	url    string
+	data   []byte      // Original downloaded bytes, kept for saving and resets
+	format imageFormat // Format of the original bytes
+	size   int         // Size of the original bytes
+
+	// Downloads the original bytes again when they are not kept in memory
+	fetch func() ([]byte, error)
+
+	// Orientation changes applied on top of the original, with version
+	// counting changes so an update started from older transforms is redone
+	transforms      []imageTransform
+	transformedData []byte
+	transformedFmt  imageFormat
+	version         int
+
+	// Shapes drawn over the image, only added to it when saving
+	annotations []annotation
+
+	motion         bool         // An animation or video, played instead of shown as a picture
+	texture        *gdk.Texture // Texture currently displayed, possibly scaled down, nil while released
+	thumbnail      *gdk.Texture // Small texture for the recent strip, nil to share texture
+	cover          *gdk.Texture // Blurred texture hiding a flagged image, nil when not hidden
+	maxDisplaySize int          // Largest displayed dimension, 0 for full size
+	restoring      bool         // Whether the released texture is being loaded again, used on the main thread
+}
+
+// newResultImage creates a result image and its texture from downloaded bytes
+func newResultImage(url string, data []byte, contentType string, maxDisplaySize int) (*resultImage, error) {
+	// Animations show their first frame where a still picture is needed,
+	// and videos a placeholder unless one of the loaders reads them
+	motion := isMotion(data)
+	texture, err := newDisplayTexture(data, maxDisplaySize)
+	if err != nil && motion {
+		texture, err = motionPlaceholder(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resultImage{
+		url:            url,
+		data:           data,
+		format:         detectImageFormat(data, contentType, url),
+		size:           len(data),
+		motion:         motion,
+		texture:        texture,
+		maxDisplaySize: maxDisplaySize,
+	}, nil
+}
+
+// original returns the original bytes, downloading them again if they are
+// not kept. Call it off the main thread.
+func (img *resultImage) original() ([]byte, error) {
+	if img.data != nil || img.fetch == nil {
+		return img.data, nil
+	}
+	return img.fetch()
+}
+
+// newDisplayTexture creates the texture shown for image data. Images larger
+// than maxSize are scaled down to save texture memory; a maxSize of 0 keeps
+// the full resolution.
+func newDisplayTexture(data []byte, maxSize int) (*gdk.Texture, error) {
+	if maxSize > 0 {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err == nil && (cfg.Width > maxSize || cfg.Height > maxSize) {
+			if src, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+				scaled := toRGBA(scaleToFit(src, maxSize))
+				// Raw pixels carry no color profile, so a profiled image is
+				// passed to gdk as PNG to be color managed where supported
+				if profile := extractICCProfile(data); profile != nil {
+					if encoded, _, err := encodeImage(scaled, imageFormats[0], profile); err == nil {
+						return newTexture(encoded)
+					}
+				}
+				return memoryTexture(scaled), nil
+			}
+		}
+	}
+
+	return newTexture(data)
+}
+
+// fullTexture returns a full resolution texture of the image as it would be
+// saved, for uses such as copying to the clipboard. It may download the
+// image, so call it off the main thread.
+func (img *resultImage) fullTexture() (*gdk.Texture, error) {
+	data, _ := img.saveData()
+	if data == nil {
+		var err error
+		if data, err = img.original(); err != nil {
+			return nil, err
+		}
+	}
+	return newTexture(data)
+}
+
+// saveData returns the bytes and format to write when saving the image,
+// including any transforms. The bytes are nil if they are not kept.
+func (img *resultImage) saveData() ([]byte, imageFormat) {
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	if !cancelsOut(img.transforms) {
+		return img.transformedData, img.transformedFmt
+	}
+	return img.data, img.format
+}
+
+// transform appends t to the image's transforms and updates its texture.
+// It decodes and re-encodes the image, so call it off the main thread.
+func (img *resultImage) transform(t imageTransform) (*gdk.Texture, error) {
+	return img.updateTransforms(func(transforms []imageTransform) []imageTransform {
+		return append(append([]imageTransform(nil), transforms...), t)
+	}, func(annotations []annotation, _ []imageTransform) []annotation {
+		return transformAnnotations(annotations, t)
+	})
+}
+
+// reset drops all transforms and restores the original image
+func (img *resultImage) reset() (*gdk.Texture, error) {
+	return img.updateTransforms(func([]imageTransform) []imageTransform {
+		return nil
+	}, untransformAnnotations)
+}
+
+// restoreTexture creates the display texture again after it was released.
+// It may download the image, so call it off the main thread.
+func (img *resultImage) restoreTexture() (*gdk.Texture, error) {
+	return img.updateTransforms(func(transforms []imageTransform) []imageTransform {
+		return transforms
+	}, nil)
+}
+
+// releaseTexture drops the display texture to free its memory until it is
+// restored
+func (img *resultImage) releaseTexture() {
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	img.texture = nil
+}
+
+// displayTexture returns the texture currently displayed, nil while it is
+// released
+func (img *resultImage) displayTexture() *gdk.Texture {
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	return img.texture
+}
+
+// updateTransforms replaces the transforms with those next returns for the
+// current ones and updates the texture, moving the annotations with annotate
+// if given. The image is downloaded and re-encoded without holding img.mu;
+// when the transforms changed meanwhile it starts over from the new ones.
+func (img *resultImage) updateTransforms(next func([]imageTransform) []imageTransform, annotate func([]annotation, []imageTransform) []annotation) (*gdk.Texture, error) {
+	for {
+		img.mu.Lock()
+		version, current := img.version, img.transforms
+		img.mu.Unlock()
+
+		transforms := next(current)
+		data, format, texture, err := img.render(transforms)
+		if err != nil {
+			return nil, err
+		}
+
+		img.mu.Lock()
+		if img.version != version {
+			img.mu.Unlock()
+			continue
+		}
+		img.version++
+		img.transforms = transforms
+		img.transformedData, img.transformedFmt = nil, format
+		if !cancelsOut(transforms) {
+			img.transformedData = data
+		}
+		img.texture = texture
+		if annotate != nil {
+			img.annotations = annotate(img.annotations, current)
+		}
+		img.mu.Unlock()
+		return texture, nil
+	}
+}
+
+// render applies transforms to the original bytes and creates the display
+// texture of the result
+func (img *resultImage) render(transforms []imageTransform) ([]byte, imageFormat, *gdk.Texture, error) {
+	original, err := img.original()
+	if err != nil {
+		return nil, imageFormat{}, nil, err
+	}
+
+	// Transforms that cancel out keep the original bytes, so a JPEG is not
+	// encoded again for nothing
+	data, format := original, img.format
+	if !cancelsOut(transforms) {
+		data, format, err = applyTransforms(original, img.format, transforms)
+		if err != nil {
+			return nil, imageFormat{}, nil, err
+		}
+	}
+
+	texture, err := newDisplayTexture(data, img.maxDisplaySize)
+	if err != nil {
+		return nil, imageFormat{}, nil, err
+	}
+	return data, format, texture, nil
+}
+
+// loadImageTexture downloads an image and creates its display texture
+func (a *App) loadImageTexture(url string) (img *resultImage, err error) {
+	// The spinner shown while loading is only replaced once this returns
+	defer recoverAsError(&err)
+
+	data, contentType, err := a.client.Download(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Catch error pages before handing the bytes to the texture loader
+	if err := checkImageData(data); err != nil {
+		return nil, err
+	}
+
+	if !a.settings.LowMemory {
+		return newResultImage(url, data, contentType, a.config.GetMaxDisplaySize())
+	}
+	return a.newLowMemoryResultImage(url, data, contentType)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// trackResultFocus makes img the focused result whenever the keyboard focus
+// moves into box, which holds the image and its buttons, or the image is
+// clicked
+func (a *App) trackResultFocus(img *resultImage, box *gtk.Box, picture *gtk.Picture) {
+	focus := gtk.NewEventControllerFocus()
+	focus.ConnectEnter(func() {
+		a.focusedResult = img
+	})
+	box.AddController(focus)
+
+	// Pictures only take the focus when clicked, keeping Tab on the buttons
+	picture.SetFocusable(true)
+	picture.SetFocusOnClick(true)
+	click := gtk.NewGestureClick()
+	click.ConnectPressed(func(nPress int, x, y float64) {
+		picture.GrabFocus()
+		a.focusedResult = img
+	})
+	picture.AddController(click)
+}
+
+// saveFocusedResult opens the save dialog for the focused result, or the
+// first to load when none has been focused
+func (a *App) saveFocusedResult() {
+	img := a.focusedResult
+	if img == nil {
+		if len(a.results) == 0 {
+			a.setStatus(tr("There are no results to save"))
+			return
+		}
+		img = a.results[0]
+	}
+	a.saveImage(img)
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// File manager D-Bus interface, implemented by most Linux file managers
+const (
+	fileManagerBusName   = "org.freedesktop.FileManager1"
+	fileManagerPath      = "/org/freedesktop/FileManager1"
+	fileManagerInterface = "org.freedesktop.FileManager1"
+	fileManagerTimeoutMs = 5000
+)
+
+// setSavedStatus reports a saved file and offers to show it in the file
+// manager
+func (a *App) setSavedStatus(message, path string) {
+	a.setStatus(message)
+	a.savedPath = path
+	a.revealBtn.SetVisible(true)
+}
+
+// revealSavedFile shows the last saved file in the file manager
+func (a *App) revealSavedFile() {
+	if a.savedPath != "" {
+		a.revealInFileManager(a.savedPath)
+	}
+}
+
+// revealInFileManager opens the file manager with path selected. Without a
+// file manager on D-Bus, the containing folder is opened instead.
+func (a *App) revealInFileManager(path string) {
+	uri := gio.NewFileForPath(path).URI()
+
+	conn, err := gio.BusGetSync(context.Background(), gio.BusTypeSession)
+	if err != nil {
+		a.openFolder(path)
+		return
+	}
+
+	// ShowItems(as uris, s startup_id)
+	params := glib.NewVariantTuple([]*glib.Variant{
+		glib.NewVariantArray(glib.NewVariantType("s"), []*glib.Variant{glib.NewVariantString(uri)}),
+		glib.NewVariantString(""),
+	})
+	conn.Call(context.Background(), fileManagerBusName, fileManagerPath, fileManagerInterface,
+		"ShowItems", params, nil, gio.DBusCallFlagsNone, fileManagerTimeoutMs,
+		func(res gio.AsyncResulter) {
+			if _, err := conn.CallFinish(res); err != nil {
+				a.openFolder(path)
+			}
+		})
+}
+
+// openFolder opens the folder containing path with the default application
+func (a *App) openFolder(path string) {
+	a.openDirectory(filepath.Dir(path))
+}
+
+// openConfigFolder opens the folder holding the settings, presets, word
+// banks and other files the app saves, for editing them by hand
+func (a *App) openConfigFolder() {
+	a.openAppDirectory(a.config.GetConfigDir())
+}
+
+// openCacheFolder opens the folder holding cached data such as gallery
+// thumbnails
+func (a *App) openCacheFolder() {
+	a.openAppDirectory(a.config.GetCacheDir())
+}
+
+// openAppDirectory opens one of the app's own directories, creating it
+// first since nothing may have been saved there yet
+func (a *App) openAppDirectory(dir string) {
+	if dir == "" {
+		a.setStatus(tr("Error: No user directory is available for this folder"))
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error creating folder %s: %v"), dir, err))
+		return
+	}
+	a.openDirectory(dir)
+}
+
+// openDirectory opens dir with the default application
+func (a *App) openDirectory(dir string) {
+	gtk.ShowURIFull(context.Background(), &a.win.Window, gio.NewFileForPath(dir).URI(), 0,
+		func(res gio.AsyncResulter) {
+			if err := gtk.ShowURIFullFinish(&a.win.Window, res); err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error opening folder %s: %v"), dir, err))
+			}
+		})
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// createSafetyBar creates the bar offering to edit and retry a prompt that
+// was rejected by the safety filter. It stays hidden until needed.
+func (a *App) createSafetyBar() *gtk.Revealer {
+	barBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	barBox.SetMarginTop(4)
+	barBox.SetMarginBottom(4)
+
+	a.safetyLabel = gtk.NewLabel("")
+	a.safetyLabel.SetXAlign(0)
+	a.safetyLabel.SetWrap(true)
+	a.safetyLabel.SetHExpand(true)
+
+	retryBtn := gtk.NewButtonWithLabel(tr("Retry"))
+	retryBtn.SetTooltipText(tr("Generate again with the edited prompt"))
+	retryBtn.ConnectClicked(a.onGenerateClicked)
+
+	dismissBtn := gtk.NewButtonWithLabel(tr("Dismiss"))
+	dismissBtn.ConnectClicked(a.hideSafetyRetry)
+
+	barBox.Append(a.safetyLabel)
+	barBox.Append(retryBtn)
+	barBox.Append(dismissBtn)
+
+	a.safetyBar = gtk.NewRevealer()
+	a.safetyBar.SetChild(barBox)
+	a.safetyBar.SetRevealChild(false)
+
+	return a.safetyBar
+}
+
+// showSafetyRetry puts a rejected prompt back in the entry for editing and
+// offers to retry it
+func (a *App) showSafetyRetry(prompt string, err error) {
+	a.safetyLabel.SetText(fmt.Sprintf(tr("Error: %v. Edit the prompt and press Retry."), err))
+	a.safetyBar.SetRevealChild(true)
+
+	a.setPromptText(prompt)
+	a.focusPromptEnd()
+}
+
+// hideSafetyRetry hides the safety retry bar
+func (a *App) hideSafetyRetry() {
+	a.safetyBar.SetRevealChild(false)
+}
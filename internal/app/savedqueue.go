@@ -0,0 +1,253 @@
+package app
+
+import (
+	"fmt"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// saveQueue writes the generations that have not finished to the queue file,
+// so a restart does not lose them. Jobs resuming a prediction are left out
+// because the pending predictions are saved separately.
+func (a *App) saveQueue() {
+	dir := a.config.GetConfigDir()
+	if dir == "" {
+		return
+	}
+
+	// A queue left from the last session that the user has not answered
+	// about yet is kept
+	queue := append([]flux.QueuedGeneration(nil), a.savedQueue...)
+	for _, job := range a.queue {
+		if job.pending != nil || (job.status != jobQueued && job.status != jobRunning) {
+			continue
+		}
+		input := flux.NewInput(job.prompt, job.opts)
+		if job.opts.ImageSource != "" {
+			input.Image = ""
+		}
+		queue = append(queue, flux.QueuedGeneration{
+			Label:       job.label,
+			Input:       input,
+			ImageSource: job.opts.ImageSource,
+			Interrupted: job.status == jobRunning,
+		})
+	}
+
+	if err := flux.SaveQueue(dir, queue); err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error saving queue: %v"), err))
+	}
+}
+
+// offerSavedQueue asks whether to resume the generations still queued when
+// the app last closed
+func (a *App) offerSavedQueue() {
+	dir := a.config.GetConfigDir()
+	if a.config.IsOffline() || dir == "" {
+		return
+	}
+
+	queue, err := flux.LoadQueue(dir)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error loading queue: %v"), err))
+		return
+	}
+	queue = withoutResumedPredictions(queue, a.pendingPrompts())
+	if len(queue) == 0 {
+		return
+	}
+	a.savedQueue = queue
+
+	dialog := gtk.NewMessageDialog(
+		&a.win.Window,
+		gtk.DialogModal|gtk.DialogDestroyWithParent,
+		gtk.MessageQuestion,
+		gtk.ButtonsNone,
+	)
+	dialog.SetObjectProperty("text", tr("Resume the queue?"))
+	dialog.SetObjectProperty("secondary-text", fmt.Sprintf(
+		tr("%d generation(s) were still queued when Fluxxxer last closed."), len(queue)))
+	dialog.AddButton(tr("Discard"), int(gtk.ResponseReject))
+	dialog.AddButton(tr("Resume"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+		switch responseId {
+		case int(gtk.ResponseAccept):
+			a.resumeSavedQueue()
+		case int(gtk.ResponseReject):
+			a.savedQueue = nil
+			a.saveQueue()
+		}
+	})
+	dialog.Show()
+}
+
+// resumeSavedQueue queues the generations left from the last session. Base
+// images are loaded again in the background from where they came from, and
+// generations whose base image is gone are left out.
+func (a *App) resumeSavedQueue() {
+	queue := a.savedQueue
+	go func() {
+		opts := make([]flux.GenerateOptions, 0, len(queue))
+		var kept []flux.QueuedGeneration
+		var lastErr error
+		for _, q := range queue {
+			o := q.Input.Options()
+			if q.ImageSource != "" {
+				img, _, err := a.readBaseImage(q.ImageSource)
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				o.Image, o.ImageSource = img.value, q.ImageSource
+			}
+			opts = append(opts, o)
+			kept = append(kept, q)
+		}
+
+		glib.IdleAdd(func() {
+			// The queue may have been cleared while loading
+			if len(a.savedQueue) == 0 {
+				return
+			}
+			a.savedQueue = nil
+
+			group := &jobGroup{appendMode: true, total: len(kept)}
+			for i, q := range kept {
+				job := &generationJob{
+					prompt: q.Input.Prompt,
+					label:  q.Label,
+					opts:   opts[i],
+					group:  group,
+					status: jobQueued,
+				}
+				a.queue = append(a.queue, job)
+				a.addJobRow(job)
+			}
+
+			a.updateQueueTitle()
+			if lastErr != nil {
+				a.setStatus(fmt.Sprintf(tr("Error: %d queued generation(s) left out, their base image could not be loaded: %v"), len(queue)-len(kept), lastErr))
+			} else {
+				a.setStatus(fmt.Sprintf(tr("Resuming %d queued generation(s)"), len(kept)))
+			}
+			a.saveQueue()
+			a.processQueue()
+		})
+	}()
+}
+
+// stopAllConfirmCount is how many queued generations Stop All confirms
+// before clearing
+const stopAllConfirmCount = 5
+
+// clearQueue removes every generation that has not started yet, including
+// a queue left from the last session
+func (a *App) clearQueue() {
+	removed := a.removeQueuedJobs()
+	a.setStatus(fmt.Sprintf(tr("Removed %d queued generation(s)"), removed))
+}
+
+// removeQueuedJobs removes every generation that has not started yet and
+// returns how many were removed
+func (a *App) removeQueuedJobs() int {
+	a.savedQueue = nil
+
+	var removed int
+	for _, job := range append([]*generationJob(nil), a.queue...) {
+		if job.status == jobQueued {
+			a.removeJob(job)
+			removed++
+		}
+	}
+
+	a.saveQueue()
+	return removed
+}
+
+// stopAll cancels the running generation and removes every queued one,
+// keeping the results already shown. A large queue is confirmed first.
+func (a *App) stopAll() {
+	queued := len(a.savedQueue)
+	running := false
+	for _, job := range a.queue {
+		switch job.status {
+		case jobQueued:
+			queued++
+		case jobRunning:
+			running = true
+		}
+	}
+	if queued == 0 && !running {
+		a.setStatus(tr("Nothing to stop"))
+		return
+	}
+	if queued < stopAllConfirmCount {
+		a.stopQueue()
+		return
+	}
+
+	dialog := gtk.NewMessageDialog(
+		&a.win.Window,
+		gtk.DialogModal|gtk.DialogDestroyWithParent,
+		gtk.MessageQuestion,
+		gtk.ButtonsNone,
+	)
+	dialog.SetObjectProperty("text", fmt.Sprintf(tr("Stop all %d queued generations?"), queued))
+	dialog.SetObjectProperty("secondary-text", tr("The running generation is canceled and the queue is cleared. Results already shown are kept."))
+	dialog.AddButton(tr("Keep Generating"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Stop All"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+		if responseId == int(gtk.ResponseAccept) {
+			a.stopQueue()
+		}
+	})
+	dialog.Show()
+}
+
+// stopQueue cancels the running generation and clears the queue
+func (a *App) stopQueue() {
+	canceled := 0
+	for _, job := range a.queue {
+		if job.status == jobRunning && job.cancel != nil {
+			job.group.stopped = true
+			job.cancel()
+			canceled++
+		}
+	}
+	removed := a.removeQueuedJobs()
+
+	if canceled > 0 {
+		a.setStatus(fmt.Sprintf(tr("Stopped the running generation and removed %d queued generation(s)"), removed))
+	} else {
+		a.setStatus(fmt.Sprintf(tr("Removed %d queued generation(s)"), removed))
+	}
+}
+
+// pendingPrompts returns the prompts of the predictions being resumed
+func (a *App) pendingPrompts() map[string]bool {
+	prompts := make(map[string]bool)
+	for _, job := range a.queue {
+		if job.pending != nil {
+			prompts[job.pending.Prompt] = true
+		}
+	}
+	return prompts
+}
+
+// withoutResumedPredictions drops interrupted generations whose prediction
+// is resumed already, so they are not generated twice
+func withoutResumedPredictions(queue []flux.QueuedGeneration, resumed map[string]bool) []flux.QueuedGeneration {
+	kept := queue[:0]
+	for _, q := range queue {
+		if q.Interrupted && resumed[q.Input.Prompt] {
+			continue
+		}
+		kept = append(kept, q)
+	}
+	return kept
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// uiScales are the sizes offered for text and controls, in percent
+var uiScales = []int{100, 125, 150, 175, 200}
+
+// Limits of the UI scale, against values edited into the settings file
+const (
+	minUIScale = 50
+	maxUIScale = 300
+)
+
+// addUIScaleAction adds the radio action choosing the size of text and
+// controls, applied right away and saved with the settings
+func (a *App) addUIScaleAction() {
+	action := gio.NewSimpleActionStateful(
+		"ui-scale",
+		glib.NewVariantType("i"),
+		glib.NewVariantInt32(int32(a.uiScale())),
+	)
+	action.ConnectActivate(func(parameter *glib.Variant) {
+		action.SetState(parameter)
+		a.settings.UIScale = int(parameter.Int32())
+		a.saveSettings()
+		a.applyUIScale()
+	})
+	a.win.AddAction(action)
+}
+
+// createUIScaleMenu creates the radio items choosing the UI scale
+func createUIScaleMenu() *gio.Menu {
+	menu := gio.NewMenu()
+	for _, scale := range uiScales {
+		menu.Append(fmt.Sprintf("%d%%", scale), fmt.Sprintf("win.ui-scale(%d)", scale))
+	}
+	return menu
+}
+
+// uiScale returns the size of text and controls chosen in the settings,
+// in percent
+func (a *App) uiScale() int {
+	if a.settings.UIScale == 0 {
+		return 100
+	}
+	return min(max(a.settings.UIScale, minUIScale), maxUIScale)
+}
+
+// applyUIScale sizes text and controls of every window as chosen in the
+// settings
+func (a *App) applyUIScale() {
+	if a.scaleProvider == nil {
+		display := gdk.DisplayGetDefault()
+		if display == nil {
+			return
+		}
+		a.scaleProvider = gtk.NewCSSProvider()
+		gtk.StyleContextAddProviderForDisplay(display, a.scaleProvider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
+	}
+	a.scaleProvider.LoadFromData(uiScaleCSS(a.uiScale()))
+}
+
+// uiScaleCSS returns the style sheet scaling text and the padding of
+// controls to percent of their usual size. Text is scaled from the
+// system font, so it follows changes to it.
+func uiScaleCSS(percent int) string {
+	if percent == 100 {
+		return ""
+	}
+	px := func(size float64) string {
+		return fmt.Sprintf("%.0fpx", size*float64(percent)/100)
+	}
+
+	var css strings.Builder
+	fmt.Fprintf(&css, "window { font-size: %d%%; }\n", percent)
+	fmt.Fprintf(&css, "button { min-height: %s; min-width: %s; padding: %s %s; }\n", px(24), px(16), px(5), px(10))
+	fmt.Fprintf(&css, "entry, spinbutton, dropdown > button { min-height: %s; }\n", px(34))
+	fmt.Fprintf(&css, "entry { padding-left: %s; padding-right: %s; }\n", px(8), px(8))
+	fmt.Fprintf(&css, "checkbutton, switch { min-height: %s; }\n", px(20))
+	return css.String()
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// setupWheelScrolling makes the mouse wheel scroll the results sideways
+// while batches are laid out side by side, so Shift is not needed. In
+// compact mode the results are a column and the wheel scrolls as usual.
+func (a *App) setupWheelScrolling(scrollWin *gtk.ScrolledWindow) {
+	scroll := gtk.NewEventControllerScroll(gtk.EventControllerScrollVertical)
+
+	// Run before the scrolled window's own handling of the wheel
+	scroll.SetPropagationPhase(gtk.PhaseCapture)
+
+	scroll.ConnectScroll(func(dx, dy float64) bool {
+		if !a.settings.WheelScrollsSideways || a.compact || dy == 0 {
+			return false
+		}
+
+		// Leave the wheel alone when there is nothing to scroll sideways
+		adjustment := scrollWin.HAdjustment()
+		if adjustment.Upper()-adjustment.Lower() <= adjustment.PageSize() {
+			return false
+		}
+
+		// Wheel clicks move by the step GTK uses for scrollbars, touchpads
+		// by the distance swiped
+		delta := dy
+		if scroll.Unit() == gdk.ScrollUnitWheel {
+			delta *= math.Pow(adjustment.PageSize(), 2.0/3.0)
+		}
+		adjustment.SetValue(adjustment.Value() + delta)
+		return true
+	})
+
+	scrollWin.AddController(scroll)
+}
+
+// addWheelScrollAction adds the toggle for scrolling the results sideways
+// with the wheel, saved with the settings
+func (a *App) addWheelScrollAction() {
+	action := gio.NewSimpleActionStateful("wheel-scrolls-sideways", nil, glib.NewVariantBoolean(a.settings.WheelScrollsSideways))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.settings.WheelScrollsSideways = !a.settings.WheelScrollsSideways
+		action.SetState(glib.NewVariantBoolean(a.settings.WheelScrollsSideways))
+		a.saveSettings()
+
+		if a.settings.WheelScrollsSideways {
+			a.setStatus(tr("The mouse wheel now scrolls the results sideways"))
+		} else {
+			a.setStatus(tr("The mouse wheel now scrolls the results up and down, hold Shift to scroll sideways"))
+		}
+	})
+	a.win.AddAction(action)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"bytes"
+	"image"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// coverSize is the size flagged images are shrunk to for their cover.
+// Stretched back to full size they are blurred beyond recognition.
+const coverSize = 12
+
+// addBlurFlaggedAction adds the toggle for hiding images the backend flags
+// as NSFW behind a blurred cover, saved with the settings
+func (a *App) addBlurFlaggedAction() {
+	action := gio.NewSimpleActionStateful("blur-flagged", nil, glib.NewVariantBoolean(a.settings.BlurFlagged))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.settings.BlurFlagged = !a.settings.BlurFlagged
+		action.SetState(glib.NewVariantBoolean(a.settings.BlurFlagged))
+		a.saveSettings()
+
+		if a.settings.BlurFlagged {
+			a.setStatus(tr("New images flagged as sensitive are blurred until clicked"))
+		} else {
+			a.setStatus(tr("New images flagged as sensitive are shown directly"))
+		}
+	})
+	a.win.AddAction(action)
+}
+
+// imageFlags returns whether each of urls, a subset of the result's images,
+// was flagged as NSFW, or nil when the backend flagged none
+func imageFlags(urls []string, result *flux.GenerateResult) []bool {
+	if len(result.Flagged) != len(result.URLs) {
+		return nil
+	}
+	byURL := make(map[string]bool, len(result.URLs))
+	for i, url := range result.URLs {
+		byURL[url] = byURL[url] || result.Flagged[i]
+	}
+	flags := make([]bool, len(urls))
+	for i, url := range urls {
+		flags[i] = byURL[url]
+	}
+	return flags
+}
+
+// newCoverTexture returns the image blurred beyond recognition, shown in
+// place of a flagged image. It may download the image, so call it off the
+// main thread.
+func newCoverTexture(img *resultImage) (*gdk.Texture, error) {
+	data, err := img.original()
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil && img.motion {
+		// Videos are hidden behind a plain cover
+		return motionPlaceholder(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The picture scales the few pixels left back up smoothly
+	small := toRGBA(scaleToFit(src, coverSize))
+	return memoryTexture(small), nil
+}
+
+// createSensitiveCover creates the blurred cover laid over a flagged image,
+// which reveals the image when clicked, and the button covering it again
+func (a *App) createSensitiveCover(img *resultImage) (*gtk.Overlay, *gtk.Button) {
+	coverPicture := gtk.NewPicture()
+	coverPicture.SetPaintable(img.cover)
+	coverPicture.SetCanShrink(true)
+	coverPicture.SetContentFit(gtk.ContentFitFill)
+
+	noticeLabel := gtk.NewLabel(tr("Flagged as sensitive by the backend"))
+	noticeLabel.SetWrap(true)
+	noticeLabel.SetJustify(gtk.JustifyCenter)
+	revealLabel := gtk.NewLabel(tr("Click to reveal"))
+	revealLabel.AddCSSClass("dim-label")
+
+	noticeBox := gtk.NewBox(gtk.OrientationVertical, 4)
+	noticeBox.AddCSSClass("osd")
+	noticeBox.SetHAlign(gtk.AlignCenter)
+	noticeBox.SetVAlign(gtk.AlignCenter)
+	noticeBox.SetMarginStart(12)
+	noticeBox.SetMarginEnd(12)
+	noticeBox.Append(noticeLabel)
+	noticeBox.Append(revealLabel)
+
+	cover := gtk.NewOverlay()
+	cover.SetChild(coverPicture)
+	cover.AddOverlay(noticeBox)
+	cover.SetCursorFromName("pointer")
+
+	hideBtn := gtk.NewButtonWithLabel(tr("Hide"))
+	hideBtn.SetTooltipText(tr("Blur the image again"))
+	hideBtn.SetVisible(false)
+	hideBtn.ConnectClicked(func() {
+		cover.SetVisible(true)
+		hideBtn.SetVisible(false)
+	})
+
+	click := gtk.NewGestureClick()
+	click.ConnectReleased(func(nPress int, x, y float64) {
+		cover.SetVisible(false)
+		hideBtn.SetVisible(true)
+	})
+	cover.AddController(click)
+
+	return cover, hideBtn
+}
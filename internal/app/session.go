@@ -0,0 +1,380 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Layout of a session archive
+const (
+	sessionManifestName = "session.json"
+	sessionSettingsName = "settings.json"
+	sessionImagesDir    = "images/"
+)
+
+// sessionManifest lists the images of a session archive in the order they
+// were shown, so the last one is the latest result
+type sessionManifest struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Images     []string  `json:"images"` // Names in the images directory
+}
+
+// sessionImage is a result to write into a session archive
+type sessionImage struct {
+	url         string
+	data        []byte // Nil when the bytes are not kept and must be downloaded
+	format      imageFormat
+	annotations []annotation
+	batch       *generationBatch
+}
+
+// exportSession asks where to write the current results, their metadata and
+// the settings as a zip archive
+func (a *App) exportSession() {
+	if len(a.results) == 0 {
+		a.setStatus(tr("There are no results to export"))
+		return
+	}
+
+	// Collected now, as the results may be cleared while the dialog is open
+	images := make([]sessionImage, len(a.results))
+	for i, result := range a.results {
+		data, format := result.saveData()
+		images[i] = sessionImage{
+			url:         result.url,
+			data:        data,
+			format:      format,
+			annotations: result.annotationList(),
+			batch:       result.batch,
+		}
+	}
+	settings := a.settings
+
+	dialog := gtk.NewFileChooserNative(
+		tr("Export Session"),
+		&a.win.Window,
+		gtk.FileChooserActionSave,
+		tr("_Export"),
+		tr("_Cancel"),
+	)
+	dialog.SetCurrentName(fmt.Sprintf("fluxxxer-session-%s.zip", time.Now().Format("2006-01-02-150405")))
+	a.setDefaultSaveFolder(dialog)
+
+	dialog.ConnectResponse(func(response int) {
+		defer dialog.Destroy()
+		if response != int(gtk.ResponseAccept) {
+			return
+		}
+		file := dialog.File()
+		if file == nil {
+			a.setStatus(tr("Error: No file selected"))
+			return
+		}
+
+		dest := file.Path()
+		a.setStatus(fmt.Sprintf(tr("Exporting %d images..."), len(images)))
+		go func() {
+			err := a.writeSessionArchive(dest, images, settings)
+			glib.IdleAdd(func() {
+				if err != nil {
+					a.setStatus(fmt.Sprintf(tr("Error exporting session: %v"), err))
+					return
+				}
+				a.setSavedStatus(fmt.Sprintf(tr("Session exported to: %s"), dest), dest)
+			})
+		}()
+	})
+
+	dialog.Show()
+}
+
+// writeSessionArchive writes images with their metadata sidecars, the
+// settings and a manifest into a zip archive at dest. Images are written
+// as they would be saved, with transforms and annotations.
+func (a *App) writeSessionArchive(dest string, images []sessionImage, settings config.Settings) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".fluxxxer-session-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	manifest := sessionManifest{ExportedAt: time.Now()}
+	for i, img := range images {
+		data, format := img.data, img.format
+		if data == nil {
+			if data, format, err = a.downloadImage(img.url); err != nil {
+				return fmt.Errorf("image %d: %w", i+1, err)
+			}
+		}
+		if len(img.annotations) > 0 {
+			if data, format, err = burnAnnotations(data, format, img.annotations); err != nil {
+				return fmt.Errorf("image %d: %w", i+1, err)
+			}
+		}
+
+		// Numbered so names from different batches cannot clash
+		name := withFormatExt(fmt.Sprintf("%03d-%s", i+1, defaultImageName(img.url)), format)
+		if err := writeZipFile(archive, sessionImagesDir+name, data); err != nil {
+			return err
+		}
+		if meta := newImageMetadata(img.batch, format); meta != nil {
+			sidecar, err := marshalSidecar(meta)
+			if err != nil {
+				return err
+			}
+			if err := writeZipFile(archive, sidecarPath(sessionImagesDir+name), sidecar); err != nil {
+				return err
+			}
+		}
+		manifest.Images = append(manifest.Images, name)
+	}
+
+	for _, file := range []struct {
+		name  string
+		value any
+	}{
+		{sessionManifestName, manifest},
+		{sessionSettingsName, settings},
+	} {
+		data, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeZipFile(archive, file.name, data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// writeZipFile adds a file named name holding data to archive
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// importSession asks for a session archive and restores its images into
+// the gallery
+func (a *App) importSession() {
+	dialog := gtk.NewFileChooserNative(
+		tr("Import Session"),
+		&a.win.Window,
+		gtk.FileChooserActionOpen,
+		tr("_Import"),
+		tr("_Cancel"),
+	)
+	filter := gtk.NewFileFilter()
+	filter.SetName(tr("Session archives"))
+	filter.AddPattern("*.zip")
+	dialog.AddFilter(filter)
+
+	dialog.ConnectResponse(func(response int) {
+		defer dialog.Destroy()
+		if response != int(gtk.ResponseAccept) {
+			return
+		}
+		file := dialog.File()
+		if file == nil {
+			a.setStatus(tr("Error: No file selected"))
+			return
+		}
+
+		src := file.Path()
+		dir := a.config.GetOutputDir()
+		go func() {
+			count, latest, err := extractSession(src, dir)
+			glib.IdleAdd(func() {
+				if err != nil {
+					a.setStatus(fmt.Sprintf(tr("Error importing session: %v"), err))
+					return
+				}
+				a.setMode(modeGallery)
+				a.setStatus(fmt.Sprintf(tr("Imported %d images into %s"), count, dir))
+				if latest != nil {
+					a.offerSessionControls(latest)
+				}
+			})
+		}()
+	})
+
+	dialog.Show()
+}
+
+// extractSession copies the images of the session archive at src and their
+// metadata into dir, next to any images already there. It returns how many
+// images were copied and the metadata of the session's latest result.
+func extractSession(src, dir string) (int, *imageMetadata, error) {
+	archive, err := zip.OpenReader(src)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	// Archives without a manifest are imported in the order they list images
+	var names []string
+	var manifest sessionManifest
+	if f, ok := files[sessionManifestName]; ok {
+		data, err := readZipFile(f)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return 0, nil, fmt.Errorf("failed to parse %s: %w", sessionManifestName, err)
+		}
+		names = manifest.Images
+	} else {
+		for _, f := range archive.File {
+			if name, ok := strings.CutPrefix(f.Name, sessionImagesDir); ok && isImageFile(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return 0, nil, errors.New("the archive holds no images")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var latest *imageMetadata
+	count := 0
+	for _, name := range names {
+		// Names come from the archive, so keep them inside dir
+		name = path.Base(name)
+		f, ok := files[sessionImagesDir+name]
+		if !ok || !isImageFile(name) {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return count, latest, err
+		}
+		if err := checkImageData(data); err != nil {
+			return count, latest, fmt.Errorf("%s: %w", name, err)
+		}
+
+		dest := uniquePath(filepath.Join(dir, name), ".json")
+		if err := writeFileAtomic(dest, bytes.NewReader(data)); err != nil {
+			return count, latest, err
+		}
+		count++
+
+		// The sidecar is written through the same code as when saving, so
+		// the gallery reads it like any other
+		if f, ok := files[sidecarPath(sessionImagesDir+name)]; ok {
+			data, err := readZipFile(f)
+			if err != nil {
+				return count, latest, err
+			}
+			var meta imageMetadata
+			if err := json.Unmarshal(data, &meta); err != nil {
+				return count, latest, fmt.Errorf("failed to parse metadata of %s: %w", name, err)
+			}
+			if err := writeSidecar(dest, &meta); err != nil {
+				return count, latest, err
+			}
+			latest = &meta
+		}
+	}
+	return count, latest, nil
+}
+
+// readZipFile returns the contents of f
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// offerSessionControls asks whether to set the generation controls from
+// the latest result of an imported session
+func (a *App) offerSessionControls(meta *imageMetadata) {
+	dialog := gtk.NewMessageDialog(
+		&a.win.Window,
+		gtk.DialogModal|gtk.DialogDestroyWithParent,
+		gtk.MessageQuestion,
+		gtk.ButtonsNone,
+	)
+	dialog.SetObjectProperty("text", tr("Restore the session's controls?"))
+	dialog.SetObjectProperty("secondary-text", tr("The prompt, aspect ratio, seed and advanced settings of the session's latest result can replace the current ones."))
+	dialog.AddButton(tr("Keep Current"), int(gtk.ResponseReject))
+	dialog.AddButton(tr("Restore"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+		if responseId == int(gtk.ResponseAccept) {
+			a.applySessionControls(meta)
+		}
+	})
+	dialog.Show()
+}
+
+// applySessionControls sets the generation controls from the metadata of
+// an imported result and switches to the generator
+func (a *App) applySessionControls(meta *imageMetadata) {
+	a.setMode(modeGenerator)
+	a.setPromptText(meta.Prompt)
+
+	if aspectRatioCombo != nil {
+		for i, ratio := range a.config.GetSupportedAspectRatios() {
+			if ratio == meta.AspectRatio {
+				aspectRatioCombo.SetSelected(uint(i))
+				break
+			}
+		}
+	}
+	if a.guidanceSpin != nil {
+		a.guidanceSpin.SetValue(meta.Guidance)
+		a.stepsSpin.SetValue(float64(meta.Steps))
+	}
+
+	if meta.Seed != nil {
+		a.useSeed(*meta.Seed)
+		return
+	}
+	a.setStatus(tr("Restored the controls of the session's latest result"))
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestSession writes a session archive holding one image and its
+// metadata, listed without a manifest
+func writeTestSession(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	archive := zip.NewWriter(f)
+	files := map[string]string{
+		sessionImagesDir + "fox.png":  "\x89PNG\r\n\x1a\nimage data",
+		sessionImagesDir + "fox.json": `{"prompt": "a red fox"}`,
+	}
+	for name, contents := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractSessionKeepsNames(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "session.zip")
+	writeTestSession(t, src)
+	dir := t.TempDir()
+
+	// The first import keeps the names, the second is saved beside it
+	for _, want := range []string{"fox", "fox (1)"} {
+		count, latest, err := extractSession(src, dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 1 || latest == nil {
+			t.Fatalf("got %d images and metadata %v, want 1 image with metadata", count, latest)
+		}
+		for _, ext := range []string{".png", ".json"} {
+			if _, err := os.Stat(filepath.Join(dir, want+ext)); err != nil {
+				t.Errorf("%s%s not imported: %v", want, ext, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// recordGeneration counts a finished generation request in the statistics
+func (a *App) recordGeneration(job *generationJob, images int, err error) {
+	a.stats.APICalls++
+	if err == nil {
+		a.stats.Images += int64(images)
+		a.stats.Generations++
+		a.stats.GenerationTime += time.Since(job.started)
+		if a.stats.AspectRatios == nil {
+			a.stats.AspectRatios = map[string]int{}
+		}
+		a.stats.AspectRatios[job.opts.AspectRatio]++
+	}
+	a.saveStats()
+	a.updateGenerateTooltip()
+}
+
+// recordDownload counts downloaded image data in the statistics
+func (a *App) recordDownload(bytes int) {
+	a.stats.BytesDownloaded += int64(bytes)
+	a.saveStats()
+}
+
+// saveStats writes the statistics, reporting failures on the console only
+// since they are not worth interrupting the user for
+func (a *App) saveStats() {
+	if err := config.SaveStats(a.config.GetConfigDir(), a.stats); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving statistics: %v\n", err)
+	}
+}
+
+// showStatsDialog shows the usage statistics with an option to reset them
+func (a *App) showStatsDialog() {
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Statistics"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+
+	grid := gtk.NewGrid()
+	grid.SetRowSpacing(6)
+	grid.SetColumnSpacing(16)
+	grid.SetMarginTop(12)
+	grid.SetMarginBottom(12)
+	grid.SetMarginStart(12)
+	grid.SetMarginEnd(12)
+
+	var values []*gtk.Label
+	rows := []string{"Images generated", "API calls", "Downloaded", "Average generation time", "Most used aspect ratio"}
+	for i, name := range rows {
+		nameLabel := gtk.NewLabel(tr(name))
+		nameLabel.SetXAlign(0)
+		value := gtk.NewLabel("")
+		value.SetXAlign(1)
+		value.SetSelectable(true)
+		grid.Attach(nameLabel, 0, i, 1, 1)
+		grid.Attach(value, 1, i, 1, 1)
+		values = append(values, value)
+	}
+
+	update := func() {
+		mostUsed := a.stats.MostUsedAspectRatio()
+		if mostUsed == "" {
+			mostUsed = "-"
+		}
+		values[0].SetText(fmt.Sprint(a.stats.Images))
+		values[1].SetText(fmt.Sprint(a.stats.APICalls))
+		values[2].SetText(formatBytes(a.stats.BytesDownloaded))
+		values[3].SetText(a.stats.AverageGenerationTime().Round(100 * time.Millisecond).String())
+		values[4].SetText(mostUsed)
+	}
+	update()
+
+	dialog.ContentArea().Append(grid)
+	dialog.AddButton(tr("Reset"), int(gtk.ResponseReject))
+	dialog.AddButton(tr("Close"), int(gtk.ResponseClose))
+	dialog.ConnectResponse(func(responseId int) {
+		if responseId == int(gtk.ResponseReject) {
+			a.confirmResetStats(&dialog.Window, update)
+			return
+		}
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
+
+// confirmResetStats asks before clearing the statistics, which cannot be
+// undone, and calls cleared once they are
+func (a *App) confirmResetStats(parent *gtk.Window, cleared func()) {
+	dialog := gtk.NewMessageDialog(
+		parent,
+		gtk.DialogModal|gtk.DialogDestroyWithParent,
+		gtk.MessageQuestion,
+		gtk.ButtonsNone,
+	)
+	dialog.SetObjectProperty("text", tr("Reset the statistics?"))
+	dialog.SetObjectProperty("secondary-text", tr("Every count and the average generation time start again from zero. This cannot be undone."))
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Reset"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+		if responseId == int(gtk.ResponseAccept) {
+			a.stats = config.Stats{}
+			a.saveStats()
+			cleared()
+		}
+	})
+	dialog.Show()
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "3.2 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
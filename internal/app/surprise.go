@@ -0,0 +1,34 @@
+package app
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"fluxxxer/internal/config"
+)
+
+// surprisePrompt fills the prompt entry with a random prompt assembled from
+// the word banks. The banks are read on every click so edits to the file
+// apply right away.
+func (a *App) surprisePrompt() {
+	banks, err := config.LoadWordBanks(a.config.GetConfigDir())
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: %v, using the bundled words"), err))
+	}
+
+	parts := []string{
+		pickWord(banks.Subjects),
+		pickWord(banks.Styles),
+		pickWord(banks.Lighting),
+		pickWord(banks.Compositions),
+	}
+
+	a.setPromptText(strings.Join(parts, ", "))
+	a.focusPromptEnd()
+}
+
+// pickWord returns a random entry of words
+func pickWord(words []string) string {
+	return words[rand.IntN(len(words))]
+}
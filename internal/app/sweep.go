@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"fluxxxer/internal/flux"
+)
+
+// Sweep parameters shown in the sweep dropdown
+const (
+	sweepNone        = "None"
+	sweepSeed        = "Seed"
+	sweepImageSeeds  = "Seed per Image"
+	sweepAspectRatio = "Aspect Ratio"
+)
+
+// sweepParameters lists the parameters that can be swept, in dropdown order
+var sweepParameters = []string{sweepNone, sweepSeed, sweepImageSeeds, sweepAspectRatio}
+
+// sweepRun is a single queued generation, one per value in a parameter sweep
+// or prompt of a multi-prompt batch
+type sweepRun struct {
+	label  string
+	prompt string // Replaces the typed prompt when set
+	opts   flux.GenerateOptions
+}
+
+// selectedSweepParameter returns the parameter chosen in the sweep dropdown
+func (a *App) selectedSweepParameter() string {
+	if a.sweepCombo == nil {
+		return sweepNone
+	}
+	idx := a.sweepCombo.Selected()
+	if idx >= uint(len(sweepParameters)) {
+		return sweepNone
+	}
+	return sweepParameters[idx]
+}
+
+// useSeed sets up the next generation to use seed, entering it as the only
+// value of a seed sweep
+func (a *App) useSeed(seed int) {
+	for i, param := range sweepParameters {
+		if param == sweepSeed {
+			a.sweepCombo.SetSelected(uint(i))
+			break
+		}
+	}
+	a.sweepEntry.SetText(strconv.Itoa(seed))
+	a.setStatus(fmt.Sprintf(tr("Seed %d will be used for the next generation"), seed))
+}
+
+// buildSweep expands the sweep values into one run per value.
+// It returns nil runs when no sweep is selected.
+func (a *App) buildSweep(base flux.GenerateOptions) ([]sweepRun, error) {
+	param := a.selectedSweepParameter()
+	if param == sweepNone {
+		return nil, nil
+	}
+
+	var values []string
+	if a.sweepEntry != nil {
+		for _, v := range strings.Split(a.sweepEntry.Text(), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	var runs []sweepRun
+	switch param {
+	case sweepSeed:
+		if len(values) == 0 {
+			return nil, fmt.Errorf("enter one or more seeds to sweep, separated by commas")
+		}
+		seeds, err := parseSeeds(values)
+		if err != nil {
+			return nil, err
+		}
+		for _, seed := range seeds {
+			opts := base
+			opts.Seed = &seed
+			runs = append(runs, sweepRun{label: fmt.Sprintf(tr("Seed %d"), seed), opts: opts})
+		}
+
+	case sweepImageSeeds:
+		// Each image is its own request so it can have its own seed
+		seeds, err := parseSeeds(values)
+		if err != nil {
+			return nil, err
+		}
+		count := max(base.NumOutputs, len(seeds))
+		for i := 0; i < count; i++ {
+			// Images beyond the list get random seeds
+			var seed int
+			var label string
+			if i < len(seeds) {
+				seed = seeds[i]
+				label = fmt.Sprintf(tr("Seed %d"), seed)
+			} else {
+				seed = rand.IntN(math.MaxInt32)
+				label = fmt.Sprintf(tr("Seed %d (random)"), seed)
+			}
+			opts := base
+			opts.NumOutputs = 1
+			opts.Seed = &seed
+			runs = append(runs, sweepRun{label: label, opts: opts})
+		}
+
+	case sweepAspectRatio:
+		// Sweep every supported ratio when no values are given
+		if len(values) == 0 {
+			values = a.config.GetSupportedAspectRatios()
+		}
+		for _, v := range values {
+			if !a.isSupportedAspectRatio(v) {
+				return nil, fmt.Errorf("unsupported aspect ratio %q", v)
+			}
+			opts := base
+			opts.AspectRatio = v
+			runs = append(runs, sweepRun{label: fmt.Sprintf(tr("Aspect ratio %s"), v), opts: opts})
+		}
+	}
+
+	return runs, nil
+}
+
+// parseSeeds converts seed values entered as text into numbers
+func parseSeeds(values []string) ([]int, error) {
+	seeds := make([]int, 0, len(values))
+	for _, v := range values {
+		seed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed %q", v)
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+// isSupportedAspectRatio reports whether ratio is one of the configured ratios
+func (a *App) isSupportedAspectRatio(ratio string) bool {
+	for _, r := range a.config.GetSupportedAspectRatios() {
+		if r == ratio {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+)
+
+// tagList holds the tags of a generation. The batches of its images share
+// it, so tags edited on the results apply to each image saved afterwards.
+type tagList struct {
+	mu   sync.Mutex // Read when saving, off the main thread
+	tags []string
+}
+
+// list returns a copy of the tags, nil for a nil list
+func (t *tagList) list() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.tags)
+}
+
+// set replaces the tags
+func (t *tagList) set(tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tags = slices.Clone(tags)
+}
+
+// normalizeTags trims tags and drops empty ones and repeats, which differ
+// only in case, keeping the first of each in order
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag != "" && !hasTag(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// hasTag reports whether tags holds tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool {
+		return strings.EqualFold(t, tag)
+	})
+}
+
+// formatTags returns tags as shown on buttons and labels
+func formatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+// knownTags returns every tag used in the gallery or on the results of this
+// session, sorted, for completing tags as they are typed
+func (a *App) knownTags() []string {
+	var tags []string
+	for _, entry := range a.galleryEntries {
+		tags = append(tags, entry.tags...)
+	}
+	for _, result := range a.results {
+		tags = append(tags, result.batch.tags.list()...)
+	}
+	return sortTags(normalizeTags(tags))
+}
+
+// sortTags sorts tags alphabetically, ignoring case, and returns them
+func sortTags(tags []string) []string {
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+	return tags
+}
+
+// createTagsButton creates the button showing the tags of a generation,
+// opening the tag editor when clicked
+func (a *App) createTagsButton(tags *tagList) *gtk.Button {
+	label := gtk.NewLabel("")
+	label.SetEllipsize(pango.EllipsizeEnd)
+	label.SetMaxWidthChars(32)
+
+	tagsBtn := gtk.NewButton()
+	tagsBtn.SetChild(label)
+	tagsBtn.SetVAlign(gtk.AlignCenter)
+	update := func() {
+		if list := tags.list(); len(list) > 0 {
+			label.SetText(fmt.Sprintf(tr("Tags: %s"), formatTags(list)))
+		} else {
+			label.SetText(tr("Add Tags"))
+		}
+	}
+	update()
+
+	tagsBtn.SetTooltipText(tr("Tag this generation. Tags are saved with its images and can be searched in the gallery."))
+	tagsBtn.ConnectClicked(func() {
+		a.showTagEditor(tags.list(), func(edited []string) {
+			tags.set(edited)
+			update()
+		})
+	})
+	return tagsBtn
+}
+
+// showTagEditor shows a dialog editing tags, suggesting tags used before
+// as they are typed. done receives the edited tags unless it is cancelled.
+func (a *App) showTagEditor(tags []string, done func(tags []string)) {
+	tags = normalizeTags(tags)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(tr("Edit Tags"))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(360, -1)
+
+	chipBox := gtk.NewFlowBox()
+	chipBox.SetSelectionMode(gtk.SelectionNone)
+	chipBox.SetColumnSpacing(4)
+	chipBox.SetRowSpacing(4)
+
+	emptyLabel := gtk.NewLabel(tr("No tags yet"))
+	emptyLabel.AddCSSClass("dim-label")
+	emptyLabel.SetXAlign(0)
+
+	var refresh func()
+	refresh = func() {
+		chipBox.RemoveAll()
+		for _, tag := range tags {
+			tagLabel := gtk.NewLabel(tag)
+			tagLabel.SetMarginStart(6)
+
+			removeBtn := gtk.NewButtonFromIconName("window-close-symbolic")
+			removeBtn.SetTooltipText(tr("Remove tag"))
+			removeBtn.AddCSSClass("flat")
+			removeBtn.ConnectClicked(func() {
+				tags = slices.DeleteFunc(tags, func(t string) bool {
+					return t == tag
+				})
+				refresh()
+			})
+
+			chip := gtk.NewBox(gtk.OrientationHorizontal, 2)
+			chip.AddCSSClass("card")
+			chip.Append(tagLabel)
+			chip.Append(removeBtn)
+			chipBox.Append(chip)
+		}
+		emptyLabel.SetVisible(len(tags) == 0)
+	}
+	refresh()
+
+	// Tags used before complete as they are typed
+	store := gtk.NewListStore([]glib.Type{glib.TypeString})
+	for _, tag := range a.knownTags() {
+		store.Set(store.Append(), []int{0}, []glib.Value{*glib.NewValue(tag)})
+	}
+	completion := gtk.NewEntryCompletion()
+	completion.SetModel(store)
+	completion.SetTextColumn(0)
+	completion.SetInlineCompletion(true)
+
+	tagEntry := gtk.NewEntry()
+	tagEntry.SetPlaceholderText(tr("Type a tag and press Enter"))
+	tagEntry.SetCompletion(completion)
+	addTag := func() {
+		// Several tags can be pasted at once, separated by commas
+		tags = normalizeTags(append(tags, strings.Split(tagEntry.Text(), ",")...))
+		tagEntry.SetText("")
+		refresh()
+	}
+	tagEntry.ConnectActivate(addTag)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(12)
+	contentBox.SetMarginBottom(12)
+	contentBox.SetMarginStart(12)
+	contentBox.SetMarginEnd(12)
+	contentBox.Append(emptyLabel)
+	contentBox.Append(chipBox)
+	contentBox.Append(tagEntry)
+
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Done"), int(gtk.ResponseAccept))
+	dialog.ConnectResponse(func(responseId int) {
+		defer dialog.Destroy()
+		if responseId != int(gtk.ResponseAccept) {
+			return
+		}
+		// A tag typed but not yet added still counts
+		if strings.TrimSpace(tagEntry.Text()) != "" {
+			addTag()
+		}
+		done(tags)
+	})
+	dialog.Show()
+	tagEntry.GrabFocus()
+}
+
+// editGalleryTags shows the tag editor for the saved image at path and
+// writes the edited tags into its metadata sidecar
+func (a *App) editGalleryTags(path string, changed func(tags []string)) {
+	i := slices.IndexFunc(a.galleryEntries, func(entry galleryEntry) bool {
+		return entry.path == path
+	})
+	if i < 0 {
+		return
+	}
+	entry := a.galleryEntries[i]
+
+	a.showTagEditor(entry.tags, func(tags []string) {
+		go func() {
+			// Images saved without metadata get a sidecar holding the tags
+			meta := readSidecar(path)
+			if meta == nil {
+				meta = &imageMetadata{Format: entry.format, CreatedAt: entry.created}
+			}
+			meta.Tags = tags
+			err := writeSidecar(path, meta)
+			glib.IdleAdd(func() {
+				if err != nil {
+					a.setStatus(fmt.Sprintf(tr("Error saving tags: %v"), err))
+					return
+				}
+				// The gallery may have been refreshed meanwhile
+				for i := range a.galleryEntries {
+					if a.galleryEntries[i].path == path {
+						a.galleryEntries[i].tags = tags
+					}
+				}
+				changed(tags)
+				a.refreshGalleryTags()
+				a.setGalleryFilter(a.galleryFilter)
+			})
+		}()
+	})
+}
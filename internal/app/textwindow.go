@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// showTextWindow shows text in a monospace, read-only window with a button
+// copying it, which reports copied in the status bar
+func (a *App) showTextWindow(title, text, copied string) {
+	textView := gtk.NewTextView()
+	textView.SetEditable(false)
+	textView.SetMonospace(true)
+	textView.SetWrapMode(gtk.WrapWordChar)
+	textView.SetLeftMargin(8)
+	textView.SetRightMargin(8)
+	textView.SetTopMargin(8)
+	textView.SetBottomMargin(8)
+	textView.Buffer().SetText(text)
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetPolicy(gtk.PolicyAutomatic, gtk.PolicyAutomatic)
+	scrollWin.SetChild(textView)
+	scrollWin.SetVExpand(true)
+
+	copyBtn := gtk.NewButtonWithLabel(tr("Copy"))
+	copyBtn.ConnectClicked(func() {
+		a.copyText(text, copied)
+	})
+
+	buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	buttonBox.SetHAlign(gtk.AlignEnd)
+	buttonBox.Append(copyBtn)
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.Append(scrollWin)
+	contentBox.Append(buttonBox)
+
+	window := gtk.NewWindow()
+	window.SetTitle(title)
+	window.SetTransientFor(&a.win.Window)
+	window.SetDefaultSize(640, 520)
+	window.SetChild(contentBox)
+	window.Present()
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	xdraw "golang.org/x/image/draw"
+)
+
+// thumbnailSize is the maximum width and height of gallery thumbnails
+const thumbnailSize = 256
+
+// thumbnailSlots limits how many thumbnails are generated at once
+var thumbnailSlots = make(chan struct{}, 4)
+
+// loadThumbnail returns a small texture for the image at srcPath, creating
+// and caching the thumbnail under cacheDir/thumbnails if needed
+func loadThumbnail(cacheDir, srcPath string) (*gdk.Texture, error) {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// Without a cache directory the full image is used instead
+	if cacheDir == "" {
+		return loadTextureFromFile(srcPath)
+	}
+
+	thumbPath := thumbnailPath(cacheDir, srcPath, info)
+	if data, err := os.ReadFile(thumbPath); err == nil {
+		return newTexture(data)
+	}
+
+	thumbnailSlots <- struct{}{}
+	defer func() { <-thumbnailSlots }()
+
+	data, err := createThumbnail(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caching is best effort, the thumbnail is still shown if this fails
+	if err := writeFileAtomic(thumbPath, bytes.NewReader(data)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to cache thumbnail for %s: %v\n", srcPath, err)
+	}
+
+	return newTexture(data)
+}
+
+// thumbnailPath returns the cache path for a thumbnail, keyed by a hash of
+// the source path, size and modification time so edits invalidate it
+func thumbnailPath(cacheDir, srcPath string, info os.FileInfo) string {
+	key := fmt.Sprintf("%s|%d|%d|%d", srcPath, info.Size(), info.ModTime().UnixNano(), thumbnailSize)
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(cacheDir, "thumbnails", hex.EncodeToString(sum[:])+".png")
+}
+
+// createThumbnail decodes the image at srcPath and returns a PNG scaled to
+// fit within thumbnailSize, with the image's color profile
+func createThumbnail(srcPath string) ([]byte, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	thumbnail, _, err := encodeImage(scaleToFit(src, thumbnailSize), imageFormats[0], extractICCProfile(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return thumbnail, nil
+}
+
+// scaleToFit scales src down so neither side exceeds maxSize, keeping its
+// aspect ratio. Images that already fit are returned unchanged.
+func scaleToFit(src image.Image, maxSize int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return src
+	}
+
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
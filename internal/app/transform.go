@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+
+	// Register the WebP decoder for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+// imageTransform is an orientation change applied to a result image. The
+// pixels are moved without loss, but JPEG images are encoded again, which
+// loses some quality, and WebP images are written as PNG.
+type imageTransform int
+
+const (
+	rotateClockwise imageTransform = iota
+	flipHorizontal
+	flipVertical
+)
+
+// createTransformButtons creates the rotate, flip and reset buttons for a
+// result image, updating picture as transforms are applied
+func (a *App) createTransformButtons(img *resultImage, picture *gtk.Picture) *gtk.Box {
+	buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 4)
+	buttonBox.SetHAlign(gtk.AlignCenter)
+
+	// run applies a change in the background and shows the new texture
+	run := func(change func() (*gdk.Texture, error)) {
+		buttonBox.SetSensitive(false)
+		go func() {
+			texture, err := change()
+			glib.IdleAdd(func() {
+				buttonBox.SetSensitive(true)
+				if err != nil {
+					a.setStatus(fmt.Sprintf(tr("Error transforming image: %v"), err))
+					return
+				}
+				picture.SetPaintable(texture)
+			})
+		}()
+	}
+
+	buttons := []struct {
+		label   string
+		tooltip string
+		change  func() (*gdk.Texture, error)
+	}{
+		{"Rotate", "Rotate 90° clockwise", func() (*gdk.Texture, error) { return img.transform(rotateClockwise) }},
+		{"Flip H", "Flip horizontally", func() (*gdk.Texture, error) { return img.transform(flipHorizontal) }},
+		{"Flip V", "Flip vertically", func() (*gdk.Texture, error) { return img.transform(flipVertical) }},
+		{"Reset", "Restore the original image", img.reset},
+	}
+
+	for _, b := range buttons {
+		btn := gtk.NewButtonWithLabel(tr(b.label))
+		btn.SetTooltipText(tr(b.tooltip))
+		btn.ConnectClicked(func() {
+			run(b.change)
+		})
+		buttonBox.Append(btn)
+	}
+
+	return buttonBox
+}
+
+// applyTransforms decodes data, applies the transforms in order and
+// re-encodes the result, keeping its color profile. Formats without an
+// encoder are written as PNG, so the returned format may differ from the
+// input format. JPEG is encoded again at quality 95, so apply transforms to
+// the original bytes rather than to an earlier result.
+func applyTransforms(data []byte, format imageFormat, transforms []imageTransform) ([]byte, imageFormat, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	img := toRGBA(src)
+	for _, t := range transforms {
+		switch t {
+		case rotateClockwise:
+			img = rotate90(img)
+		case flipHorizontal:
+			img = flip(img, true)
+		case flipVertical:
+			img = flip(img, false)
+		}
+	}
+
+	return encodeImage(img, format, extractICCProfile(data))
+}
+
+// encodeImage encodes img in format with the ICC color profile, if any,
+// falling back to PNG when the format cannot be encoded
+func encodeImage(img image.Image, format imageFormat, profile []byte) ([]byte, imageFormat, error) {
+	var buf bytes.Buffer
+	switch format.MIME {
+	case "image/jpeg":
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+			return nil, format, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+	default:
+		if err := encodePNG(&buf, img); err != nil {
+			return nil, format, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		format = imageFormats[0]
+	}
+
+	data, err := embedICCProfile(buf.Bytes(), format, profile)
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to embed color profile: %w", err)
+	}
+	return data, format, nil
+}
+
+// cancelsOut reports whether transforms applied in order leave the image as
+// it was, such as four rotations or the same flip twice, so the original
+// bytes can be kept instead of encoding them again
+func cancelsOut(transforms []imageTransform) bool {
+	// The orientation is tracked as a horizontal flip followed by a number
+	// of clockwise rotations. A vertical flip is a horizontal flip followed
+	// by two rotations.
+	flipped, rotations := false, 0
+	for _, t := range transforms {
+		switch t {
+		case rotateClockwise:
+			rotations++
+		case flipHorizontal:
+			flipped, rotations = !flipped, -rotations
+		case flipVertical:
+			flipped, rotations = !flipped, 2-rotations
+		}
+	}
+	return !flipped && ((rotations%4)+4)%4 == 0
+}
+
+// toRGBA converts any image into an RGBA image with its origin at 0,0
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// rotate90 rotates img 90 degrees clockwise
+func rotate90(img *image.RGBA) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, h, w))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetRGBA(h-1-y, x, img.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// flip mirrors img horizontally or vertically
+func flip(img *image.RGBA, horizontal bool) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if horizontal {
+				dst.SetRGBA(w-1-x, y, img.RGBAAt(x, y))
+			} else {
+				dst.SetRGBA(x, h-1-y, img.RGBAAt(x, y))
+			}
+		}
+	}
+	return dst
+}
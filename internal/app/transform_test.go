@@ -0,0 +1,52 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestCancelsOut(t *testing.T) {
+	r, h, v := rotateClockwise, flipHorizontal, flipVertical
+	tests := []struct {
+		name       string
+		transforms []imageTransform
+	}{
+		{"none", nil},
+		{"rotate", []imageTransform{r}},
+		{"rotate twice", []imageTransform{r, r}},
+		{"rotate four times", []imageTransform{r, r, r, r}},
+		{"flip twice", []imageTransform{h, h}},
+		{"flip both ways", []imageTransform{h, v}},
+		{"flip both ways and half turn", []imageTransform{h, v, r, r}},
+		{"flip around a rotation", []imageTransform{h, r, h, r}},
+		{"flip around a quarter turn", []imageTransform{v, r, v}},
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Compare with the pixels actually moved by the transforms
+			img := src
+			for _, change := range tt.transforms {
+				switch change {
+				case rotateClockwise:
+					img = rotate90(img)
+				case flipHorizontal:
+					img = flip(img, true)
+				case flipVertical:
+					img = flip(img, false)
+				}
+			}
+			want := img.Bounds() == src.Bounds() && bytes.Equal(img.Pix, src.Pix)
+
+			if got := cancelsOut(tt.transforms); got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+)
+
+// Names of the StatusNotifierItem spec and the menu it points to. Hosts
+// look for the item at trayItemPath of the bus name it is registered with.
+const (
+	trayItemPath      = "/StatusNotifierItem"
+	trayItemInterface = "org.kde.StatusNotifierItem"
+	trayMenuPath      = "/MenuBar"
+	trayMenuInterface = "com.canonical.dbusmenu"
+	trayWatcherName   = "org.kde.StatusNotifierWatcher"
+	trayWatcherPath   = "/StatusNotifierWatcher"
+	trayIconName      = "image-x-generic"
+	trayCallTimeoutMs = 5000
+)
+
+// IDs of the tray menu items, 0 being the menu itself
+const (
+	trayMenuRoot int32 = iota
+	trayMenuProgress
+	trayMenuSeparator
+	trayMenuShow
+	trayMenuQuit
+)
+
+// trayIntrospection describes the tray objects to hosts that ask
+const trayIntrospection = `<node>
+ <interface name="org.kde.StatusNotifierItem">
+  <method name="Activate"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+  <method name="SecondaryActivate"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+  <method name="ContextMenu"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+  <method name="Scroll"><arg name="delta" type="i" direction="in"/><arg name="orientation" type="s" direction="in"/></method>
+  <signal name="NewTitle"/>
+  <signal name="NewToolTip"/>
+  <property name="Category" type="s" access="read"/>
+  <property name="Id" type="s" access="read"/>
+  <property name="Title" type="s" access="read"/>
+  <property name="Status" type="s" access="read"/>
+  <property name="IconName" type="s" access="read"/>
+  <property name="ToolTip" type="(sa(iiay)ss)" access="read"/>
+  <property name="ItemIsMenu" type="b" access="read"/>
+  <property name="Menu" type="o" access="read"/>
+ </interface>
+ <interface name="com.canonical.dbusmenu">
+  <method name="GetLayout"><arg type="i" direction="in"/><arg type="i" direction="in"/><arg type="as" direction="in"/><arg type="u" direction="out"/><arg type="(ia{sv}av)" direction="out"/></method>
+  <method name="GetGroupProperties"><arg type="ai" direction="in"/><arg type="as" direction="in"/><arg type="a(ia{sv})" direction="out"/></method>
+  <method name="Event"><arg type="i" direction="in"/><arg type="s" direction="in"/><arg type="v" direction="in"/><arg type="u" direction="in"/></method>
+  <method name="EventGroup"><arg type="a(isvu)" direction="in"/><arg type="ai" direction="out"/></method>
+  <method name="AboutToShow"><arg type="i" direction="in"/><arg type="b" direction="out"/></method>
+  <method name="AboutToShowGroup"><arg type="ai" direction="in"/><arg type="ai" direction="out"/><arg type="ai" direction="out"/></method>
+  <signal name="LayoutUpdated"><arg type="u"/><arg type="i"/></signal>
+  <property name="Version" type="u" access="read"/>
+  <property name="TextDirection" type="s" access="read"/>
+  <property name="Status" type="s" access="read"/>
+  <property name="IconThemePath" type="as" access="read"/>
+ </interface>
+</node>`
+
+// tray is the icon shown in the desktop's system tray while the tray
+// setting is on. Its objects are registered on the main loop, so GDBus
+// calls them there and they may touch the window directly.
+type tray struct {
+	conn     *gio.DBusConnection
+	busName  string
+	objects  []uint // Registrations of the item and menu objects
+	nameLost uint   // Subscription to the bus taking busName away
+
+	progress string // Queue progress shown in the tooltip and menu
+	revision uint32 // Bumped when the menu changes
+
+	show func()
+	quit func()
+	lost func() // Called when busName is lost after registering
+}
+
+// newTray exports the tray objects on conn. It is not shown until it is
+// registered with the watcher.
+func newTray(conn *gio.DBusConnection, progress string, show, quit, lost func()) (*tray, error) {
+	t := &tray{
+		conn:     conn,
+		busName:  fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid()),
+		progress: progress,
+		show:     show,
+		quit:     quit,
+		lost:     lost,
+	}
+
+	node, err := gio.NewDBusNodeInfoForXML(trayIntrospection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tray introspection: %w", err)
+	}
+	objects := []struct {
+		path, iface string
+		call        func(method string, params *glib.Variant, invocation *gio.DBusMethodInvocation)
+		properties  func() map[string]*glib.Variant
+	}{
+		{trayItemPath, trayItemInterface, t.itemCall, t.itemProperties},
+		{trayMenuPath, trayMenuInterface, t.menuCall, t.menuProperties},
+	}
+	for _, object := range objects {
+		call, properties := object.call, object.properties
+		id, err := conn.RegisterObject(object.path, node.LookupInterface(object.iface),
+			func(_ *gio.DBusConnection, _, _, _, method string, params *glib.Variant, invocation *gio.DBusMethodInvocation) {
+				call(method, params, invocation)
+			},
+			func(_ *gio.DBusConnection, _, _, _, property string) *glib.Value {
+				return glib.NewVariantValue(properties()[property])
+			},
+			func(_ *gio.DBusConnection, _, _, _, _ string, _ *glib.Variant) bool {
+				return false
+			})
+		if err != nil {
+			t.close()
+			return nil, fmt.Errorf("failed to export %s: %w", object.path, err)
+		}
+		t.objects = append(t.objects, id)
+	}
+	return t, nil
+}
+
+// register takes the tray's bus name and registers the item with the
+// watcher, calling done with the outcome on the main loop. The bindings
+// have no g_bus_own_name, so it asks the bus itself and watches NameLost
+// the way g_bus_own_name would.
+func (t *tray) register(done func(error)) {
+	t.nameLost = t.conn.SignalSubscribe("org.freedesktop.DBus", "org.freedesktop.DBus", "NameLost", "/org/freedesktop/DBus", t.busName,
+		gio.DBusSignalFlagsNone, func(*gio.DBusConnection, string, string, string, string, *glib.Variant) {
+			if t.lost != nil {
+				t.lost()
+			}
+		})
+
+	request := glib.NewVariantTuple([]*glib.Variant{
+		glib.NewVariantString(t.busName),
+		glib.NewVariantUint32(uint32(gio.BusNameOwnerFlagsDoNotQueue)),
+	})
+	t.conn.Call(context.Background(), "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "RequestName",
+		request, glib.NewVariantType("(u)"), gio.DBusCallFlagsNone, trayCallTimeoutMs, func(res gio.AsyncResulter) {
+			reply, err := t.conn.CallFinish(res)
+			if err != nil {
+				done(fmt.Errorf("failed to take bus name %s: %w", t.busName, err))
+				return
+			}
+			// 1 means the name is now ours
+			if reply.ChildValue(0).Uint32() != 1 {
+				done(fmt.Errorf("bus name %s is taken", t.busName))
+				return
+			}
+
+			register := glib.NewVariantTuple([]*glib.Variant{glib.NewVariantString(t.busName)})
+			t.conn.Call(context.Background(), trayWatcherName, trayWatcherPath, trayWatcherName, "RegisterStatusNotifierItem",
+				register, nil, gio.DBusCallFlagsNone, trayCallTimeoutMs, func(res gio.AsyncResulter) {
+					_, err := t.conn.CallFinish(res)
+					done(err)
+				})
+		})
+}
+
+// close removes the icon. Giving up the bus name tells the watcher the
+// item is gone.
+func (t *tray) close() {
+	if t.nameLost != 0 {
+		t.conn.SignalUnsubscribe(t.nameLost)
+		t.nameLost = 0
+	}
+	for _, id := range t.objects {
+		t.conn.UnregisterObject(id)
+	}
+	t.objects = nil
+
+	release := glib.NewVariantTuple([]*glib.Variant{glib.NewVariantString(t.busName)})
+	t.conn.Call(context.Background(), "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "ReleaseName",
+		release, nil, gio.DBusCallFlagsNone, trayCallTimeoutMs, nil)
+}
+
+// setProgress updates the tooltip and menu, telling hosts when it changed
+func (t *tray) setProgress(progress string) {
+	if progress == t.progress {
+		return
+	}
+	t.progress = progress
+	t.revision++
+
+	t.conn.EmitSignal("", trayItemPath, trayItemInterface, "NewToolTip", nil)
+	t.conn.EmitSignal("", trayMenuPath, trayMenuInterface, "LayoutUpdated", glib.NewVariantTuple([]*glib.Variant{
+		glib.NewVariantUint32(t.revision),
+		glib.NewVariantInt32(trayMenuRoot),
+	}))
+}
+
+// itemCall answers a method call to the StatusNotifierItem object
+func (t *tray) itemCall(method string, _ *glib.Variant, invocation *gio.DBusMethodInvocation) {
+	invocation.ReturnValue(nil)
+	switch method {
+	case "Activate", "SecondaryActivate":
+		t.show()
+	}
+}
+
+// menuCall answers a method call to the dbusmenu object of the tray menu
+func (t *tray) menuCall(method string, params *glib.Variant, invocation *gio.DBusMethodInvocation) {
+	switch method {
+	case "GetLayout":
+		layout := t.menuLayout(params.ChildValue(0).Int32())
+		invocation.ReturnValue(glib.NewVariantTuple([]*glib.Variant{glib.NewVariantUint32(t.revision), layout}))
+	case "GetGroupProperties":
+		ids := params.ChildValue(0)
+		var items []*glib.Variant
+		for i := uint(0); i < ids.NChildren(); i++ {
+			id := ids.ChildValue(i).Int32()
+			items = append(items, glib.NewVariantTuple([]*glib.Variant{
+				glib.NewVariantInt32(id),
+				variantDict(t.menuItemProperties(id)),
+			}))
+		}
+		invocation.ReturnValue(glib.NewVariantTuple([]*glib.Variant{
+			glib.NewVariantArray(glib.NewVariantType("(ia{sv})"), items),
+		}))
+	case "Event":
+		invocation.ReturnValue(nil)
+		t.menuEvent(params.ChildValue(0).Int32(), params.ChildValue(1).String())
+	case "EventGroup":
+		invocation.ReturnValue(glib.NewVariantTuple([]*glib.Variant{
+			glib.NewVariantArray(glib.NewVariantType("i"), nil),
+		}))
+		events := params.ChildValue(0)
+		for i := uint(0); i < events.NChildren(); i++ {
+			event := events.ChildValue(i)
+			t.menuEvent(event.ChildValue(0).Int32(), event.ChildValue(1).String())
+		}
+	case "AboutToShow":
+		invocation.ReturnValue(glib.NewVariantTuple([]*glib.Variant{glib.NewVariantBoolean(false)}))
+	case "AboutToShowGroup":
+		invocation.ReturnValue(glib.NewVariantTuple([]*glib.Variant{
+			glib.NewVariantArray(glib.NewVariantType("i"), nil),
+			glib.NewVariantArray(glib.NewVariantType("i"), nil),
+		}))
+	default:
+		invocation.ReturnDBusError("org.freedesktop.DBus.Error.UnknownMethod", "Unknown method "+method)
+	}
+}
+
+// itemProperties returns the properties of the StatusNotifierItem
+func (t *tray) itemProperties() map[string]*glib.Variant {
+	toolTip := glib.NewVariantTuple([]*glib.Variant{
+		glib.NewVariantString(trayIconName),
+		glib.NewVariantArray(glib.NewVariantType("(iiay)"), nil),
+		glib.NewVariantString("Fluxxxer"),
+		glib.NewVariantString(t.progress),
+	})
+	return map[string]*glib.Variant{
+		"Category":   glib.NewVariantString("ApplicationStatus"),
+		"Id":         glib.NewVariantString("fluxxxer"),
+		"Title":      glib.NewVariantString("Fluxxxer"),
+		"Status":     glib.NewVariantString("Active"),
+		"IconName":   glib.NewVariantString(trayIconName),
+		"ToolTip":    toolTip,
+		"ItemIsMenu": glib.NewVariantBoolean(false),
+		"Menu":       glib.NewVariantObjectPath(trayMenuPath),
+	}
+}
+
+// menuProperties returns the properties of the tray menu object
+func (t *tray) menuProperties() map[string]*glib.Variant {
+	return map[string]*glib.Variant{
+		"Version":       glib.NewVariantUint32(3),
+		"TextDirection": glib.NewVariantString("ltr"),
+		"Status":        glib.NewVariantString("normal"),
+		"IconThemePath": glib.NewVariantStrv(nil),
+	}
+}
+
+// menuItemProperties returns the properties of the menu item with id
+func (t *tray) menuItemProperties(id int32) map[string]*glib.Variant {
+	switch id {
+	case trayMenuRoot:
+		return map[string]*glib.Variant{"children-display": glib.NewVariantString("submenu")}
+	case trayMenuProgress:
+		return map[string]*glib.Variant{
+			"label":   glib.NewVariantString(t.progress),
+			"enabled": glib.NewVariantBoolean(false),
+		}
+	case trayMenuSeparator:
+		return map[string]*glib.Variant{"type": glib.NewVariantString("separator")}
+	case trayMenuShow:
+		return map[string]*glib.Variant{"label": glib.NewVariantString(tr("Show Fluxxxer"))}
+	case trayMenuQuit:
+		return map[string]*glib.Variant{"label": glib.NewVariantString(tr("Quit"))}
+	}
+	return map[string]*glib.Variant{}
+}
+
+// menuLayout returns the layout of the menu item with id, with the items
+// under it when it is the menu itself
+func (t *tray) menuLayout(id int32) *glib.Variant {
+	var children []*glib.Variant
+	if id == trayMenuRoot {
+		for _, child := range []int32{trayMenuProgress, trayMenuSeparator, trayMenuShow, trayMenuQuit} {
+			children = append(children, glib.NewVariantVariant(t.menuLayout(child)))
+		}
+	}
+	return glib.NewVariantTuple([]*glib.Variant{
+		glib.NewVariantInt32(id),
+		variantDict(t.menuItemProperties(id)),
+		glib.NewVariantArray(glib.NewVariantType("v"), children),
+	})
+}
+
+// menuEvent runs the menu item with id when it was clicked
+func (t *tray) menuEvent(id int32, event string) {
+	if event != "clicked" {
+		return
+	}
+	switch id {
+	case trayMenuShow:
+		t.show()
+	case trayMenuQuit:
+		t.quit()
+	}
+}
+
+// variantDict returns values as an a{sv} dictionary, sorted by key
+func variantDict(values map[string]*glib.Variant) *glib.Variant {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]*glib.Variant, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, glib.NewVariantDictEntry(glib.NewVariantString(key), glib.NewVariantVariant(values[key])))
+	}
+	return glib.NewVariantArray(glib.NewVariantType("{sv}"), entries)
+}
+
+// setupTray shows the tray icon if the setting is on. While it is shown,
+// closing the window hides it and generations carry on in the background.
+func (a *App) setupTray() {
+	a.win.ConnectCloseRequest(func() bool {
+		if a.tray == nil {
+			return false
+		}
+		a.win.SetVisible(false)
+		return true
+	})
+
+	if a.settings.TrayIcon {
+		a.startTray()
+	}
+}
+
+// addTrayAction adds the toggle for the tray icon, saved with the settings
+func (a *App) addTrayAction() {
+	action := gio.NewSimpleActionStateful("tray-icon", nil, glib.NewVariantBoolean(a.settings.TrayIcon))
+	action.ConnectActivate(func(*glib.Variant) {
+		a.settings.TrayIcon = !a.settings.TrayIcon
+		action.SetState(glib.NewVariantBoolean(a.settings.TrayIcon))
+		a.saveSettings()
+
+		if a.settings.TrayIcon {
+			a.startTray()
+		} else {
+			a.stopTray()
+			a.setStatus(tr("Tray icon removed, closing the window quits"))
+		}
+	})
+	a.win.AddAction(action)
+}
+
+// startTray registers the tray icon with the desktop. Desktops without a
+// tray leave the window closing as usual.
+func (a *App) startTray() {
+	if a.tray != nil {
+		return
+	}
+	conn, err := gio.BusGetSync(context.Background(), gio.BusTypeSession)
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: no session bus to show the tray icon on: %v"), err))
+		return
+	}
+
+	var t *tray
+	t, err = newTray(conn, a.queueProgress(), a.showWindow, a.Quit, func() {
+		if a.tray == t {
+			a.stopTray()
+			a.setStatus(tr("Error: the tray icon lost its bus name"))
+		}
+	})
+	if err != nil {
+		a.setStatus(fmt.Sprintf(tr("Error: no system tray found: %v"), err))
+		return
+	}
+	t.register(func(err error) {
+		if err != nil {
+			t.close()
+			a.setStatus(fmt.Sprintf(tr("Error: no system tray found: %v"), err))
+			return
+		}
+		// The setting may have been turned off while registering
+		if !a.settings.TrayIcon || a.tray != nil {
+			t.close()
+			return
+		}
+		a.tray = t
+		a.setStatus(tr("Tray icon shown, closing the window keeps generating in the background"))
+	})
+}
+
+// stopTray removes the tray icon
+func (a *App) stopTray() {
+	if a.tray == nil {
+		return
+	}
+	a.tray.close()
+	a.tray = nil
+}
+
+// showWindow brings back the window hidden to the tray, or raises it
+func (a *App) showWindow() {
+	a.win.SetVisible(true)
+	a.win.Present()
+}
+
+// updateTray shows the queue progress on the tray icon
+func (a *App) updateTray() {
+	if a.tray != nil {
+		a.tray.setProgress(a.queueProgress())
+	}
+}
+
+// queueProgress describes how far the queue has come
+func (a *App) queueProgress() string {
+	var finished, pending int
+	for _, job := range a.queue {
+		switch job.status {
+		case jobQueued, jobRunning:
+			pending++
+		case jobDone, jobFailed:
+			finished++
+		}
+	}
+
+	if pending == 0 {
+		return tr("No generations pending")
+	}
+	return fmt.Sprintf(tr("%d of %d generations finished"), finished, finished+pending)
+}
@@ -1,11 +1,11 @@
 package app
 
 import (
+	"fluxxxer/internal/config"
+
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
-	
-	// Import gio with underscore to use in file methods but avoid unused import error
-	_ "github.com/diamondburned/gotk4/pkg/gio/v2"
 )
 
 // setupUI initializes the application UI
@@ -14,6 +14,7 @@ func (a *App) setupUI() {
 	a.win = gtk.NewApplicationWindow(a.Application)
 	a.win.SetTitle("Fluxxxer")
 	a.win.SetDefaultSize(a.config.GetWindowWidth(), a.config.GetWindowHeight())
+	a.applyUIScale()
 
 	// Create main container with margins
 	mainBox := gtk.NewBox(gtk.OrientationVertical, 10)
@@ -26,28 +27,58 @@ func (a *App) setupUI() {
 	headerBox := a.createHeaderArea()
 	mainBox.Append(headerBox)
 
-	// Create a stack to switch between generator and upscaler modes
-	stack := gtk.NewStack()
-	stack.SetTransitionType(gtk.StackTransitionTypeCrossfade)
-	stack.SetTransitionDuration(200)
+	// Errors and warnings are shown above the results, where they are hard
+	// to miss, as well as in the status bar
+	mainBox.Append(a.createBanner())
+
+	// Create a stack to switch between generator, upscaler and gallery modes
+	a.stack = gtk.NewStack()
+	a.stack.SetTransitionType(gtk.StackTransitionTypeCrossfade)
+	a.stack.SetTransitionDuration(200)
 	
 	// Generator view (image display area)
 	generatorView := a.createGeneratorView()
-	stack.AddTitled(generatorView, "generator", "Generator")
+	a.stack.AddTitled(generatorView, modeGenerator, tr("Generator"))
 	
 	// Upscaler view
 	upscalerView := a.createUpscalerView()
-	stack.AddTitled(upscalerView, "upscaler", "Upscaler")
+	a.stack.AddTitled(upscalerView, modeUpscaler, tr("Upscaler"))
+	
+	// Gallery view
+	galleryView := a.createGalleryView()
+	a.stack.AddTitled(galleryView, modeGallery, tr("Gallery"))
 	
 	// Add stack to main box
-	stack.SetVExpand(true)
-	mainBox.Append(stack)
+	a.stack.SetVExpand(true)
+	mainBox.Append(a.stack)
+	mainBox.Append(a.createRecentStrip())
 	
 	// Create status bar
+	statusBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	statusBox.SetMarginTop(8)
+	
+	// Selectable so messages can be copied into bug reports
 	a.statusBar = gtk.NewLabel("")
 	a.statusBar.SetXAlign(0)
-	a.statusBar.SetMarginTop(8)
-	mainBox.Append(a.statusBar)
+	a.statusBar.SetHExpand(true)
+	a.statusBar.SetWrap(true)
+	a.statusBar.SetSelectable(true)
+	
+	// Shown only while the status is an error
+	a.copyErrorBtn = gtk.NewButtonWithLabel(tr("Copy Error"))
+	a.copyErrorBtn.SetVisible(false)
+	a.copyErrorBtn.ConnectClicked(a.copyStatus)
+	
+	// Shown after saving a file
+	a.revealBtn = gtk.NewButtonWithLabel(tr("Show in Folder"))
+	a.revealBtn.SetTooltipText(tr("Open the file manager at the saved file"))
+	a.revealBtn.SetVisible(false)
+	a.revealBtn.ConnectClicked(a.revealSavedFile)
+	
+	statusBox.Append(a.statusBar)
+	statusBox.Append(a.copyErrorBtn)
+	statusBox.Append(a.revealBtn)
+	mainBox.Append(statusBox)
 
 	// Show the window
 	a.win.SetChild(mainBox)
@@ -55,29 +86,26 @@ func (a *App) setupUI() {
 		// Use configured window width
 		a.currentWidth = a.config.GetWindowWidth()
 		
-		// Set initial mode
-		a.setMode(a.isGeneratorMode)
-		
-		// Update stack based on current mode
-		if a.isGeneratorMode {
-			stack.SetVisibleChildName("generator")
-		} else {
-			stack.SetVisibleChildName("upscaler")
+		// Generation needs a backend
+		if a.config.IsOffline() {
+			a.setGenerationEnabled(false, "Offline mode: set FLUX_API_URL in your .env file to generate images")
 		}
+		a.startHealthChecks()
+		a.resumePending()
+		a.offerSavedQueue()
+		
+		// Set initial mode
+		a.setMode(a.mode)
 	})
 	
-	// Listen for mode changes
-	a.generatorToggle.ConnectToggled(func() {
-		if a.generatorToggle.Active() {
-			stack.SetVisibleChildName("generator")
-		}
-	})
+	// Register actions and keyboard shortcuts
+	a.setupActions()
 	
-	a.upscalerToggle.ConnectToggled(func() {
-		if a.upscalerToggle.Active() {
-			stack.SetVisibleChildName("upscaler")
-		}
-	})
+	// Keep running in the tray when the window is closed, if turned on
+	a.setupTray()
+	
+	// Switch to the compact layout on narrow windows
+	a.setupCompactMode()
 	
 	// Setup simple drop to handle files for the upscaler
 	a.setupFileDrop(upscalerView)
@@ -95,31 +123,55 @@ func (a *App) createHeaderArea() *gtk.Box {
 	
 	// Prompt entry field
 	a.entry = gtk.NewEntry()
-	a.entry.SetPlaceholderText("Enter your prompt...")
+	a.entry.SetPlaceholderText(tr("Enter your prompt..."))
 	a.entry.SetHExpand(true)
 	a.entry.SetMarginEnd(8)
 	a.entry.ConnectActivate(a.onGenerateClicked)
 	
+	// Multi-line editor for long prompts, toggled by the Expand button
+	promptEditor := a.createPromptEditor()
+	
+	// Fill the prompt with a random idea
+	surpriseBtn := gtk.NewButtonWithLabel(tr("Surprise Me"))
+	surpriseBtn.SetTooltipText(tr("Fill in a random prompt. Edit wordbanks.json in the config directory to change the words."))
+	surpriseBtn.ConnectClicked(a.surprisePrompt)
+	
 	// Generate button
-	generateBtn := gtk.NewButtonWithLabel("Generate")
+	a.generateBtn = gtk.NewButtonWithLabel(tr("Generate"))
 	// generateBtn.AddCSSClass("suggested-action") - Not available in this version
-	generateBtn.ConnectClicked(a.onGenerateClicked)
+	a.generateBtn.ConnectClicked(a.onGenerateClicked)
+	
+	// Rerun the last generation with the same seed
+	duplicateBtn := gtk.NewButtonWithLabel(tr("Duplicate Last"))
+	duplicateBtn.SetTooltipText(tr("Run the last generation again with the same seed and settings (Ctrl+D)"))
+	duplicateBtn.SetActionName("win.duplicate-last")
 	
 	// Spinner for loading state
 	a.spinner = gtk.NewSpinner()
 	a.spinner.SetMarginStart(8)
+	a.progressBar = createProgressBar()
 	
 	// Add elements to input box
 	inputBox.Append(a.entry)
-	inputBox.Append(generateBtn)
+	inputBox.Append(a.createFavoriteButton())
+	inputBox.Append(a.createExpandPromptButton())
+	inputBox.Append(surpriseBtn)
+	inputBox.Append(a.generateBtn)
+	inputBox.Append(duplicateBtn)
 	inputBox.Append(a.spinner)
+	inputBox.Append(a.progressBar)
+	inputBox.Append(a.createHealthIndicator())
+	inputBox.Append(a.createBaseImageMenu())
+	inputBox.Append(a.createAdvancedMenu())
+	inputBox.Append(a.createPresetsMenu())
+	inputBox.Append(a.createVariablesMenu())
+	inputBox.Append(a.createAppMenu())
 	
 	// Create options area (aspect ratio, number of outputs, etc.)
 	optionsBox := gtk.NewBox(gtk.OrientationHorizontal, 16)
-	optionsBox.SetMarginTop(8)
 	
 	// Create aspect ratio dropdown
-	aspectLabel := gtk.NewLabel("Aspect Ratio:")
+	aspectLabel := gtk.NewLabel(tr("Aspect Ratio:"))
 	aspectLabel.SetMarginEnd(4)
 	
 	// Create and store reference to aspect ratio dropdown
@@ -136,29 +188,76 @@ func (a *App) createHeaderArea() *gtk.Box {
 	}
 	
 	// Number of outputs slider
-	numOutputsLabel := gtk.NewLabel("Images:")
+	numOutputsLabel := gtk.NewLabel(tr("Images:"))
 	numOutputsLabel.SetMarginStart(16)
 	numOutputsLabel.SetMarginEnd(4)
 	
 	// Create and store reference to outputs scale
+	maxOutputs := a.config.GetMaxOutputs()
 	numOutputsScale = gtk.NewScale(gtk.OrientationHorizontal, gtk.NewAdjustment(
-		float64(a.config.GetDefaultNumOutputs()), // value
-		1,                                        // min
-		8,                                        // max
-		1,                                        // step
-		0,                                        // page increment
-		0,                                        // page size
+		float64(min(a.config.GetDefaultNumOutputs(), maxOutputs)), // value
+		1,                                                          // min
+		float64(maxOutputs),                                        // max
+		1,                                                          // step
+		0,                                                          // page increment
+		0,                                                          // page size
 	))
 	numOutputsScale.SetDrawValue(true)
 	numOutputsScale.SetHExpand(false)
 	numOutputsScale.SetSizeRequest(120, -1)
 	numOutputsScale.SetDigits(0)
 	
+	// Append toggle keeps previous results and adds new batches after them
+	a.appendToggle = gtk.NewCheckButtonWithLabel(tr("Append"))
+	a.appendToggle.SetMarginStart(16)
+	a.appendToggle.SetTooltipText(tr("Add new images to the existing results instead of replacing them"))
+	
+	// Numbers shown on each result frame
+	a.numbersToggle = gtk.NewCheckButtonWithLabel(tr("Numbers"))
+	a.numbersToggle.SetMarginStart(16)
+	a.numbersToggle.SetTooltipText(tr("Label each image with its number in the batch"))
+	a.numbersToggle.ConnectToggled(func() {
+		for _, label := range a.imageNumbers {
+			label.SetVisible(a.numbersToggle.Active())
+		}
+	})
+	
+	// Images per row in the results
+	perRowLabel := gtk.NewLabel(tr("Per Row:"))
+	perRowLabel.SetMarginStart(16)
+	perRowLabel.SetMarginEnd(4)
+	
+	// Parameter sweep runs one generation per value
+	sweepLabel := gtk.NewLabel(tr("Sweep:"))
+	sweepLabel.SetMarginEnd(4)
+	
+	a.sweepCombo = gtk.NewDropDown(nil, nil)
+	a.sweepCombo.SetModel(gtk.NewStringList(trAll(sweepParameters)))
+	
+	a.sweepEntry = gtk.NewEntry()
+	a.sweepEntry.SetPlaceholderText(tr("Values, e.g. 1,42,100"))
+	a.sweepEntry.SetTooltipText(tr("Comma-separated seeds or aspect ratios. Leave empty to sweep all aspect ratios. Seed per Image gives each image the next seed, random once the list runs out."))
+	a.sweepEntry.SetSensitive(false)
+	a.sweepCombo.NotifyProperty("selected", func() {
+		a.sweepEntry.SetSensitive(a.selectedSweepParameter() != sweepNone)
+	})
+	
 	// Add options elements
 	optionsBox.Append(aspectLabel)
 	optionsBox.Append(aspectRatioCombo)
 	optionsBox.Append(numOutputsLabel)
 	optionsBox.Append(numOutputsScale)
+	optionsBox.Append(a.appendToggle)
+	optionsBox.Append(a.numbersToggle)
+	optionsBox.Append(perRowLabel)
+	optionsBox.Append(a.createResultsPerRowDropDown())
+	optionsBox.Append(a.createKeepIndicator())
+	
+	// Sweep controls form their own group so compact mode can wrap them
+	sweepBox := gtk.NewBox(gtk.OrientationHorizontal, 16)
+	sweepBox.Append(sweepLabel)
+	sweepBox.Append(a.sweepCombo)
+	sweepBox.Append(a.sweepEntry)
 	
 	// Mode switcher section for switching between generator and upscaler
 	modeBox := gtk.NewBox(gtk.OrientationHorizontal, 4)
@@ -167,52 +266,137 @@ func (a *App) createHeaderArea() *gtk.Box {
 	
 	// Store toggle buttons for later use
 	a.generatorToggle = gtk.NewToggleButton()
-	a.generatorToggle.SetLabel("Generator")
-	a.generatorToggle.SetActive(a.isGeneratorMode)
+	a.generatorToggle.SetLabel(tr("Generator"))
+	a.generatorToggle.SetActive(a.mode == modeGenerator)
 	
 	a.upscalerToggle = gtk.NewToggleButton()
-	a.upscalerToggle.SetLabel("Upscaler")
-	a.upscalerToggle.SetActive(!a.isGeneratorMode)
+	a.upscalerToggle.SetLabel(tr("Upscaler"))
+	a.upscalerToggle.SetActive(a.mode == modeUpscaler)
+	
+	a.galleryToggle = gtk.NewToggleButton()
+	a.galleryToggle.SetLabel(tr("Gallery"))
+	a.galleryToggle.SetActive(a.mode == modeGallery)
 	
 	// Disable upscaler button if not configured
 	if !a.isUpscalerConfigured() {
 		a.upscalerToggle.SetSensitive(false)
-		a.upscalerToggle.SetTooltipText("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.")
+		a.upscalerToggle.SetTooltipText(tr("Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file."))
 	}
 	
 	// Add toggles to mode box
 	modeBox.Append(a.generatorToggle)
 	modeBox.Append(a.upscalerToggle)
+	modeBox.Append(a.galleryToggle)
 	
-	// Connect toggle buttons to form a radio group
-	a.generatorToggle.ConnectToggled(func() {
-		if a.generatorToggle.Active() {
-			a.upscalerToggle.SetActive(false)
-			a.setMode(true)
-		} else if !a.upscalerToggle.Active() {
-			a.generatorToggle.SetActive(true)
-		}
-	})
+	// Group the toggle buttons so only one mode is active at a time
+	a.upscalerToggle.SetGroup(a.generatorToggle)
+	a.galleryToggle.SetGroup(a.generatorToggle)
 	
-	a.upscalerToggle.ConnectToggled(func() {
-		if a.upscalerToggle.Active() {
-			a.generatorToggle.SetActive(false)
-			a.setMode(false)
-		} else if !a.generatorToggle.Active() {
-			a.upscalerToggle.SetActive(true)
-		}
-	})
+	modeToggles := map[string]*gtk.ToggleButton{
+		modeGenerator: a.generatorToggle,
+		modeUpscaler:  a.upscalerToggle,
+		modeGallery:   a.galleryToggle,
+	}
+	for mode, toggle := range modeToggles {
+		toggle.ConnectToggled(func() {
+			if toggle.Active() && a.mode != mode {
+				a.setMode(mode)
+			}
+		})
+	}
 	
-	// Add the mode switcher to the options box
-	optionsBox.Append(modeBox)
+	// Lay the option groups out in a row, stacked in compact mode
+	a.optionsRow = gtk.NewBox(gtk.OrientationHorizontal, 16)
+	a.optionsRow.SetMarginTop(8)
+	a.optionsRow.Append(optionsBox)
+	a.optionsRow.Append(sweepBox)
+	a.optionsRow.Append(modeBox)
 	
-	// Add both rows to the header
+	// Add both rows and the queue panel to the header
+	headerBox.Append(a.createFavoritesBar())
 	headerBox.Append(inputBox)
-	headerBox.Append(optionsBox)
+	headerBox.Append(promptEditor)
+	headerBox.Append(a.createSafetyBar())
+	headerBox.Append(a.optionsRow)
+	headerBox.Append(a.createQueuePanel())
+	
+	// Show the estimated cost on the Generate button for paid backends
+	a.setupCostEstimate()
 	
 	return headerBox
 }
 
+// createAppMenu creates the menu button holding less frequently used actions
+func (a *App) createAppMenu() *gtk.MenuButton {
+	menu := gio.NewMenu()
+	menu.Append(tr("Command Palette"), "win.show-command-palette")
+	menu.Append(tr("Generate Several Prompts..."), "win.generate-prompts")
+	menu.Append(tr("Reset to Defaults"), "win.reset-controls")
+	menu.Append(tr("Stop All"), "win.stop-all")
+	menu.Append(tr("Pin or Unpin Prompt"), "win.toggle-favorite")
+	menu.Append(tr("Copy Request JSON"), "win.copy-request-json")
+	menu.Append(tr("Show Last Response"), "win.show-last-response")
+	menu.Append(tr("Dry Run"), "win.dry-run")
+	menu.Append(tr("API Headers..."), "win.edit-api-headers")
+	menu.Append(tr("Export Session..."), "win.export-session")
+	menu.Append(tr("Import Session..."), "win.import-session")
+	menu.Append(tr("Statistics"), "win.show-stats")
+	menu.Append(tr("Compare with Previous Batch"), "win.compare-previous")
+	menu.Append(tr("Open Config Folder"), "win.open-config-folder")
+	menu.Append(tr("Open Cache Folder"), "win.open-cache-folder")
+	menu.Append(tr("Keyboard Shortcuts"), "win.show-help-overlay")
+	
+	// Radio choices of what happens to the prompt after generating
+	promptMenu := gio.NewMenu()
+	promptMenu.Append(tr("Leave As Is"), "win.prompt-after-generate::"+config.PromptLeave)
+	promptMenu.Append(tr("Clear"), "win.prompt-after-generate::"+config.PromptClear)
+	promptMenu.Append(tr("Select All"), "win.prompt-after-generate::"+config.PromptSelect)
+	menu.AppendSubmenu(tr("Prompt After Generating"), promptMenu)
+	
+	// Radio choices of what happens when saving over an existing file
+	existingMenu := gio.NewMenu()
+	existingMenu.Append(tr("Ask Before Replacing"), "win.existing-files::"+config.ExistingAsk)
+	existingMenu.Append(tr("Save with a Number"), "win.existing-files::"+config.ExistingRename)
+	menu.AppendSubmenu(tr("When a File Exists"), existingMenu)
+	
+	// Formats saved beside each image in addition to its own
+	menu.AppendSubmenu(tr("Also Save As"), a.addCopyFormatActions())
+	menu.AppendSubmenu(tr("PNG Compression"), createPNGCompressionMenu())
+	
+	// Keeps everything without clicking Save
+	autoSaveSection := gio.NewMenu()
+	autoSaveSection.Append(tr("Auto-save All Generations"), "win.auto-save")
+	menu.AppendSection("", autoSaveSection)
+	
+	// Trades speed for memory on constrained machines
+	memorySection := gio.NewMenu()
+	memorySection.Append(tr("Low Memory Mode"), "win.low-memory")
+	memorySection.AppendSubmenu(tr("Image Loader"), createImageLoaderMenu())
+	menu.AppendSection(tr("Re-downloads images to save or copy them"), memorySection)
+	
+	scrollSection := gio.NewMenu()
+	scrollSection.Append(tr("Wheel Scrolls Results Sideways"), "win.wheel-scrolls-sideways")
+	scrollSection.AppendSubmenu(tr("UI Scale"), createUIScaleMenu())
+	menu.AppendSection("", scrollSection)
+	
+	// Only backends reporting per-image safety flags are affected
+	flaggedSection := gio.NewMenu()
+	flaggedSection.Append(tr("Blur Sensitive Images"), "win.blur-flagged")
+	menu.AppendSection("", flaggedSection)
+	
+	// Tray support varies by desktop, so the icon is opt-in
+	traySection := gio.NewMenu()
+	traySection.Append(tr("Show Tray Icon"), "win.tray-icon")
+	menu.AppendSection(tr("Closing the window keeps generating in the background"), traySection)
+	
+	menuBtn := gtk.NewMenuButton()
+	menuBtn.SetIconName("open-menu-symbolic")
+	menuBtn.SetTooltipText(tr("More actions"))
+	menuBtn.SetMenuModel(menu)
+	
+	return menuBtn
+}
+
 // createGeneratorView creates the view for the image generator
 func (a *App) createGeneratorView() *gtk.ScrolledWindow {
 	scrollWin := gtk.NewScrolledWindow()
@@ -220,6 +404,8 @@ func (a *App) createGeneratorView() *gtk.ScrolledWindow {
 	scrollWin.SetChild(a.imageBox)
 	scrollWin.SetVExpand(true)
 	scrollWin.SetHExpand(true)
+	a.setupWheelScrolling(scrollWin)
+	a.watchOffscreenResults(scrollWin)
 	
 	return scrollWin
 }
@@ -238,24 +424,23 @@ func (a *App) createUpscalerView() *gtk.Box {
 	placeholderBox.SetVExpand(true)
 	
 	// Add an icon
-	iconTheme := gtk.IconThemeGetForDisplay(gdk.DisplayGetDefault())
-	if iconTheme.HasIcon("document-save") {
+	if display := gdk.DisplayGetDefault(); display != nil && gtk.IconThemeGetForDisplay(display).HasIcon("document-save") {
 		icon := gtk.NewImageFromIconName("document-save")
 		icon.SetPixelSize(64)
 		placeholderBox.Append(icon)
 	}
 	
 	// Add a label
-	dropLabel := gtk.NewLabel("Drag and drop an image here to upscale it")
+	dropLabel := gtk.NewLabel(tr("Drag and drop an image here to upscale it"))
 	// dropLabel.AddCSSClass("title-2") - Not available in this version
 	placeholderBox.Append(dropLabel)
 	
 	// Add instructions
-	infoLabel := gtk.NewLabel("Or click the button below to select an image file")
+	infoLabel := gtk.NewLabel(tr("Or click the button below to select an image file"))
 	placeholderBox.Append(infoLabel)
 	
 	// Add a select file button
-	selectBtn := gtk.NewButtonWithLabel("Select Image")
+	selectBtn := gtk.NewButtonWithLabel(tr("Select Image"))
 	selectBtn.SetHAlign(gtk.AlignCenter)
 	selectBtn.SetMarginTop(16)
 	selectBtn.ConnectClicked(func() {
@@ -264,7 +449,7 @@ func (a *App) createUpscalerView() *gtk.Box {
 	placeholderBox.Append(selectBtn)
 	
 	// Add upscale options
-	optionsFrame := gtk.NewFrame("Upscale Options")
+	optionsFrame := gtk.NewFrame(tr("Upscale Options"))
 	optionsBox := gtk.NewBox(gtk.OrientationVertical, 8)
 	optionsBox.SetMarginTop(16)
 	optionsBox.SetMarginBottom(16)
@@ -273,7 +458,7 @@ func (a *App) createUpscalerView() *gtk.Box {
 	
 	// Upscale type
 	typeBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
-	typeLabel := gtk.NewLabel("Upscale Type:")
+	typeLabel := gtk.NewLabel(tr("Upscale Type:"))
 	typeLabel.SetHAlign(gtk.AlignStart)
 	typeLabel.SetXAlign(0)
 	
@@ -295,12 +480,12 @@ func (a *App) createUpscalerView() *gtk.Box {
 	
 	// Prompt for conservative and creative modes
 	promptBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
-	promptLabel := gtk.NewLabel("Prompt:")
+	promptLabel := gtk.NewLabel(tr("Prompt:"))
 	promptLabel.SetHAlign(gtk.AlignStart)
 	promptLabel.SetXAlign(0)
 	
 	promptEntry := gtk.NewEntry()
-	promptEntry.SetPlaceholderText("Enter a prompt to guide upscaling (for conservative/creative modes)")
+	promptEntry.SetPlaceholderText(tr("Enter a prompt to guide upscaling (for conservative/creative modes)"))
 	promptEntry.SetHExpand(true)
 	
 	promptBox.Append(promptLabel)
@@ -328,11 +513,11 @@ func (a *App) setupFileDrop(widget *gtk.Box) {
 // showFileChooserForUpscale shows a file chooser dialog for upscaling
 func (a *App) showFileChooserForUpscale() {
 	dialog := gtk.NewFileChooserNative(
-		"Select Image to Upscale",
+		tr("Select Image to Upscale"),
 		&a.win.Window,
 		gtk.FileChooserActionOpen,
-		"_Open",
-		"_Cancel",
+		tr("_Open"),
+		tr("_Cancel"),
 	)
 	
 	// Add image filters
@@ -341,7 +526,7 @@ func (a *App) showFileChooserForUpscale() {
 	filter.AddPattern("*.jpg")
 	filter.AddPattern("*.jpeg")
 	filter.AddPattern("*.webp")
-	filter.SetName("Image files")
+	filter.SetName(tr("Image files"))
 	dialog.AddFilter(filter)
 	
 	dialog.ConnectResponse(func(response int) {
@@ -362,4 +547,11 @@ func (a *App) clearImages() {
 	for child := a.imageBox.FirstChild(); child != nil; child = a.imageBox.FirstChild() {
 		a.imageBox.Remove(child)
 	}
+	a.imageNumbers = nil
+	a.resultGrids = nil
+	a.resultSeparators = nil
+	a.results = nil
+	a.focusedResult = nil
+	a.compareFirst = nil
+	a.releaseResultTextures()
 }
\ No newline at end of file
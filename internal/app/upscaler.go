@@ -1,9 +1,8 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,7 +10,6 @@ import (
 	"fluxxxer/internal/upscaler"
 
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
-	"github.com/diamondburned/gotk4/pkg/gio/v2"
 	"github.com/diamondburned/gotk4/pkg/glib/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 )
@@ -19,13 +17,13 @@ import (
 // handleUpscaleFile processes an image file for upscaling
 func (a *App) handleUpscaleFile(filePath string) {
 	if !a.isUpscalerConfigured() {
-		a.setStatus("Upscaler not configured. Please set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.")
+		a.setStatus(tr("Upscaler not configured. Please set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file."))
 		return
 	}
 
 	// Check if file exists and is an image
 	if !isImageFile(filePath) {
-		a.setStatus(fmt.Sprintf("File is not a supported image format: %s", filePath))
+		a.setStatus(fmt.Sprintf(tr("File is not a supported image format: %s"), filePath))
 		return
 	}
 
@@ -37,7 +35,7 @@ func (a *App) handleUpscaleFile(filePath string) {
 func (a *App) showUpscaleConfirmDialog(imagePath string) {
 	// Create dialog
 	dialog := gtk.NewDialog()
-	dialog.SetTitle("Upscale Image")
+	dialog.SetTitle(tr("Upscale Image"))
 	dialog.SetTransientFor(&a.win.Window)
 	dialog.SetModal(true)
 	dialog.SetDefaultSize(600, 400)
@@ -56,7 +54,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 	previewBox.SetHExpand(true)
 
 	// Add a preview label
-	previewLabel := gtk.NewLabel("Image Preview")
+	previewLabel := gtk.NewLabel(tr("Image Preview"))
 	// previewLabel.AddCSSClass("title-3") - Not available in this version
 	previewBox.Append(previewLabel)
 
@@ -68,7 +66,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 	// Load and display the image preview
 	texture, err := loadTextureFromFile(imagePath)
 	if err != nil {
-		errorLabel := gtk.NewLabel(fmt.Sprintf("Error loading image: %v", err))
+		errorLabel := gtk.NewLabel(fmt.Sprintf(tr("Error loading image: %v"), err))
 		imageFrame.SetChild(errorLabel)
 	} else {
 		picture := gtk.NewPicture()
@@ -88,7 +86,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 
 	// Upscale type
 	typeBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
-	typeLabel := gtk.NewLabel("Upscale Type:")
+	typeLabel := gtk.NewLabel(tr("Upscale Type:"))
 	typeLabel.SetHAlign(gtk.AlignStart)
 	typeLabel.SetXAlign(0)
 	typeLabel.SetWidthChars(12)
@@ -111,13 +109,13 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 
 	// Prompt for conservative and creative modes
 	promptBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
-	promptLabel := gtk.NewLabel("Prompt:")
+	promptLabel := gtk.NewLabel(tr("Prompt:"))
 	promptLabel.SetHAlign(gtk.AlignStart)
 	promptLabel.SetXAlign(0)
 	promptLabel.SetWidthChars(12)
 
 	promptEntry := gtk.NewEntry()
-	promptEntry.SetPlaceholderText("Enter a prompt to guide upscaling (for conservative/creative modes)")
+	promptEntry.SetPlaceholderText(tr("Enter a prompt to guide upscaling (for conservative/creative modes)"))
 	promptEntry.SetHExpand(true)
 
 	promptBox.Append(promptLabel)
@@ -125,7 +123,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 
 	// Output format
 	formatBox := gtk.NewBox(gtk.OrientationHorizontal, 8)
-	formatLabel := gtk.NewLabel("Output Format:")
+	formatLabel := gtk.NewLabel(tr("Output Format:"))
 	formatLabel.SetHAlign(gtk.AlignStart)
 	formatLabel.SetXAlign(0)
 	formatLabel.SetWidthChars(12)
@@ -151,7 +149,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 	spinnerBox.SetMarginTop(16)
 	
 	spinner := gtk.NewSpinner()
-	spinnerLabel := gtk.NewLabel("Upscaling image...")
+	spinnerLabel := gtk.NewLabel(tr("Upscaling image..."))
 	
 	spinnerBox.Append(spinner)
 	spinnerBox.Append(spinnerLabel)
@@ -165,8 +163,8 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 	contentArea.Append(spinnerBox)
 
 	// Add buttons to the dialog
-	dialog.AddButton("Cancel", int(gtk.ResponseCancel))
-	dialog.AddButton("Upscale", int(gtk.ResponseAccept))
+	dialog.AddButton(tr("Cancel"), int(gtk.ResponseCancel))
+	dialog.AddButton(tr("Upscale"), int(gtk.ResponseAccept))
 	// Note: Unable to style the button in this version
 
 	// Connect response handler
@@ -186,7 +184,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 			fileInfo, err := os.Stat(imagePath)
 			if err == nil && fileInfo.Size() > 5*1024*1024 {
 				// Display a warning that the image is large and might cause OOM
-				a.setStatus(fmt.Sprintf("Warning: Image is large (%d MB). Server may run out of memory.", 
+				a.setStatus(fmt.Sprintf(tr("Warning: Image is large (%d MB). Server may run out of memory."), 
 					fileInfo.Size()/(1024*1024)))
 			}
 			
@@ -233,7 +231,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 						// Load image from the temporary file
 						texture, err := loadTextureFromFile(result.URL)
 						if err != nil {
-							a.setStatus(fmt.Sprintf("Error loading upscaled image: %v", err))
+							a.setStatus(fmt.Sprintf(tr("Error loading upscaled image: %v"), err))
 							dialog.Destroy()
 							return
 						}
@@ -247,7 +245,7 @@ func (a *App) showUpscaleConfirmDialog(imagePath string) {
 						a.handleUpscaledImage(result, filepath.Base(imagePath))
 						dialog.Destroy()
 					} else {
-						a.setStatus("Error: No upscaled image URL returned")
+						a.setStatus(tr("Error: No upscaled image URL returned"))
 						dialog.Destroy()
 					}
 				})
@@ -288,14 +286,14 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 	// Check if the URL is already a local file (direct binary response handling)
 	if strings.HasPrefix(result.URL, "/tmp/upscaled-") {
 		fmt.Println("Image is already local at:", result.URL)
-		a.setStatus("Loading upscaled image...")
+		a.setStatus(tr("Loading upscaled image..."))
 		
 		go func() {
 			// Load the image for display
 			texture, err := loadTextureFromFile(result.URL)
 			if err != nil {
 				glib.IdleAdd(func() {
-					a.setStatus(fmt.Sprintf("Error loading upscaled image: %v", err))
+					a.setStatus(fmt.Sprintf(tr("Error loading upscaled image: %v"), err))
 				})
 				return
 			}
@@ -309,7 +307,7 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 	}
 
 	// Otherwise download from URL
-	a.setStatus("Downloading upscaled image...")
+	a.setStatus(tr("Downloading upscaled image..."))
 	
 	go func() {
 		// Create a temporary file
@@ -321,7 +319,7 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 		tmpFile, err := os.CreateTemp("", "upscaled-*"+ext)
 		if err != nil {
 			glib.IdleAdd(func() {
-				a.setStatus(fmt.Sprintf("Error creating temporary file: %v", err))
+				a.setStatus(fmt.Sprintf(tr("Error creating temporary file: %v"), err))
 			})
 			return
 		}
@@ -331,27 +329,19 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 		
 		// Download the image
 		fmt.Println("Downloading from URL:", result.URL)
-		resp, err := http.Get(result.URL)
+		data, _, err := a.client.Download(context.Background(), result.URL)
 		if err != nil {
 			glib.IdleAdd(func() {
-				a.setStatus(fmt.Sprintf("Error downloading upscaled image: %v", err))
-			})
-			return
-		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
-			glib.IdleAdd(func() {
-				a.setStatus(fmt.Sprintf("Error downloading upscaled image: status code %d", resp.StatusCode))
+				a.setStatus(fmt.Sprintf(tr("Error downloading upscaled image: %v"), err))
 			})
 			return
 		}
 		
 		// Save the image to the temporary file
-		_, err = io.Copy(tmpFile, resp.Body)
+		_, err = tmpFile.Write(data)
 		if err != nil {
 			glib.IdleAdd(func() {
-				a.setStatus(fmt.Sprintf("Error saving upscaled image: %v", err))
+				a.setStatus(fmt.Sprintf(tr("Error saving upscaled image: %v"), err))
 			})
 			return
 		}
@@ -363,7 +353,7 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 		texture, err := loadTextureFromFile(tmpPath)
 		if err != nil {
 			glib.IdleAdd(func() {
-				a.setStatus(fmt.Sprintf("Error loading upscaled image: %v", err))
+				a.setStatus(fmt.Sprintf(tr("Error loading upscaled image: %v"), err))
 			})
 			return
 		}
@@ -379,7 +369,7 @@ func (a *App) handleUpscaledImage(result *upscaler.UpscaleResult, originalName s
 func (a *App) showUpscaledImageDialog(texture *gdk.Texture, tmpPath, originalName string) {
 	// Create dialog
 	dialog := gtk.NewDialog()
-	dialog.SetTitle("Upscaled Image")
+	dialog.SetTitle(tr("Upscaled Image"))
 	dialog.SetTransientFor(&a.win.Window)
 	dialog.SetModal(true)
 	dialog.SetDefaultSize(800, 600)
@@ -397,7 +387,7 @@ func (a *App) showUpscaledImageDialog(texture *gdk.Texture, tmpPath, originalNam
 	mainBox.SetHExpand(true)
 	
 	// Add a label
-	titleLabel := gtk.NewLabel("Upscaled Image")
+	titleLabel := gtk.NewLabel(tr("Upscaled Image"))
 	// titleLabel.AddCSSClass("title-2") - Not available in this version
 	mainBox.Append(titleLabel)
 	
@@ -423,20 +413,20 @@ func (a *App) showUpscaledImageDialog(texture *gdk.Texture, tmpPath, originalNam
 	buttonBox.SetMarginTop(16)
 	
 	// Add save button
-	saveBtn := gtk.NewButtonWithLabel("Save As...")
+	saveBtn := gtk.NewButtonWithLabel(tr("Save As..."))
 	saveBtn.ConnectClicked(func() {
 		a.saveUpscaledImage(tmpPath, originalName)
 	})
 	
 	// Add copy button
-	copyBtn := gtk.NewButtonWithLabel("Copy to Clipboard")
+	copyBtn := gtk.NewButtonWithLabel(tr("Copy to Clipboard"))
 	copyBtn.ConnectClicked(func() {
 		a.copyImageToClipboard(texture)
-		a.setStatus("Upscaled image copied to clipboard")
+		a.setStatus(tr("Upscaled image copied to clipboard"))
 	})
 	
 	// Add close button
-	closeBtn := gtk.NewButtonWithLabel("Close")
+	closeBtn := gtk.NewButtonWithLabel(tr("Close"))
 	closeBtn.ConnectClicked(func() {
 		dialog.Destroy()
 	})
@@ -460,7 +450,7 @@ func (a *App) showUpscaledImageDialog(texture *gdk.Texture, tmpPath, originalNam
 	})
 	
 	// Update status
-	a.setStatus("Upscaling completed successfully")
+	a.setStatus(tr("Upscaling completed successfully"))
 	
 	// Show the dialog
 	dialog.Show()
@@ -470,67 +460,54 @@ func (a *App) showUpscaledImageDialog(texture *gdk.Texture, tmpPath, originalNam
 func (a *App) saveUpscaledImage(sourcePath, originalName string) {
 	// Create file chooser dialog
 	dialog := gtk.NewFileChooserNative(
-		"Save Upscaled Image",
+		tr("Save Upscaled Image"),
 		&a.win.Window,
 		gtk.FileChooserActionSave,
-		"_Save",
-		"_Cancel",
+		tr("_Save"),
+		tr("_Cancel"),
 	)
 	
+	// Name the file after the format the upscaler actually returned
+	format := detectFileFormat(sourcePath)
+	
 	// Set default name with "upscaled_" prefix
 	baseName := fmt.Sprintf("upscaled_%s", originalName)
-	dialog.SetCurrentName(baseName)
+	dialog.SetCurrentName(withFormatExt(baseName, format))
 	
 	// Add filters for image types
-	filter := gtk.NewFileFilter()
-	filter.AddPattern("*.png")
-	filter.AddPattern("*.jpg")
-	filter.AddPattern("*.jpeg")
-	filter.AddPattern("*.webp")
-	filter.SetName("Image files")
-	dialog.AddFilter(filter)
+	addImageFilters(dialog, format)
 	
-	// Try to use the Pictures directory
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		picturesDir := filepath.Join(homeDir, "Pictures")
-		if _, err := os.Stat(picturesDir); err == nil {
-			gfile := gio.NewFileForPath(picturesDir)
-			dialog.SetCurrentFolder(gfile)
-		}
-	}
+	// Try to use the output directory
+	a.setDefaultSaveFolder(dialog)
 	
 	// Connect response handler
 	dialog.ConnectResponse(func(response int) {
 		if response == int(gtk.ResponseAccept) {
 			file := dialog.File()
 			if file == nil {
-				a.setStatus("Error: No file selected")
+				a.setStatus(tr("Error: No file selected"))
 				dialog.Destroy()
 				return
 			}
 			
-			destPath := file.Path()
+			chosen := file.Path()
 			
-			// Ensure the file has the correct extension
-			if !strings.HasSuffix(strings.ToLower(destPath), ".png") &&
-			   !strings.HasSuffix(strings.ToLower(destPath), ".jpg") &&
-			   !strings.HasSuffix(strings.ToLower(destPath), ".jpeg") &&
-			   !strings.HasSuffix(strings.ToLower(destPath), ".webp") {
-				destPath += ".png"
-			}
+			// Keep an explicit image extension, otherwise use the detected format
+			destPath := withDetectedExt(chosen, format)
 			
 			// Copy the file
-			go func() {
-				err := copyFile(sourcePath, destPath)
-				glib.IdleAdd(func() {
-					if err != nil {
-						a.setStatus(fmt.Sprintf("Error saving upscaled image: %v", err))
-					} else {
-						a.setStatus(fmt.Sprintf("Upscaled image saved to: %s", destPath))
-					}
-				})
-			}()
+			a.checkExistingFile(destPath, chosen, nil, func(destPath string) {
+				go func() {
+					err := copyFile(sourcePath, destPath)
+					glib.IdleAdd(func() {
+						if err != nil {
+							a.setStatus(fmt.Sprintf(tr("Error saving upscaled image: %v"), err))
+						} else {
+							a.setSavedStatus(fmt.Sprintf(tr("Upscaled image saved to: %s"), destPath), destPath)
+						}
+					})
+				}()
+			})
 		}
 		
 		dialog.Destroy()
@@ -562,7 +539,7 @@ func loadTextureFromFile(path string) (*gdk.Texture, error) {
 	}
 	
 	// Create texture
-	texture, err := gdk.NewTextureFromBytes(glib.NewBytesWithGo(data))
+	texture, err := newTexture(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create texture: %w", err)
 	}
@@ -572,45 +549,11 @@ func loadTextureFromFile(path string) (*gdk.Texture, error) {
 
 // copyFile copies a file from src to dst
 func copyFile(src, dst string) error {
-	// Create parent directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Open source file
 	in, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer in.Close()
 
-	// Create a temp file in the destination directory
-	tmpFile, err := os.CreateTemp(filepath.Dir(dst), "*.tmp")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-
-	// Cleanup in case of error
-	defer func() {
-		tmpFile.Close()
-		os.Remove(tmpPath)
-	}()
-
-	// Copy the data
-	if _, err := io.Copy(tmpFile, in); err != nil {
-		return fmt.Errorf("failed to copy data: %w", err)
-	}
-
-	// Close the file to ensure all data is written
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("failed to close temporary file: %w", err)
-	}
-
-	// Rename the temp file to the destination path
-	if err := os.Rename(tmpPath, dst); err != nil {
-		return fmt.Errorf("failed to rename temporary file: %w", err)
-	}
-
-	return nil
+	return writeFileAtomic(dst, in)
 }
\ No newline at end of file
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/config"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// variableRef matches a variable reference such as $subject, or $$ for a
+// literal dollar sign
+var variableRef = regexp.MustCompile(`\$(\$|[A-Za-z_][A-Za-z0-9_]*)`)
+
+// variableDefinition matches a line defining a variable: $name = value,
+// with the value optionally quoted
+var variableDefinition = regexp.MustCompile(`^\$?([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// builtinVariables are available in every prompt unless a variable of the
+// same name is defined
+var builtinVariables = map[string]func() string{
+	"date":   func() string { return time.Now().Format("2006-01-02") },
+	"time":   func() string { return time.Now().Format("15:04") },
+	"random": func() string { return strconv.Itoa(rand.IntN(1000000)) },
+}
+
+// createVariablesMenu creates the menu button for editing the variables
+// prompts can refer to
+func (a *App) createVariablesMenu() *gtk.MenuButton {
+	variables, err := config.LoadVariables(a.config.GetConfigDir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading variables: %v\n", err)
+	}
+	a.variables = variables
+
+	panelBox := gtk.NewBox(gtk.OrientationVertical, 8)
+	panelBox.SetMarginTop(8)
+	panelBox.SetMarginBottom(8)
+	panelBox.SetMarginStart(8)
+	panelBox.SetMarginEnd(8)
+
+	hint := gtk.NewLabel(`One per line, like $subject = "a red fox". Use them in prompts as $subject. Built in: $date, $time, $random.`)
+	hint.SetWrap(true)
+	hint.SetMaxWidthChars(40)
+	hint.SetXAlign(0)
+
+	textView := gtk.NewTextView()
+	textView.SetMonospace(true)
+	textView.Buffer().SetText(formatVariables(a.variables))
+
+	scrollWin := gtk.NewScrolledWindow()
+	scrollWin.SetChild(textView)
+	scrollWin.SetSizeRequest(360, 160)
+
+	errorLabel := gtk.NewLabel("")
+	errorLabel.SetWrap(true)
+	errorLabel.SetXAlign(0)
+	errorLabel.AddCSSClass("error")
+
+	// Variables apply as soon as the text is valid
+	buffer := textView.Buffer()
+	buffer.ConnectChanged(func() {
+		start, end := buffer.Bounds()
+		variables, err := parseVariables(buffer.Text(start, end, false))
+		if err != nil {
+			errorLabel.SetText(err.Error())
+			return
+		}
+		errorLabel.SetText("")
+		a.variables = variables
+	})
+
+	panelBox.Append(hint)
+	panelBox.Append(scrollWin)
+	panelBox.Append(errorLabel)
+
+	popover := gtk.NewPopover()
+	popover.SetChild(panelBox)
+	popover.ConnectClosed(func() {
+		if err := config.SaveVariables(a.config.GetConfigDir(), a.variables); err != nil {
+			a.setStatus(fmt.Sprintf(tr("Error saving variables: %v"), err))
+		}
+	})
+
+	menuBtn := gtk.NewMenuButton()
+	menuBtn.SetLabel(tr("Variables"))
+	menuBtn.SetTooltipText(tr("Define $variables to use in prompts"))
+	menuBtn.SetPopover(popover)
+
+	return menuBtn
+}
+
+// parseVariables reads variable definitions, one per line. Blank lines and
+// lines starting with # are ignored.
+func parseVariables(text string) ([]config.Variable, error) {
+	var variables []config.Variable
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m := variableDefinition.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected $name = value", i+1)
+		}
+		value := strings.TrimSpace(m[2])
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		variables = append(variables, config.Variable{Name: m[1], Value: value})
+	}
+	return variables, nil
+}
+
+// formatVariables writes variables in the form parseVariables reads
+func formatVariables(variables []config.Variable) string {
+	var b strings.Builder
+	for _, v := range variables {
+		fmt.Fprintf(&b, "$%s = %s\n", v.Name, strconv.Quote(v.Value))
+	}
+	return b.String()
+}
+
+// expandVariables replaces the variable references in prompt with their
+// values. It fails listing the references that are not defined.
+func expandVariables(prompt string, variables []config.Variable) (string, error) {
+	values := make(map[string]string, len(variables))
+	for _, v := range variables {
+		values[v.Name] = v.Value
+	}
+
+	var undefined []string
+	expanded := variableRef.ReplaceAllStringFunc(prompt, func(ref string) string {
+		name := ref[1:]
+		if name == "$" {
+			return "$"
+		}
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if builtin, ok := builtinVariables[name]; ok {
+			return builtin()
+		}
+		undefined = append(undefined, ref)
+		return ref
+	})
+
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variable(s) %s, define them under Variables", strings.Join(undefined, ", "))
+	}
+	return expanded, nil
+}
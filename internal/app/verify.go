@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"os"
+	"path/filepath"
+
+	"fluxxxer/internal/flux"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	xdraw "golang.org/x/image/draw"
+)
+
+// Limits telling a reproduced image from a different one. Lossy formats
+// differ by a few levels even when the backend is deterministic.
+const (
+	verifyPixelTolerance = 12   // Largest channel difference of an unchanged pixel
+	verifyChangedLimit   = 0.01 // Share of changed pixels still counted as the same image
+	verifyHashLimit      = 4    // Differing bits of the perceptual hashes of the same image
+)
+
+// Size of each image in the verification dialog
+const verifyImageSize = 320
+
+// verifyResult compares a saved image with the one generated again from
+// its metadata
+type verifyResult struct {
+	saved, regenerated image.Image
+	diff               *image.RGBA // Changed pixels in red over the saved image
+	sameBytes          bool
+	resized            bool    // The regenerated image had another size and was scaled
+	changed            float64 // Share of pixels that changed beyond the tolerance
+	hashDistance       int     // Differing bits of the perceptual hashes
+}
+
+// reproduced reports whether the regenerated image matches the saved one
+func (r *verifyResult) reproduced() bool {
+	return r.sameBytes || (r.changed <= verifyChangedLimit && r.hashDistance <= verifyHashLimit)
+}
+
+// summary describes the outcome of the verification
+func (r *verifyResult) summary() string {
+	switch {
+	case r.sameBytes:
+		return tr("Reproduced exactly: the regenerated file is identical, so the backend is deterministic for this seed.")
+	case r.changed == 0:
+		return tr("Reproduced: every pixel is identical, only the file encoding differs.")
+	case r.reproduced():
+		return fmt.Sprintf(tr("Reproduced with small differences: %.2f%% of pixels changed slightly, likely from compression."), r.changed*100)
+	}
+	return fmt.Sprintf(tr("Not reproduced: %.1f%% of pixels changed and the images look different (hash distance %d of 64). The backend does not reproduce this image from its seed."), r.changed*100, r.hashDistance)
+}
+
+// verifyGalleryImage generates the saved image at path again from the
+// prompt, seed and settings in its metadata and shows how the new image
+// differs from it
+func (a *App) verifyGalleryImage(path string) {
+	meta := readSidecar(path)
+	if meta == nil || meta.Prompt == "" {
+		a.setStatus(tr("This image has no saved prompt to generate it again from"))
+		return
+	}
+	if meta.Seed == nil {
+		a.setStatus(tr("No seed was saved with this image, so it cannot be reproduced"))
+		return
+	}
+
+	name := filepath.Base(path)
+	a.setStatus(fmt.Sprintf(tr("Verifying %s with seed %d..."), name, *meta.Seed))
+	go func() {
+		result, err := a.regenerateAndCompare(path, meta)
+		glib.IdleAdd(func() {
+			if err != nil {
+				a.setStatus(fmt.Sprintf(tr("Error verifying %s: %v"), name, err))
+				return
+			}
+			a.setStatus(result.summary())
+			a.showVerifyResult(name, result)
+		})
+	}()
+}
+
+// regenerateAndCompare generates the image at path again from its metadata
+// and compares the two. Call it off the main thread.
+func (a *App) regenerateAndCompare(path string, meta *imageMetadata) (result *verifyResult, err error) {
+	defer recoverAsError(&err)
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	regenerated, _, _, err := a.regenerateImage(meta, nil)
+	if err != nil {
+		return nil, err
+	}
+	return compareImageData(saved, regenerated)
+}
+
+// regenerateImage generates the image described by meta again, with change
+// applied to its options if not nil, and downloads it. It returns the image
+// data, its format and the options used. Call it off the main thread.
+func (a *App) regenerateImage(meta *imageMetadata, change func(*flux.GenerateOptions)) ([]byte, imageFormat, flux.GenerateOptions, error) {
+	opts, err := a.metadataOptions(meta)
+	if err != nil {
+		return nil, imageFormat{}, opts, err
+	}
+	if change != nil {
+		change(&opts)
+	}
+
+	generated, err := a.generateOutsideQueue(context.Background(), meta.Prompt, opts)
+	if err != nil {
+		return nil, imageFormat{}, opts, err
+	}
+	if len(generated.URLs) == 0 {
+		return nil, imageFormat{}, opts, errors.New("the backend returned no image")
+	}
+	data, contentType, err := a.client.Download(context.Background(), generated.URLs[0])
+	if err != nil {
+		return nil, imageFormat{}, opts, err
+	}
+	if err := checkImageData(data); err != nil {
+		return nil, imageFormat{}, opts, err
+	}
+	glib.IdleAdd(func() {
+		a.recordDownload(len(data))
+	})
+	return data, detectImageFormat(data, contentType, generated.URLs[0]), opts, nil
+}
+
+// compareImageData compares a saved image with a regenerated one pixel by
+// pixel and by perceptual hash. A regenerated image of another size is
+// scaled to the size of the saved one first.
+func compareImageData(saved, regenerated []byte) (*verifyResult, error) {
+	savedImg, _, err := image.Decode(bytes.NewReader(saved))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode saved image: %w", err)
+	}
+	newImg, _, err := image.Decode(bytes.NewReader(regenerated))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode regenerated image: %w", err)
+	}
+
+	result := &verifyResult{
+		saved:        savedImg,
+		regenerated:  newImg,
+		sameBytes:    bytes.Equal(saved, regenerated),
+		hashDistance: bits.OnesCount64(perceptualHash(savedImg) ^ perceptualHash(newImg)),
+	}
+
+	before := toRGBA(savedImg)
+	after := toRGBA(newImg)
+	if after.Rect.Size() != before.Rect.Size() {
+		scaled := image.NewRGBA(before.Rect)
+		xdraw.CatmullRom.Scale(scaled, scaled.Rect, after, after.Rect, xdraw.Src, nil)
+		after = scaled
+		result.resized = true
+	}
+
+	result.diff = image.NewRGBA(before.Rect)
+	changed := 0
+	for y := 0; y < before.Rect.Dy(); y++ {
+		for x := 0; x < before.Rect.Dx(); x++ {
+			b, c := before.RGBAAt(x, y), after.RGBAAt(x, y)
+			d := max(absDiff(b.R, c.R), absDiff(b.G, c.G), absDiff(b.B, c.B))
+
+			// The saved image dimmed to gray, with changes in red by size
+			gray := uint8((int(b.R) + int(b.G) + int(b.B)) / 6)
+			if d <= verifyPixelTolerance {
+				result.diff.SetRGBA(x, y, color.RGBA{gray, gray, gray, 0xff})
+				continue
+			}
+			changed++
+			result.diff.SetRGBA(x, y, color.RGBA{uint8(min(255, 128+int(d))), gray / 2, gray / 2, 0xff})
+		}
+	}
+	if pixels := before.Rect.Dx() * before.Rect.Dy(); pixels > 0 {
+		result.changed = float64(changed) / float64(pixels)
+	}
+	return result, nil
+}
+
+// absDiff returns the absolute difference of two channel values
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// perceptualHash returns the difference hash of img: whether each pixel of
+// a 9x8 grayscale copy is brighter than its right neighbour. Similar images
+// have hashes differing in few bits.
+func perceptualHash(img image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	xdraw.ApproxBiLinear.Scale(small, small.Rect, img, img.Bounds(), xdraw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// showVerifyResult shows the saved image, the regenerated one and their
+// differences side by side with the outcome
+func (a *App) showVerifyResult(name string, result *verifyResult) {
+	imagesBox := gtk.NewBox(gtk.OrientationHorizontal, 12)
+	for _, column := range []struct {
+		label string
+		img   image.Image
+	}{
+		{tr("Saved"), result.saved},
+		{tr("Regenerated"), result.regenerated},
+		{tr("Differences"), result.diff},
+	} {
+		picture := gtk.NewPicture()
+		picture.SetPaintable(memoryTexture(toRGBA(column.img)))
+		picture.SetCanShrink(true)
+		picture.SetContentFit(gtk.ContentFitContain)
+		picture.SetSizeRequest(verifyImageSize, verifyImageSize)
+
+		label := gtk.NewLabel(column.label)
+		label.AddCSSClass("dim-label")
+
+		columnBox := gtk.NewBox(gtk.OrientationVertical, 4)
+		columnBox.Append(picture)
+		columnBox.Append(label)
+		imagesBox.Append(columnBox)
+	}
+
+	summary := result.summary()
+	if result.resized {
+		summary += " " + tr("The regenerated image had a different size and was scaled to compare it.")
+	}
+	summaryLabel := gtk.NewLabel(summary)
+	summaryLabel.SetWrap(true)
+	summaryLabel.SetXAlign(0)
+	summaryLabel.SetMaxWidthChars(80)
+	if !result.reproduced() {
+		summaryLabel.AddCSSClass("error")
+	}
+
+	contentBox := gtk.NewBox(gtk.OrientationVertical, 12)
+	contentBox.SetMarginTop(12)
+	contentBox.SetMarginBottom(12)
+	contentBox.SetMarginStart(12)
+	contentBox.SetMarginEnd(12)
+	contentBox.Append(summaryLabel)
+	contentBox.Append(imagesBox)
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(fmt.Sprintf(tr("Verify %s"), name))
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.ContentArea().Append(contentBox)
+	dialog.AddButton(tr("Close"), int(gtk.ResponseClose))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
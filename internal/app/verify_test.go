@@ -0,0 +1,148 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/bits"
+	"testing"
+)
+
+// testGradient returns a w×h image with a diagonal gradient, mirrored
+// when flipped is set
+func testGradient(w, h int, flipped bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*255/h) / 2)
+			if flipped {
+				v = 255 - v
+			}
+			img.SetRGBA(x, y, color.RGBA{v, v / 2, 255 - v, 0xff})
+		}
+	}
+	return img
+}
+
+// testPNG encodes img as PNG with the given compression
+func testPNG(t *testing.T, img image.Image, level png.CompressionLevel) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: level}
+	if err := encoder.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareImageData(t *testing.T) {
+	saved := testGradient(100, 100, false)
+	savedPNG := testPNG(t, saved, png.DefaultCompression)
+
+	// One pixel in a hundred changed far beyond the tolerance
+	spotted := testGradient(100, 100, false)
+	for x := 0; x < 100; x++ {
+		spotted.SetRGBA(x, x, color.RGBA{255, 255, 255, 0xff})
+	}
+
+	tests := []struct {
+		name          string
+		regenerated   []byte
+		wantSameBytes bool
+		wantChanged   float64
+		wantResized   bool
+		wantSame      bool // Whether the image counts as reproduced
+	}{
+		{
+			name:          "identical file",
+			regenerated:   savedPNG,
+			wantSameBytes: true,
+			wantSame:      true,
+		},
+		{
+			name:        "identical pixels",
+			regenerated: testPNG(t, saved, png.BestSpeed),
+			wantSame:    true,
+		},
+		{
+			name:        "few changed pixels",
+			regenerated: testPNG(t, spotted, png.DefaultCompression),
+			wantChanged: 0.01,
+			wantSame:    true,
+		},
+		{
+			name:        "larger size",
+			regenerated: testPNG(t, testGradient(200, 200, false), png.DefaultCompression),
+			wantChanged: -1,
+			wantResized: true,
+			wantSame:    true,
+		},
+		{
+			name:        "different image",
+			regenerated: testPNG(t, testGradient(100, 100, true), png.DefaultCompression),
+			wantChanged: -1,
+			wantSame:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := compareImageData(savedPNG, tt.regenerated)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.sameBytes != tt.wantSameBytes {
+				t.Errorf("got same bytes %v, want %v", result.sameBytes, tt.wantSameBytes)
+			}
+			// A negative share is not checked exactly
+			if tt.wantChanged >= 0 && result.changed != tt.wantChanged {
+				t.Errorf("got %v changed, want %v", result.changed, tt.wantChanged)
+			}
+			if result.resized != tt.wantResized {
+				t.Errorf("got resized %v, want %v", result.resized, tt.wantResized)
+			}
+			if result.reproduced() != tt.wantSame {
+				t.Errorf("got reproduced %v, want %v (%.3f changed, hash distance %d)", result.reproduced(), tt.wantSame, result.changed, result.hashDistance)
+			}
+			if result.diff.Rect != saved.Rect {
+				t.Errorf("got diff of %v, want %v", result.diff.Rect, saved.Rect)
+			}
+		})
+	}
+}
+
+func TestCompareImageDataInvalid(t *testing.T) {
+	valid := testPNG(t, testGradient(10, 10, false), png.DefaultCompression)
+
+	if _, err := compareImageData([]byte("not an image"), valid); err == nil {
+		t.Error("got no error for an invalid saved image")
+	}
+	if _, err := compareImageData(valid, []byte("not an image")); err == nil {
+		t.Error("got no error for an invalid regenerated image")
+	}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	base := perceptualHash(testGradient(64, 64, false))
+
+	tests := []struct {
+		name    string
+		img     image.Image
+		similar bool
+	}{
+		{"same image", testGradient(64, 64, false), true},
+		{"scaled", testGradient(256, 256, false), true},
+		{"mirrored", testGradient(64, 64, true), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := bits.OnesCount64(base ^ perceptualHash(tt.img))
+			if got := distance <= verifyHashLimit; got != tt.similar {
+				t.Errorf("got distance %d, want similar %v", distance, tt.similar)
+			}
+		})
+	}
+}
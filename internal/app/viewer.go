@@ -0,0 +1,138 @@
+package app
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// Zoom limits of the image viewer, relative to the image fitted to the window
+const (
+	maxViewerZoom  = 8.0
+	viewerZoomStep = 1.25 // Zoom factor per scroll wheel step
+)
+
+// zoomView shows an image that can be zoomed with pinch gestures or the scroll
+// wheel and panned by dragging. A zoom of 1 fits the image to the view.
+type zoomView struct {
+	scroll  *gtk.ScrolledWindow
+	picture *gtk.Picture
+	texture *gdk.Texture
+	zoom    float64
+
+	// Pointer position, the center for scroll wheel zoom
+	pointerX, pointerY float64
+}
+
+// newZoomView creates a zoomable, pannable view of texture
+func newZoomView(texture *gdk.Texture) *zoomView {
+	v := &zoomView{texture: texture, zoom: 1}
+
+	v.picture = gtk.NewPicture()
+	v.picture.SetPaintable(texture)
+	v.picture.SetCanShrink(true)
+	v.picture.SetContentFit(gtk.ContentFitContain)
+
+	v.scroll = gtk.NewScrolledWindow()
+	v.scroll.SetHExpand(true)
+	v.scroll.SetVExpand(true)
+	v.scroll.SetChild(v.picture)
+
+	motion := gtk.NewEventControllerMotion()
+	motion.ConnectMotion(func(x, y float64) {
+		v.pointerX, v.pointerY = x, y
+	})
+	v.scroll.AddController(motion)
+
+	// Zoom with the wheel before the scrolled window would scroll
+	wheel := gtk.NewEventControllerScroll(gtk.EventControllerScrollVertical)
+	wheel.SetPropagationPhase(gtk.PhaseCapture)
+	wheel.ConnectScroll(func(dx, dy float64) bool {
+		v.setZoom(v.zoom*math.Pow(viewerZoomStep, -dy), v.pointerX, v.pointerY)
+		return true
+	})
+	v.scroll.AddController(wheel)
+
+	// Pinch zoom scales relative to the zoom when the gesture began
+	pinch := gtk.NewGestureZoom()
+	startZoom := 1.0
+	pinch.ConnectBegin(func(*gdk.EventSequence) {
+		startZoom = v.zoom
+	})
+	pinch.ConnectScaleChanged(func(scale float64) {
+		x, y, ok := pinch.BoundingBoxCenter()
+		if !ok {
+			x, y = float64(v.scroll.Width())/2, float64(v.scroll.Height())/2
+		}
+		v.setZoom(startZoom*scale, x, y)
+	})
+	v.scroll.AddController(pinch)
+
+	drag := gtk.NewGestureDrag()
+	var startH, startV float64
+	drag.ConnectDragBegin(func(startX, startY float64) {
+		startH = v.scroll.HAdjustment().Value()
+		startV = v.scroll.VAdjustment().Value()
+	})
+	drag.ConnectDragUpdate(func(offsetX, offsetY float64) {
+		v.scroll.HAdjustment().SetValue(startH - offsetX)
+		v.scroll.VAdjustment().SetValue(startV - offsetY)
+	})
+	v.scroll.AddController(drag)
+
+	return v
+}
+
+// setZoom changes the zoom, keeping the image point under (x, y) in place.
+// The zoom is clamped between fitting the window and maxViewerZoom.
+func (v *zoomView) setZoom(zoom, x, y float64) {
+	zoom = math.Max(1, math.Min(zoom, maxViewerZoom))
+	if zoom == v.zoom {
+		return
+	}
+	ratio := zoom / v.zoom
+	v.zoom = zoom
+
+	viewWidth, viewHeight := float64(v.scroll.Width()), float64(v.scroll.Height())
+	if zoom == 1 {
+		v.picture.SetSizeRequest(-1, -1)
+		return
+	}
+
+	// Size of the image fitted to the view, scaled by the zoom
+	fit := math.Min(viewWidth/float64(v.texture.Width()), viewHeight/float64(v.texture.Height()))
+	width := math.Max(viewWidth, float64(v.texture.Width())*fit*zoom)
+	height := math.Max(viewHeight, float64(v.texture.Height())*fit*zoom)
+	v.picture.SetSizeRequest(int(width), int(height))
+
+	// The adjustments only learn the new size on the next layout, so set
+	// their range now to scroll to the zoom center immediately
+	hadj, vadj := v.scroll.HAdjustment(), v.scroll.VAdjustment()
+	hValue := (hadj.Value()+x)*ratio - x
+	vValue := (vadj.Value()+y)*ratio - y
+	hadj.SetUpper(width)
+	vadj.SetUpper(height)
+	hadj.SetValue(hValue)
+	vadj.SetValue(vValue)
+}
+
+// showImageViewer shows texture in a dialog at full resolution, zoomable
+// and pannable to inspect detail
+func (a *App) showImageViewer(title string, texture *gdk.Texture) {
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(title)
+	dialog.SetTransientFor(&a.win.Window)
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(800, 600)
+
+	view := newZoomView(texture)
+	view.scroll.SetTooltipText(tr("Scroll or pinch to zoom, drag to pan"))
+
+	dialog.ContentArea().Append(view.scroll)
+	dialog.AddButton(tr("Close"), int(gtk.ResponseClose))
+	dialog.ConnectResponse(func(responseId int) {
+		dialog.Destroy()
+	})
+	dialog.Show()
+}
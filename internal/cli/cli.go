@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fluxxxer/internal/config"
+	"fluxxxer/internal/flux"
+)
+
+// IsCLI reports whether the arguments ask for headless CLI mode
+func IsCLI(args []string) bool {
+	for _, arg := range args {
+		if arg == "--cli" || arg == "-cli" {
+			return true
+		}
+	}
+	return false
+}
+
+// now returns the time saved files are named after, replaced in tests
+var now = time.Now
+
+// Run generates images without the GUI and returns the process exit code.
+// Saved file paths are printed to stdout, one per line, so the output can
+// be piped to other tools.
+func Run(args []string) int {
+	return run(args, os.Stdin, os.Stdout)
+}
+
+// run is Run reading piped prompts from stdin and printing the saved file
+// paths to stdout
+func run(args []string, stdin io.Reader, stdout io.Writer) int {
+	cfg := config.NewConfig()
+
+	flags := flag.NewFlagSet("fluxxxer", flag.ContinueOnError)
+	flags.Bool("cli", true, "run without the GUI")
+	prompt := flags.String("prompt", "", `prompt to generate; "-" or piped input reads it from stdin`)
+	perLine := flags.Bool("lines", false, "treat each line of stdin as a separate prompt")
+	outDir := flags.String("out-dir", cfg.GetOutputDir(), "directory to save images in")
+	numOutputs := flags.Int("n", cfg.GetDefaultNumOutputs(), "number of images per prompt")
+	aspectRatio := flags.String("aspect-ratio", cfg.GetDefaultAspectRatio(), "aspect ratio, e.g. 16:9")
+	seed := flags.Int("seed", -1, "seed to generate with (default: random)")
+	force := flags.Bool("force", false, "overwrite files that already exist")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	prompts, err := collectPrompts(*prompt, *perLine, stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if cfg.IsOffline() {
+		fmt.Fprintln(os.Stderr, "Error: FLUX_API_URL is not set")
+		return 1
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		return 1
+	}
+
+	opts := flux.GenerateOptions{
+		NumOutputs:   *numOutputs,
+		AspectRatio:  *aspectRatio,
+		OutputFormat: cfg.GetDefaultFormat(),
+		Quality:      cfg.GetDefaultQuality(),
+	}
+	if *seed >= 0 {
+		opts.Seed = seed
+	}
+
+	client := flux.NewClient(cfg)
+	exitCode := 0
+	for _, p := range prompts {
+		if err := generate(client, p, opts, *outDir, *force, stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating %q: %v\n", p, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// collectPrompts returns the prompts to generate. The prompt is read from
+// stdin when it is "-" or when it is empty and stdin is piped. Multi-line
+// input is one prompt unless perLine is set.
+func collectPrompts(prompt string, perLine bool, stdin io.Reader) ([]string, error) {
+	if prompt == "" && !stdinIsPiped(stdin) {
+		return nil, errors.New("no prompt given; use --prompt or pipe one on stdin")
+	}
+
+	if prompt != "" && prompt != "-" {
+		return []string{prompt}, nil
+	}
+
+	prompts, err := readPrompts(stdin, perLine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt from stdin: %w", err)
+	}
+	if len(prompts) == 0 {
+		return nil, errors.New("no prompt on stdin")
+	}
+	return prompts, nil
+}
+
+// readPrompts reads prompts from r, either one per non-empty line or the
+// whole input joined into a single prompt
+func readPrompts(r io.Reader, perLine bool) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if perLine || len(lines) == 0 {
+		return lines, nil
+	}
+	return []string{strings.Join(lines, " ")}, nil
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinIsPiped(stdin io.Reader) bool {
+	file, ok := stdin.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// generate runs one generation, saves its images to outDir and prints
+// their paths to stdout. Existing files are only replaced when force is set.
+func generate(client *flux.Client, prompt string, opts flux.GenerateOptions, outDir string, force bool, stdout io.Writer) error {
+	result, err := client.Generate(context.Background(), prompt, opts)
+	if err != nil {
+		return err
+	}
+	if result.FormatFallback != "" {
+		fmt.Fprintf(os.Stderr, "Format %s not supported by the backend, used png instead\n", result.FormatFallback)
+	}
+
+	completion := flux.NewCompletion(prompt, opts, result)
+	if err := client.NotifyWebhook(context.Background(), completion); err != nil {
+		fmt.Fprintf(os.Stderr, "Error notifying webhook: %v\n", err)
+	}
+
+	stamp := now().Format("20060102-150405")
+	for i, url := range result.URLs {
+		data, _, err := client.Download(context.Background(), url)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("fluxxxer-%s-%d-%d%s", stamp, result.Seed, i+1, imageExt(data))
+		path := filepath.Join(outDir, name)
+		if err := saveImage(path, data, force); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
+
+// saveImage writes data to path. Unless force is set, an existing file is
+// an error rather than overwritten; the check and the write are one step,
+// so two runs saving at the same time cannot both succeed.
+func saveImage(path string, data []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
+
+// imageExt returns the file extension for image data based on its content
+func imageExt(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".png"
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsCLI(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--cli", "--prompt", "fox"}, true},
+		{[]string{"--prompt", "fox", "-cli"}, true},
+		{[]string{"--prompt", "--cli-like"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsCLI(tt.args); got != tt.want {
+			t.Errorf("IsCLI(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestReadPrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		perLine bool
+		want    []string
+	}{
+		{"joined", "a red fox\n  in the snow \n", false, []string{"a red fox in the snow"}},
+		{"per line", "a red fox\n\n  a lighthouse\n", true, []string{"a red fox", "a lighthouse"}},
+		{"blank", "\n  \n", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPrompts(strings.NewReader(tt.input), tt.perLine)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestBackend starts a backend answering each generation with two
+// images, whose contents change with every request
+func newTestBackend(t *testing.T) {
+	t.Helper()
+	var generations atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			generations.Add(1)
+			fmt.Fprintf(w, `{"output": ["%[1]s/1.png", "%[1]s/2.png"], "seed": 42}`, server.URL)
+			return
+		}
+		fmt.Fprintf(w, "\x89PNG\r\n\x1a\nimage %s of generation %d", r.URL.Path, generations.Load())
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("FLUX_API_URL", server.URL)
+	t.Setenv("FLUX_CONFIG_DIR", t.TempDir())
+	t.Setenv("FLUX_WEBHOOK_URL", "")
+}
+
+func TestRunFlags(t *testing.T) {
+	newTestBackend(t)
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  int
+	}{
+		{"unknown flag", []string{"--cli", "--bogus"}, "", 2},
+		{"invalid number", []string{"--cli", "--prompt", "fox", "-n", "many"}, "", 2},
+		{"empty stdin", []string{"--cli"}, "\n", 2},
+		{"prompt from stdin", []string{"--cli", "--prompt", "-", "-n", "2"}, "a red fox\n", 0},
+		{"prompt per line", []string{"--cli", "--lines", "--force"}, "a red fox\na lighthouse\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(tt.args, "--out-dir", t.TempDir())
+			var stdout bytes.Buffer
+			if got := run(args, strings.NewReader(tt.stdin), &stdout); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	newTestBackend(t)
+	stamp := time.Date(2024, 5, 1, 15, 30, 12, 0, time.UTC)
+	now = func() time.Time { return stamp }
+	t.Cleanup(func() { now = time.Now })
+
+	dir := t.TempDir()
+	args := []string{"--cli", "--prompt", "a red fox", "--out-dir", dir}
+	want := []string{
+		filepath.Join(dir, "fluxxxer-20240501-153012-42-1.png"),
+		filepath.Join(dir, "fluxxxer-20240501-153012-42-2.png"),
+	}
+
+	// checkRun runs the CLI and checks its exit code, the paths it printed
+	// and which generation the saved images came from
+	checkRun := func(args []string, wantCode int, wantPaths []string, wantGeneration int) {
+		t.Helper()
+		var stdout bytes.Buffer
+		if got := run(args, strings.NewReader(""), &stdout); got != wantCode {
+			t.Errorf("got exit code %d, want %d", got, wantCode)
+		}
+		if got := strings.Fields(stdout.String()); !slices.Equal(got, wantPaths) {
+			t.Errorf("got output %q, want %q", got, wantPaths)
+		}
+		for i, path := range want {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if suffix := fmt.Sprintf("image /%d.png of generation %d", i+1, wantGeneration); !strings.HasSuffix(string(data), suffix) {
+				t.Errorf("%s holds %q, want the image from generation %d", path, data, wantGeneration)
+			}
+		}
+	}
+
+	checkRun(args, 0, want, 1)
+
+	// The same names again are refused, leaving the files as they were
+	checkRun(args, 1, nil, 1)
+
+	checkRun(append(args, "--force"), 0, want, 3)
+}
+
+func TestSaveImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.png")
+
+	if err := saveImage(path, []byte("first"), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := saveImage(path, []byte("second"), false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("got error %v, want one suggesting --force", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "first" {
+		t.Errorf("got %q after a refused save, want the first image", data)
+	}
+
+	if err := saveImage(path, []byte("2nd"), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "2nd" {
+		t.Errorf("got %q after a forced save, want the second image", data)
+	}
+}
@@ -1,20 +1,55 @@
 package config
 
 import (
+	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultFileNameTemplate names automatically saved images by date, time,
+// seed, number in the batch and prompt, e.g. "2024-05-01_153012_42_1_a-red-fox"
+const DefaultFileNameTemplate = "{date}_{time}_{seed}_{n}_{prompt}"
+
 // Config holds application configuration
 type Config struct {
 	// Flux API settings
 	APIEndpoint        string
 	DefaultNumOutputs  int
+	MaxOutputs         int
 	DefaultAspectRatio string
 	DefaultFormat      string
 	DefaultQuality     int
 	DisableSafetyCheck bool
+	APIHeaders         map[string]string // Guarded by headersMu, replaced rather than changed
+	HealthURL          string
+	WebhookURL         string
+	CostPerImage       float64
+	SendDimensions     bool
+	SendImageURLs      bool
+	ResponseFormat     string
+	DedupeResults      bool
+	EmptyRetries       int
+	MaxConcurrent      int
+	Dimensions         map[string]Dimensions
+	Offline            bool
+	ImageTimeout       time.Duration
+	MaxImageSize       int64
+	ImageAuth          bool   // Send the API's Authorization header with image downloads from its host
+	ImageToken         string // Bearer token sent with image downloads from the API's host instead
+	Profile            string // Name the saved API headers are kept under
+	Debug              bool   // Log each request to stderr
+	
+	headersMu  sync.RWMutex
+	envHeaders map[string]string // Set by FLUX_API_HEADERS, overriding saved headers
+	
+	// Connection reuse of the HTTP transport
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration // 0 turns keep-alive off
 	
 	// Upscaler API settings
 	UpscalerAPIURL     string
@@ -25,6 +60,13 @@ type Config struct {
 	// UI settings
 	WindowWidth        int
 	WindowHeight       int
+	MaxDisplaySize     int
+	
+	// Storage settings
+	OutputDir        string
+	CacheDir         string
+	ConfigDir        string
+	FileNameTemplate string // Names of automatically saved images
 }
 
 // NewConfig creates a new configuration with default values and environment overrides
@@ -33,10 +75,23 @@ func NewConfig() *Config {
 		// Flux API settings
 		APIEndpoint:        os.Getenv("FLUX_API_URL"),
 		DefaultNumOutputs:  4,
+		MaxOutputs:         8,
 		DefaultAspectRatio: "1:1",
 		DefaultFormat:      "png",
 		DefaultQuality:     1,
 		DisableSafetyCheck: true,
+		APIHeaders:         map[string]string{},
+		HealthURL:          os.Getenv("FLUX_HEALTH_URL"),
+		WebhookURL:         os.Getenv("FLUX_WEBHOOK_URL"),
+		ResponseFormat:     strings.ToLower(os.Getenv("FLUX_RESPONSE_FORMAT")),
+		Dimensions:         defaultDimensions(),
+		DedupeResults:      true,
+		MaxConcurrent:      1,
+		ImageTimeout:       60 * time.Second,
+		MaxImageSize:       64 << 20,
+		MaxIdleConnsPerHost: 8,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
 		
 		// Upscaler API settings
 		UpscalerAPIURL:     os.Getenv("UPSCALER_API_URL"),
@@ -47,6 +102,28 @@ func NewConfig() *Config {
 		// UI settings
 		WindowWidth:        2000,
 		WindowHeight:       800,
+		MaxDisplaySize:     2048,
+		
+		// Storage settings
+		FileNameTemplate:   DefaultFileNameTemplate,
+	}
+	
+	// Save images under the user's Pictures directory by default
+	if home, err := os.UserHomeDir(); err == nil {
+		cfg.OutputDir = filepath.Join(home, "Pictures", "Fluxxxer")
+	}
+	
+	// Keep caches such as gallery thumbnails in the user cache directory
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		cfg.CacheDir = filepath.Join(cacheDir, "fluxxxer")
+	}
+	
+	// Keep saved settings such as presets in the user config directory
+	cfg.ConfigDir = defaultConfigDir()
+	
+	// FLUX_UPSCALE_URL is accepted as an alternative name for the upscaler URL
+	if cfg.UpscalerAPIURL == "" {
+		cfg.UpscalerAPIURL = os.Getenv("FLUX_UPSCALE_URL")
 	}
 	
 	// Use the default upscaler URL if not set
@@ -61,6 +138,12 @@ func NewConfig() *Config {
 		}
 	}
 
+	if val := os.Getenv("FLUX_MAX_OUTPUTS"); val != "" {
+		if num, err := strconv.Atoi(val); err == nil && num > 0 {
+			cfg.MaxOutputs = num
+		}
+	}
+
 	if val := os.Getenv("FLUX_ASPECT_RATIO"); val != "" {
 		cfg.DefaultAspectRatio = val
 	}
@@ -78,6 +161,93 @@ func NewConfig() *Config {
 	if val := os.Getenv("FLUX_DISABLE_SAFETY"); val != "" {
 		cfg.DisableSafetyCheck = val == "true" || val == "1" || val == "yes"
 	}
+
+	// Without an endpoint the app can only browse the gallery
+	cfg.Offline = cfg.APIEndpoint == ""
+	if val := os.Getenv("FLUX_OFFLINE"); val != "" {
+		cfg.Offline = cfg.Offline || val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := os.Getenv("FLUX_API_HEADERS"); val != "" {
+		cfg.envHeaders = ParseHeaders(val)
+	}
+
+	if val := os.Getenv("FLUX_DEBUG"); val != "" {
+		cfg.Debug = val == "true" || val == "1" || val == "yes"
+	}
+
+	cfg.Profile = profileName(os.Getenv("FLUX_PROFILE"), os.Getenv(EnvFileVar))
+
+	if val := os.Getenv("FLUX_SEND_DIMENSIONS"); val != "" {
+		cfg.SendDimensions = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := os.Getenv("FLUX_SEND_IMAGE_URLS"); val != "" {
+		cfg.SendImageURLs = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := os.Getenv("FLUX_DIMENSIONS"); val != "" {
+		for ratio, dims := range parseDimensions(val) {
+			cfg.Dimensions[ratio] = dims
+		}
+	}
+
+	if val := os.Getenv("FLUX_DEDUPE_RESULTS"); val != "" {
+		cfg.DedupeResults = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := os.Getenv("FLUX_IMAGE_AUTH"); val != "" {
+		cfg.ImageAuth = val == "true" || val == "1" || val == "yes"
+	}
+	cfg.ImageToken = os.Getenv("FLUX_IMAGE_TOKEN")
+
+	if val := os.Getenv("FLUX_IMAGE_TIMEOUT"); val != "" {
+		if seconds, err := strconv.Atoi(val); err == nil && seconds > 0 {
+			cfg.ImageTimeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if val := os.Getenv("FLUX_MAX_IMAGE_MB"); val != "" {
+		if mb, err := strconv.Atoi(val); err == nil && mb > 0 {
+			cfg.MaxImageSize = int64(mb) << 20
+		}
+	}
+
+	if val := os.Getenv("FLUX_MAX_IDLE_CONNS_PER_HOST"); val != "" {
+		if conns, err := strconv.Atoi(val); err == nil && conns > 0 {
+			cfg.MaxIdleConnsPerHost = conns
+		}
+	}
+
+	if val := os.Getenv("FLUX_IDLE_CONN_TIMEOUT"); val != "" {
+		if seconds, err := strconv.Atoi(val); err == nil && seconds > 0 {
+			cfg.IdleConnTimeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if val := os.Getenv("FLUX_KEEP_ALIVE"); val != "" {
+		if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+			cfg.KeepAlive = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if val := os.Getenv("FLUX_EMPTY_RETRIES"); val != "" {
+		if retries, err := strconv.Atoi(val); err == nil && retries >= 0 {
+			cfg.EmptyRetries = retries
+		}
+	}
+
+	if val := os.Getenv("FLUX_CONCURRENCY"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 1 {
+			cfg.MaxConcurrent = n
+		}
+	}
+
+	if val := os.Getenv("FLUX_COST_PER_IMAGE"); val != "" {
+		if cost, err := strconv.ParseFloat(val, 64); err == nil && cost >= 0 {
+			cfg.CostPerImage = cost
+		}
+	}
 	
 	// Override Upscaler API defaults with environment variables
 	if val := os.Getenv("UPSCALER_TYPE"); val != "" {
@@ -97,9 +267,60 @@ func NewConfig() *Config {
 		}
 	}
 
+	if val := os.Getenv("FLUX_MAX_DISPLAY_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size >= 0 {
+			cfg.MaxDisplaySize = size
+		}
+	}
+
+	// Override storage defaults with environment variables
+	if val := os.Getenv("FLUX_OUTPUT_DIR"); val != "" {
+		cfg.OutputDir = val
+	}
+
+	if val := os.Getenv("FLUX_FILENAME_TEMPLATE"); val != "" {
+		cfg.FileNameTemplate = val
+	}
+
+	if val := os.Getenv("FLUX_CACHE_DIR"); val != "" {
+		cfg.CacheDir = val
+	}
+
+	if val := os.Getenv("FLUX_CONFIG_DIR"); val != "" {
+		cfg.ConfigDir = val
+	}
+
+	// Headers saved for the profile apply unless FLUX_API_HEADERS sets them.
+	// An unreadable headers file is left for the header editor to report.
+	saved, _ := LoadHeaders(cfg.ConfigDir, cfg.Profile)
+	cfg.APIHeaders = mergeHeaders(saved, cfg.envHeaders)
+
 	return cfg
 }
 
+// profileName returns the profile named by FLUX_PROFILE, or else the name
+// of the env file read last without its extension, e.g. "prod" for
+// ~/.fluxxxer/prod.env, or "default"
+func profileName(profile, envFile string) string {
+	if profile != "" {
+		return profile
+	}
+	if envFile != "" {
+		return strings.TrimSuffix(filepath.Base(envFile), filepath.Ext(envFile))
+	}
+	return DefaultProfile
+}
+
+// defaultConfigDir returns the directory for saved settings unless
+// FLUX_CONFIG_DIR names another, or "" when there is no user config directory
+func defaultConfigDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "fluxxxer")
+}
+
 // Flux API getters
 
 // GetAPIEndpoint returns the API endpoint
@@ -112,6 +333,11 @@ func (c *Config) GetDefaultNumOutputs() int {
 	return c.DefaultNumOutputs
 }
 
+// GetMaxOutputs returns the most images the backend generates per request
+func (c *Config) GetMaxOutputs() int {
+	return c.MaxOutputs
+}
+
 // GetDefaultAspectRatio returns the default aspect ratio
 func (c *Config) GetDefaultAspectRatio() string {
 	return c.DefaultAspectRatio
@@ -132,6 +358,157 @@ func (c *Config) GetDisableSafetyCheck() bool {
 	return c.DisableSafetyCheck
 }
 
+// GetAPIHeaders returns extra headers sent with generation requests. The
+// map must not be changed.
+func (c *Config) GetAPIHeaders() map[string]string {
+	c.headersMu.RLock()
+	defer c.headersMu.RUnlock()
+	return c.APIHeaders
+}
+
+// SetSavedHeaders replaces the headers saved for the profile in those sent
+// with generation requests. Headers set by FLUX_API_HEADERS still override
+// them.
+func (c *Config) SetSavedHeaders(saved map[string]string) {
+	c.headersMu.Lock()
+	defer c.headersMu.Unlock()
+	c.APIHeaders = mergeHeaders(saved, c.envHeaders)
+}
+
+// mergeHeaders returns the headers of base with those of override on top.
+// Header names are case-insensitive, so they are compared canonicalized.
+func mergeHeaders(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for _, headers := range []map[string]string{base, override} {
+		for name, value := range headers {
+			merged[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return merged
+}
+
+// GetProfile returns the name the API headers are saved under
+func (c *Config) GetProfile() string {
+	return c.Profile
+}
+
+// GetDebug returns whether each request is logged to stderr
+func (c *Config) GetDebug() bool {
+	return c.Debug
+}
+
+// GetImageAuthorization returns the Authorization header sent with image
+// downloads from the API's own host, or an empty string to send none.
+// FLUX_IMAGE_TOKEN is sent as a bearer token unless it names its scheme;
+// otherwise FLUX_IMAGE_AUTH reuses the Authorization of FLUX_API_HEADERS.
+func (c *Config) GetImageAuthorization() string {
+	if c.ImageToken != "" {
+		if strings.Contains(c.ImageToken, " ") {
+			return c.ImageToken
+		}
+		return "Bearer " + c.ImageToken
+	}
+	if !c.ImageAuth {
+		return ""
+	}
+	for name, value := range c.GetAPIHeaders() {
+		if strings.EqualFold(name, "Authorization") {
+			return value
+		}
+	}
+	return ""
+}
+
+// GetHealthURL returns the URL checked to see whether the backend is reachable,
+// or an empty string to check the API endpoint's base URL
+func (c *Config) GetHealthURL() string {
+	return c.HealthURL
+}
+
+// GetWebhookURL returns the URL notified when a generation completes, or an
+// empty string for none
+func (c *Config) GetWebhookURL() string {
+	return c.WebhookURL
+}
+
+// GetSendDimensions returns whether width and height are sent instead of
+// the aspect ratio
+func (c *Config) GetSendDimensions() bool {
+	return c.SendDimensions
+}
+
+// GetResponseFormat returns the name of the response format to parse, or
+// an empty string to detect it
+func (c *Config) GetResponseFormat() string {
+	return c.ResponseFormat
+}
+
+// GetSendImageURLs returns whether base images from the web are sent by URL
+// instead of being downloaded and embedded
+func (c *Config) GetSendImageURLs() bool {
+	return c.SendImageURLs
+}
+
+// GetDimensions returns the width and height used for an aspect ratio
+func (c *Config) GetDimensions(aspectRatio string) (int, int, bool) {
+	dims, ok := c.Dimensions[aspectRatio]
+	return dims.Width, dims.Height, ok
+}
+
+// GetDedupeResults returns whether duplicate result URLs are hidden
+func (c *Config) GetDedupeResults() bool {
+	return c.DedupeResults
+}
+
+// GetEmptyRetries returns how many times a generation that returned no
+// images is sent again before failing
+func (c *Config) GetEmptyRetries() int {
+	return c.EmptyRetries
+}
+
+// GetMaxConcurrent returns how many queued generations are sent at once
+func (c *Config) GetMaxConcurrent() int {
+	return c.MaxConcurrent
+}
+
+// GetImageTimeout returns how long a single image download may take
+func (c *Config) GetImageTimeout() time.Duration {
+	return c.ImageTimeout
+}
+
+// GetMaxImageSize returns the largest image download accepted, in bytes
+func (c *Config) GetMaxImageSize() int64 {
+	return c.MaxImageSize
+}
+
+// GetMaxIdleConnsPerHost returns how many idle connections to a backend
+// are kept open for reuse
+func (c *Config) GetMaxIdleConnsPerHost() int {
+	return c.MaxIdleConnsPerHost
+}
+
+// GetIdleConnTimeout returns how long an idle connection is kept open
+func (c *Config) GetIdleConnTimeout() time.Duration {
+	return c.IdleConnTimeout
+}
+
+// GetKeepAlive returns the interval of TCP keep-alive probes, 0 when
+// keep-alive is turned off
+func (c *Config) GetKeepAlive() time.Duration {
+	return c.KeepAlive
+}
+
+// GetCostPerImage returns the backend's price per generated image,
+// or 0 when costs are not tracked
+func (c *Config) GetCostPerImage() float64 {
+	return c.CostPerImage
+}
+
+// IsOffline returns true if generation is unavailable
+func (c *Config) IsOffline() bool {
+	return c.Offline
+}
+
 // Upscaler API getters
 
 // GetUpscalerAPIURL returns the upscaler API URL
@@ -166,6 +543,35 @@ func (c *Config) GetWindowHeight() int {
 	return c.WindowHeight
 }
 
+// GetMaxDisplaySize returns the largest width or height images are displayed
+// at before being scaled down, or 0 to always display at full resolution
+func (c *Config) GetMaxDisplaySize() int {
+	return c.MaxDisplaySize
+}
+
+// Storage getters
+
+// GetOutputDir returns the directory saved images and the gallery use
+func (c *Config) GetOutputDir() string {
+	return c.OutputDir
+}
+
+// GetFileNameTemplate returns the template naming automatically saved
+// images, without the extension
+func (c *Config) GetFileNameTemplate() string {
+	return c.FileNameTemplate
+}
+
+// GetCacheDir returns the directory for cached data such as thumbnails
+func (c *Config) GetCacheDir() string {
+	return c.CacheDir
+}
+
+// GetConfigDir returns the directory for saved settings such as presets
+func (c *Config) GetConfigDir() string {
+	return c.ConfigDir
+}
+
 // Helper methods
 
 // GetSupportedAspectRatios returns a list of supported aspect ratios
@@ -181,4 +587,19 @@ func (c *Config) GetSupportedUpscaleTypes() []string {
 // IsUpscalerConfigured returns true if the upscaler is configured
 func (c *Config) IsUpscalerConfigured() bool {
 	return c.UpscalerAPIURL != "" && c.UpscalerAPIKey != ""
-}
\ No newline at end of file
+}
+
+// ParseHeaders parses a list of headers in the form "Name: value; Other: value",
+// also accepting one header per line. Entries without a name are ignored.
+func ParseHeaders(val string) map[string]string {
+	headers := map[string]string{}
+	for _, entry := range strings.FieldsFunc(val, func(r rune) bool { return r == ';' || r == '\n' }) {
+		name, value, found := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			continue
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers
+}
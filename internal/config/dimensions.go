@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dimensionMultiple is the step image widths and heights must be a multiple
+// of, as many models require
+const dimensionMultiple = 16
+
+// Dimensions is an image size in pixels
+type Dimensions struct {
+	Width  int
+	Height int
+}
+
+// defaultDimensions maps each supported aspect ratio to a size of about
+// one megapixel
+func defaultDimensions() map[string]Dimensions {
+	return map[string]Dimensions{
+		"1:1":  {Width: 1024, Height: 1024},
+		"4:3":  {Width: 1152, Height: 864},
+		"3:4":  {Width: 864, Height: 1152},
+		"16:9": {Width: 1344, Height: 768},
+		"9:16": {Width: 768, Height: 1344},
+	}
+}
+
+// parseDimensions parses a list of sizes in the form "16:9=1344x768; 1:1=1024x1024".
+// Entries that do not parse or are not multiples of dimensionMultiple are ignored.
+func parseDimensions(val string) map[string]Dimensions {
+	dimensions := map[string]Dimensions{}
+	for _, entry := range strings.Split(val, ";") {
+		ratio, size, found := strings.Cut(entry, "=")
+		ratio = strings.TrimSpace(ratio)
+		if !found || ratio == "" {
+			continue
+		}
+
+		w, h, found := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+		if !found {
+			continue
+		}
+		width, errW := strconv.Atoi(strings.TrimSpace(w))
+		height, errH := strconv.Atoi(strings.TrimSpace(h))
+		if errW != nil || errH != nil || !validDimension(width) || !validDimension(height) {
+			continue
+		}
+
+		dimensions[ratio] = Dimensions{Width: width, Height: height}
+	}
+	return dimensions
+}
+
+// validDimension reports whether n is a positive multiple of dimensionMultiple
+func validDimension(n int) bool {
+	return n > 0 && n%dimensionMultiple == 0
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/joho/godotenv"
+)
+
+// EnvFileVar names the variable pointing at an env file read after all
+// others, e.g. FLUXXXER_ENV_FILE=~/.fluxxxer/prod.env
+const EnvFileVar = "FLUXXXER_ENV_FILE"
+
+// envFileNames are the env files read in each searched directory, the
+// later overriding the earlier
+var envFileNames = []string{".env", ".env.local"}
+
+// envDirs returns the directories searched for env files, from the most
+// general to the most specific: beside the executable, ~/.fluxxxer, the
+// config directory and the working directory
+func envDirs() []string {
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".fluxxxer"))
+	}
+	if dir := os.Getenv("FLUX_CONFIG_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	} else if dir := defaultConfigDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, wd)
+	}
+
+	// A directory read twice would override the ones between
+	var unique []string
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir); err == nil && !slices.Contains(unique, abs) {
+			unique = append(unique, abs)
+		}
+	}
+	return unique
+}
+
+// LoadEnvFiles sets environment variables from the env files found in the
+// searched directories, then from the file named by FLUXXXER_ENV_FILE.
+// Later files override earlier ones, but variables already set in the
+// environment are kept. It returns the files read; errors are returned
+// for files that exist but cannot be read, and for a missing
+// FLUXXXER_ENV_FILE.
+func LoadEnvFiles() ([]string, error) {
+	values := make(map[string]string)
+	var loaded []string
+	var errs []error
+	read := func(path string) error {
+		file, err := godotenv.Read(path)
+		if err != nil {
+			return err
+		}
+		maps.Copy(values, file)
+		loaded = append(loaded, path)
+		return nil
+	}
+
+	for _, dir := range envDirs() {
+		for _, name := range envFileNames {
+			path := filepath.Join(dir, name)
+			if err := read(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to read %s: %w", path, err))
+			}
+		}
+	}
+
+	// The explicit file may itself be named in one of the others
+	explicit, ok := os.LookupEnv(EnvFileVar)
+	if !ok {
+		explicit = values[EnvFileVar]
+	}
+	if explicit != "" {
+		if err := read(explicit); err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s %s: %w", EnvFileVar, explicit, err))
+		}
+	}
+
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); !ok {
+			os.Setenv(key, value)
+		}
+	}
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeEnvFile writes the lines to the env file at path
+func writeEnvFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unsetenv unsets key for the test, restoring it afterwards
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+// chdir changes the working directory for the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadEnvFiles(t *testing.T) {
+	home, configDir, workDir := t.TempDir(), t.TempDir(), t.TempDir()
+	explicit := filepath.Join(t.TempDir(), "prod.env")
+	t.Setenv("HOME", home)
+	t.Setenv("FLUX_CONFIG_DIR", configDir)
+	chdir(t, workDir)
+
+	keys := []string{"ONLY_HOME", "HOME_THEN_CONFIG", "ENV_THEN_LOCAL", "CONFIG_THEN_WORKDIR", "WORKDIR_THEN_EXPLICIT", "ALREADY_SET", EnvFileVar}
+	for _, key := range keys {
+		unsetenv(t, key)
+	}
+	t.Setenv("ALREADY_SET", "real")
+
+	homeEnv := filepath.Join(home, ".fluxxxer", ".env")
+	configEnv := filepath.Join(configDir, ".env")
+	configLocal := filepath.Join(configDir, ".env.local")
+	workEnv := filepath.Join(workDir, ".env")
+	writeEnvFile(t, homeEnv, "ONLY_HOME=home", "HOME_THEN_CONFIG=home", "ALREADY_SET=home")
+	writeEnvFile(t, configEnv, "HOME_THEN_CONFIG=config", "ENV_THEN_LOCAL=env", "CONFIG_THEN_WORKDIR=config")
+	writeEnvFile(t, configLocal, "ENV_THEN_LOCAL=local")
+	writeEnvFile(t, workEnv, "CONFIG_THEN_WORKDIR=workdir", "WORKDIR_THEN_EXPLICIT=workdir", EnvFileVar+"="+explicit)
+	writeEnvFile(t, explicit, "WORKDIR_THEN_EXPLICIT=explicit", "ALREADY_SET=explicit")
+
+	loaded, err := LoadEnvFiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Temporary directories may be reached through a symlink
+	for i, path := range loaded {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			loaded[i] = resolved
+		}
+	}
+	var want []string
+	for _, path := range []string{homeEnv, configEnv, configLocal, workEnv, explicit} {
+		resolved, _ := filepath.EvalSymlinks(path)
+		want = append(want, resolved)
+	}
+	if !slices.Equal(loaded, want) {
+		t.Errorf("got files %v, want %v", loaded, want)
+	}
+
+	wantValues := map[string]string{
+		"ONLY_HOME":             "home",
+		"HOME_THEN_CONFIG":      "config",
+		"ENV_THEN_LOCAL":        "local",
+		"CONFIG_THEN_WORKDIR":   "workdir",
+		"WORKDIR_THEN_EXPLICIT": "explicit",
+		"ALREADY_SET":           "real",
+	}
+	for key, want := range wantValues {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadEnvFilesMissingExplicit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FLUX_CONFIG_DIR", t.TempDir())
+	chdir(t, t.TempDir())
+
+	missing := filepath.Join(t.TempDir(), "missing.env")
+	t.Setenv(EnvFileVar, missing)
+
+	loaded, err := LoadEnvFiles()
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("got error %v, want one naming %s", err, missing)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("got files %v, want none", loaded)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// favoritesFile is the name of the favorite prompts file in the config directory
+const favoritesFile = "favorites.json"
+
+// LoadFavorites reads the favorite prompts saved in dir, in the order they
+// were pinned. A missing favorites file is not an error.
+func LoadFavorites(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, favoritesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read favorites: %w", err)
+	}
+
+	var favorites []string
+	if err := json.Unmarshal(data, &favorites); err != nil {
+		return nil, fmt.Errorf("failed to parse favorites: %w", err)
+	}
+	return favorites, nil
+}
+
+// SaveFavorites writes favorites to dir, replacing any saved before
+func SaveFavorites(dir string, favorites []string) error {
+	if err := WriteJSONFile(dir, favoritesFile, favorites); err != nil {
+		return fmt.Errorf("failed to save favorites: %w", err)
+	}
+	return nil
+}
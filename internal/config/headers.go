@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// headersFile is the name of the file in the config directory holding the
+// API headers of each profile
+const headersFile = "headers.json"
+
+// DefaultProfile is the profile used when neither FLUX_PROFILE nor
+// FLUXXXER_ENV_FILE names one
+const DefaultProfile = "default"
+
+// loadAllHeaders reads the headers saved in dir for every profile.
+// A missing headers file is not an error.
+func loadAllHeaders(dir string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, headersFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+
+	profiles := map[string]map[string]string{}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse headers: %w", err)
+	}
+	return profiles, nil
+}
+
+// LoadHeaders reads the API headers saved in dir for profile
+func LoadHeaders(dir, profile string) (map[string]string, error) {
+	profiles, err := loadAllHeaders(dir)
+	if err != nil {
+		return nil, err
+	}
+	return profiles[profile], nil
+}
+
+// SaveHeaders writes the API headers of profile to dir, keeping those of
+// other profiles
+func SaveHeaders(dir, profile string, headers map[string]string) error {
+	profiles, err := loadAllHeaders(dir)
+	if err != nil {
+		return err
+	}
+	if len(headers) == 0 {
+		delete(profiles, profile)
+	} else {
+		profiles[profile] = headers
+	}
+	// The headers usually hold credentials
+	if err := writePrivateJSONFile(dir, headersFile, profiles); err != nil {
+		return fmt.Errorf("failed to save headers: %w", err)
+	}
+	return nil
+}
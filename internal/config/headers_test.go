@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveHeadersIsPrivate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fluxxxer")
+	headers := map[string]string{"Authorization": "Bearer secret"}
+
+	if err := SaveHeaders(dir, DefaultProfile, headers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, headersFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("headers file has permissions %o, want 600", perm)
+	}
+	info, err = os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("config directory has permissions %o, want 700", perm)
+	}
+
+	saved, err := LoadHeaders(dir, DefaultProfile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved["Authorization"] != "Bearer secret" {
+		t.Errorf("got headers %v, want %v", saved, headers)
+	}
+}
+
+func TestMergeHeaders(t *testing.T) {
+	saved := map[string]string{"Authorization": "Bearer saved", "X-Org-Id": "org"}
+	env := map[string]string{"authorization": "Bearer env"}
+
+	merged := mergeHeaders(saved, env)
+
+	want := map[string]string{"Authorization": "Bearer env", "X-Org-Id": "org"}
+	if len(merged) != len(want) {
+		t.Fatalf("got headers %v, want %v", merged, want)
+	}
+	for name, value := range want {
+		if merged[name] != value {
+			t.Errorf("got %s: %q, want %q", name, merged[name], value)
+		}
+	}
+}
+
+func TestSetSavedHeadersKeepsEnvHeaders(t *testing.T) {
+	cfg := &Config{envHeaders: map[string]string{"Authorization": "Bearer env"}}
+
+	cfg.SetSavedHeaders(map[string]string{"authorization": "Bearer saved", "X-Org-ID": "org"})
+
+	headers := cfg.GetAPIHeaders()
+	if headers["Authorization"] != "Bearer env" {
+		t.Errorf("got Authorization %q, want the FLUX_API_HEADERS value", headers["Authorization"])
+	}
+	if headers["X-Org-Id"] != "org" {
+		t.Errorf("saved header missing from %v", headers)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// presetsFile is the name of the presets file in the config directory
+const presetsFile = "presets.json"
+
+// Preset is a named bundle of generation settings. Settings left empty or
+// zero use the configured or backend default.
+type Preset struct {
+	Name         string  `json:"name"`
+	Prompt       string  `json:"prompt"`
+	AspectRatio  string  `json:"aspect_ratio"`
+	NumOutputs   int     `json:"num_outputs"`
+	OutputFormat string  `json:"output_format,omitempty"`
+	Quality      int     `json:"output_quality,omitempty"`
+	Guidance     float64 `json:"guidance,omitempty"`
+	Steps        int     `json:"steps,omitempty"`
+	Model        string  `json:"model,omitempty"`
+}
+
+// LoadPresets reads the presets saved in dir.
+// A missing presets file is not an error.
+func LoadPresets(dir string) ([]Preset, error) {
+	data, err := os.ReadFile(filepath.Join(dir, presetsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("failed to parse presets: %w", err)
+	}
+	return presets, nil
+}
+
+// SavePresets writes presets to dir, replacing any saved before
+func SavePresets(dir string, presets []Preset) error {
+	if err := WriteJSONFile(dir, presetsFile, presets); err != nil {
+		return fmt.Errorf("failed to save presets: %w", err)
+	}
+	return nil
+}
+
+// WriteJSONFile writes v as indented JSON to name in dir. The data is
+// written to a temporary file first so a failed write keeps the old file.
+func WriteJSONFile(dir, name string, v any) error {
+	return writeJSONFileMode(dir, name, v, 0755, 0644)
+}
+
+// writePrivateJSONFile writes v like WriteJSONFile, readable only by the
+// user, for files holding secrets such as API headers
+func writePrivateJSONFile(dir, name string, v any) error {
+	return writeJSONFileMode(dir, name, v, 0700, 0600)
+}
+
+// writeJSONFileMode writes v as indented JSON to name in dir with the
+// permissions perm, creating dir with dirPerm if it is missing
+func writeJSONFileMode(dir, name string, v any, dirPerm, perm os.FileMode) error {
+	if dir == "" {
+		return errors.New("config directory not available")
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return err
+	}
+
+	// A new temporary file starts readable only by the user, so secrets
+	// are never exposed while it is written
+	tmpFile, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, filepath.Join(dir, name))
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
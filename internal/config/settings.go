@@ -0,0 +1,99 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// settingsFile is the name of the settings file in the config directory
+const settingsFile = "settings.json"
+
+// What happens to the prompt after a generation is started
+const (
+	PromptLeave  = "leave"  // Keep the text as typed
+	PromptClear  = "clear"  // Empty the prompt for the next one
+	PromptSelect = "select" // Select the text so typing replaces it
+)
+
+// What happens when saving over an existing file
+const (
+	ExistingAsk    = "ask"    // Confirm before replacing the file
+	ExistingRename = "rename" // Save beside it with a numbered name
+)
+
+// Which decoders images are loaded with for display
+const (
+	ImageLoaderAuto = "auto" // GDK's loaders, falling back to Go's decoders
+	ImageLoaderGDK  = "gdk"  // GDK's loaders only, the fastest
+	ImageLoaderGo   = "go"   // Go's decoders only, independent of installed loaders
+)
+
+// How hard PNG files the app encodes are compressed
+const (
+	PNGCompressionFast    = "fast"    // Quick saves, larger files
+	PNGCompressionDefault = "default" // Go's usual balance
+	PNGCompressionBest    = "best"    // Smallest files, more CPU time
+)
+
+// Settings holds preferences changed from within the app
+type Settings struct {
+	ResultsPerRow        int      `json:"results_per_row"`        // 0 picks a count for each batch size
+	PromptAfterGenerate  string   `json:"prompt_after_generate"`  // One of the Prompt constants
+	AlsoSaveAs           []string `json:"also_save_as"`           // MIME types of extra copies saved with each image
+	ExistingFiles        string   `json:"existing_files"`         // One of the Existing constants
+	GuidanceStep         float64  `json:"guidance_step"`          // Increment of the guidance spin button
+	StepsStep            int      `json:"steps_step"`             // Increment of the inference steps spin button
+	LowMemory            bool     `json:"low_memory"`             // Download images again instead of keeping their bytes
+	ExpandedPrompt       bool     `json:"expanded_prompt"`        // Edit the prompt in the multi-line editor
+	WheelScrollsSideways bool     `json:"wheel_scrolls_sideways"` // Vertical wheel scrolls results laid out side by side
+	TrayIcon             bool     `json:"tray_icon"`              // Show an icon in the system tray and keep running when closed
+	AutoSave             bool     `json:"auto_save"`              // Save every generated image to the output directory as it loads
+	BlurFlagged          bool     `json:"blur_flagged"`           // Hide images the backend flags as NSFW until clicked
+	UIScale              int      `json:"ui_scale"`               // Size of text and controls in percent, 0 for the usual size
+	ImageLoader          string   `json:"image_loader"`           // One of the ImageLoader constants
+	PNGCompression       string   `json:"png_compression"`        // One of the PNGCompression constants
+}
+
+// defaultSettings returns the settings used before any are saved
+func defaultSettings() Settings {
+	return Settings{
+		PromptAfterGenerate:  PromptLeave,
+		ExistingFiles:        ExistingAsk,
+		GuidanceStep:         0.1,
+		StepsStep:            1,
+		WheelScrollsSideways: true,
+		BlurFlagged:          true,
+		ImageLoader:          ImageLoaderAuto,
+		PNGCompression:       PNGCompressionDefault,
+	}
+}
+
+// LoadSettings reads the settings saved in dir.
+// A missing settings file yields the defaults.
+func LoadSettings(dir string) (Settings, error) {
+	settings := defaultSettings()
+
+	data, err := os.ReadFile(filepath.Join(dir, settingsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaultSettings(), fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings writes settings to dir
+func SaveSettings(dir string, settings Settings) error {
+	if err := WriteJSONFile(dir, settingsFile, settings); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// statsFile is the name of the usage statistics file in the config directory
+const statsFile = "stats.json"
+
+// Stats counts usage across sessions
+type Stats struct {
+	Images          int64          `json:"images"`           // Images generated
+	APICalls        int64          `json:"api_calls"`        // Generation requests, including failed ones
+	BytesDownloaded int64          `json:"bytes_downloaded"` // Image data downloaded
+	Generations     int64          `json:"generations"`      // Successful generation requests
+	GenerationTime  time.Duration  `json:"generation_time"`  // Total time of successful generations
+	AspectRatios    map[string]int `json:"aspect_ratios"`    // Successful generations per aspect ratio
+}
+
+// AverageGenerationTime returns the mean duration of successful generations
+func (s Stats) AverageGenerationTime() time.Duration {
+	if s.Generations == 0 {
+		return 0
+	}
+	return s.GenerationTime / time.Duration(s.Generations)
+}
+
+// MostUsedAspectRatio returns the aspect ratio generated most often, or an
+// empty string before the first generation
+func (s Stats) MostUsedAspectRatio() string {
+	best := ""
+	for ratio, count := range s.AspectRatios {
+		if count > s.AspectRatios[best] || (count == s.AspectRatios[best] && ratio < best) {
+			best = ratio
+		}
+	}
+	return best
+}
+
+// LoadStats reads the statistics saved in dir.
+// A missing statistics file yields zero counts.
+func LoadStats(dir string) (Stats, error) {
+	var stats Stats
+
+	data, err := os.ReadFile(filepath.Join(dir, statsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read statistics: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return Stats{}, fmt.Errorf("failed to parse statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// SaveStats writes stats to dir
+func SaveStats(dir string, stats Stats) error {
+	if err := WriteJSONFile(dir, statsFile, stats); err != nil {
+		return fmt.Errorf("failed to save statistics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// variablesFile is the name of the prompt variables file in the config directory
+const variablesFile = "variables.json"
+
+// Variable is a named value that prompts refer to as $name
+type Variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LoadVariables reads the prompt variables saved in dir.
+// A missing variables file is not an error.
+func LoadVariables(dir string) ([]Variable, error) {
+	data, err := os.ReadFile(filepath.Join(dir, variablesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables: %w", err)
+	}
+
+	var variables []Variable
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("failed to parse variables: %w", err)
+	}
+	return variables, nil
+}
+
+// SaveVariables writes variables to dir, replacing any saved before
+func SaveVariables(dir string, variables []Variable) error {
+	if err := WriteJSONFile(dir, variablesFile, variables); err != nil {
+		return fmt.Errorf("failed to save variables: %w", err)
+	}
+	return nil
+}
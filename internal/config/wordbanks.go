@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// wordBanksFile is the name of the word banks file in the config directory
+const wordBanksFile = "wordbanks.json"
+
+// WordBanks holds the categorized words used to assemble random prompts
+type WordBanks struct {
+	Subjects     []string `json:"subject"`
+	Styles       []string `json:"style"`
+	Lighting     []string `json:"lighting"`
+	Compositions []string `json:"composition"`
+}
+
+// DefaultWordBanks returns the word banks bundled with the app
+func DefaultWordBanks() WordBanks {
+	return WordBanks{
+		Subjects: []string{
+			"a lighthouse on a rocky coast", "a fox in a snowy forest", "an astronaut tending a garden",
+			"a bustling night market", "a vintage car on a desert highway", "a cat napping on a bookshelf",
+			"a floating island with waterfalls", "an old fisherman mending nets", "a treehouse village",
+			"a robot painting a portrait", "a dragon curled around a castle tower", "a cozy mountain cabin",
+		},
+		Styles: []string{
+			"watercolor painting", "cinematic photograph", "studio ghibli style", "oil painting",
+			"isometric 3d render", "ukiyo-e woodblock print", "pixel art", "art nouveau poster",
+			"analog film photo", "charcoal sketch", "low poly illustration", "surrealist painting",
+		},
+		Lighting: []string{
+			"golden hour light", "soft overcast light", "neon glow", "dramatic rim lighting",
+			"candlelight", "moonlight", "volumetric fog and light rays", "harsh midday sun",
+			"bioluminescent glow", "blue hour",
+		},
+		Compositions: []string{
+			"wide angle shot", "close-up portrait", "aerial view", "symmetrical composition",
+			"rule of thirds", "low angle shot", "macro detail", "panoramic vista",
+			"centered subject with shallow depth of field", "dutch angle",
+		},
+	}
+}
+
+// LoadWordBanks reads the word banks from dir, falling back to the bundled
+// words for a missing file or empty category. A missing file is created with
+// the bundled words so it can be edited.
+func LoadWordBanks(dir string) (WordBanks, error) {
+	defaults := DefaultWordBanks()
+
+	data, err := os.ReadFile(filepath.Join(dir, wordBanksFile))
+	if errors.Is(err, os.ErrNotExist) {
+		// Failing to create the file only means it cannot be edited yet
+		WriteJSONFile(dir, wordBanksFile, defaults)
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, fmt.Errorf("failed to read word banks: %w", err)
+	}
+
+	var banks WordBanks
+	if err := json.Unmarshal(data, &banks); err != nil {
+		return defaults, fmt.Errorf("failed to parse word banks: %w", err)
+	}
+
+	if len(banks.Subjects) == 0 {
+		banks.Subjects = defaults.Subjects
+	}
+	if len(banks.Styles) == 0 {
+		banks.Styles = defaults.Styles
+	}
+	if len(banks.Lighting) == 0 {
+		banks.Lighting = defaults.Lighting
+	}
+	if len(banks.Compositions) == 0 {
+		banks.Compositions = defaults.Compositions
+	}
+	return banks, nil
+}
@@ -6,7 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"mime"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,6 +25,20 @@ type Config interface {
 	GetDefaultFormat() string
 	GetDefaultQuality() int
 	GetDisableSafetyCheck() bool
+	GetAPIHeaders() map[string]string
+	GetHealthURL() string
+	GetWebhookURL() string
+	GetSendDimensions() bool
+	GetDimensions(aspectRatio string) (width, height int, ok bool)
+	GetImageTimeout() time.Duration
+	GetMaxImageSize() int64
+	GetImageAuthorization() string
+	GetResponseFormat() string
+	GetEmptyRetries() int
+	GetMaxIdleConnsPerHost() int
+	GetIdleConnTimeout() time.Duration
+	GetKeepAlive() time.Duration
+	GetDebug() bool
 }
 
 // Client manages API communication with the Flux service
@@ -25,17 +46,53 @@ type Client struct {
 	apiURL     string
 	httpClient *http.Client
 	config     Config
+	parser     OutputParser
+	pending    *PendingStore
+
+	lastMu sync.Mutex
+	last   *RawResponse // Last response received, for inspection
 }
 
-// NewClient creates a new Flux API client
+// requestTimeout bounds submitting a generation request
+const requestTimeout = 30 * time.Second
+
+// NewClient creates a new Flux API client. Requests are bounded by their
+// contexts, so image downloads can have a timeout of their own, and by a
+// client timeout covering the longest of them in case a context has none.
 func NewClient(config Config) *Client {
-	return &Client{
+	return NewClientWithHTTP(config, &http.Client{
+		Transport: newTransport(config),
+		Timeout:   max(requestTimeout, config.GetImageTimeout()),
+	})
+}
+
+// NewClientWithHTTP creates a Flux API client that sends requests through
+// httpClient, such as the client of an httptest.Server in tests
+func NewClientWithHTTP(config Config, httpClient *http.Client) *Client {
+	// An unknown response format falls back to detecting it
+	parser, err := ParserFor(config.GetResponseFormat())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v, detecting it instead\n", err)
+		parser = autoParser{}
+	}
+
+	c := &Client{
 		apiURL: config.GetAPIEndpoint(),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
 		config: config,
+		parser: parser,
 	}
+
+	// A copy, so the caller's client keeps its own redirect policy
+	client := *httpClient
+	client.CheckRedirect = c.checkRedirect(httpClient.CheckRedirect)
+	c.httpClient = &client
+	return c
+}
+
+// SetPendingStore records predictions of asynchronous backends in store while
+// they are polled, so they can be resumed after an interruption
+func (c *Client) SetPendingStore(store *PendingStore) {
+	c.pending = store
 }
 
 // GenerateOptions represents options for image generation
@@ -45,8 +102,63 @@ type GenerateOptions struct {
 	OutputFormat string
 	Quality      int
 	Seed         *int
+	Image        string  // Base image for image-to-image, as a URL or data: URL
+	ImageSource  string  // URL or file path Image was loaded from, kept to load it again but never sent
+	Guidance     float64 // Prompt guidance scale, 0 for the backend default
+	Steps        int     // Inference steps, 0 for the backend default
+	Model        string  // Model for backends serving several, empty for the backend default
+}
+
+// BuildPayload returns the JSON request body sent for a generation,
+// in the form {"input": {...}}. The aspect ratio is sent as width and height
+// when the config asks for dimensions.
+func (c *Client) BuildPayload(prompt string, opts GenerateOptions) ([]byte, error) {
+	input := NewInput(prompt, opts)
+	input.DisableSafetyCheck = c.config.GetDisableSafetyCheck()
+
+	// Some backends take explicit dimensions instead of a ratio
+	if c.config.GetSendDimensions() {
+		width, height, ok := c.config.GetDimensions(opts.AspectRatio)
+		if !ok {
+			return nil, fmt.Errorf("no dimensions configured for aspect ratio %q", opts.AspectRatio)
+		}
+		input.AspectRatio = ""
+		input.Width = width
+		input.Height = height
+	}
+
+	payload := map[string]interface{}{"input": input}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return jsonData, nil
+}
+
+// GenerateResult holds the outcome of a generation request
+type GenerateResult struct {
+	URLs  []string
+	Seed  int   // Seed used for the generation
+	Seeds []int // Seed of each image in URLs, nil unless the backend reports them
+
+	// Flagged tells whether each image in URLs was flagged as NSFW by the
+	// backend, nil when none was
+	Flagged []bool
+
+	// FormatFallback is the requested output format when the backend
+	// rejected it and the images were generated as PNG instead
+	FormatFallback string
+
+	// EmptyRetries is how many times the request was sent again because
+	// the backend returned no images
+	EmptyRetries int
 }
 
+// fallbackFormat is the output format used when the backend rejects the
+// requested one, as every backend supports it
+const fallbackFormat = "png"
+
 // GenerateImages creates images based on the provided prompt
 func (c *Client) GenerateImages(prompt string) ([]string, error) {
 	return c.GenerateImagesWithOptions(prompt, GenerateOptions{
@@ -59,53 +171,199 @@ func (c *Client) GenerateImages(prompt string) ([]string, error) {
 
 // GenerateImagesWithOptions creates images with custom options
 func (c *Client) GenerateImagesWithOptions(prompt string, opts GenerateOptions) ([]string, error) {
+	result, err := c.Generate(context.Background(), prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.URLs, nil
+}
+
+// Waits before sending a generation that returned no images again, doubling
+// up to emptyRetryMaxDelay
+const (
+	emptyRetryDelay    = time.Second
+	emptyRetryMaxDelay = 8 * time.Second
+)
+
+// Generate creates images with custom options and reports the seed used.
+// When no seed is given a random one is sent so the result can be reproduced.
+func (c *Client) Generate(ctx context.Context, prompt string, opts GenerateOptions) (*GenerateResult, error) {
 	if prompt == "" {
 		return nil, errors.New("prompt cannot be empty")
 	}
 
+	if opts.Seed == nil {
+		seed := rand.IntN(math.MaxInt32)
+		opts.Seed = &seed
+	}
+
 	if c.apiURL == "" {
 		return nil, errors.New("API URL not configured")
 	}
 
-	input := Input{
-		Prompt:             prompt,
-		NumOutputs:         opts.NumOutputs,
-		AspectRatio:        opts.AspectRatio,
-		OutputFormat:       opts.OutputFormat,
-		OutputQuality:      opts.Quality,
-		DisableSafetyCheck: c.config.GetDisableSafetyCheck(),
-		Seed:               opts.Seed,
+	if isMockURL(c.apiURL) {
+		return c.generateMock(ctx, opts)
 	}
 
-	payload := map[string]interface{}{"input": input}
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	result, err := c.generateOnce(ctx, prompt, opts)
+
+	// Flaky backends occasionally return no images for a request that works
+	// when sent again a little later. Failures with a reason, such as safety
+	// rejections, are not retried since they would fail the same way.
+	retries := c.config.GetEmptyRetries()
+	delay := emptyRetryDelay
+	for retry := 1; retry <= retries && errors.Is(err, ErrEmptyResult); retry++ {
+		fmt.Fprintf(os.Stderr, "No images returned, retrying in %s (%d of %d)\n", delay, retry, retries)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay = min(delay*2, emptyRetryMaxDelay)
+
+		result, err = c.generateOnce(ctx, prompt, opts)
+		if err == nil {
+			result.EmptyRetries = retry
+		}
+	}
+	if retries > 0 && errors.Is(err, ErrEmptyResult) {
+		return nil, &emptyResultError{retries: retries}
 	}
+	return result, err
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// generateOnce sends the generation request, retrying once as PNG if the
+// backend rejects the output format
+func (c *Client) generateOnce(ctx context.Context, prompt string, opts GenerateOptions) (*GenerateResult, error) {
+	result, err := c.submit(ctx, prompt, opts)
+
+	// Backends support different formats, so one they reject is retried
+	// once as PNG
+	if err != nil && opts.OutputFormat != fallbackFormat && isFormatRejection(err, opts.OutputFormat) {
+		requested := opts.OutputFormat
+		opts.OutputFormat = fallbackFormat
+		result, err = c.submit(ctx, prompt, opts)
+		if err == nil {
+			result.FormatFallback = requested
+		}
+	}
+	return result, err
+}
+
+// newGenerateRequest builds the request sent for a generation and returns
+// it with its body
+func (c *Client) newGenerateRequest(ctx context.Context, prompt string, opts GenerateOptions) (*http.Request, []byte, error) {
+	jsonData, err := c.BuildPayload(prompt, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", acceptHeader(c.config.GetResponseFormat(), opts.OutputFormat))
+	for name, value := range c.config.GetAPIHeaders() {
+		req.Header.Set(name, value)
+	}
+	return req, jsonData, nil
+}
+
+// submit sends one generation request and waits for its result
+func (c *Client) submit(ctx context.Context, prompt string, opts GenerateOptions) (*GenerateResult, error) {
+	// The timeout covers submitting the request; polling has its own
+	reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, _, err := c.newGenerateRequest(reqCtx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.GetDebug() {
+		logRequest(req)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned non-200 status code: %d", resp.StatusCode)
+	// Asynchronous backends answer 201 or 202 with a prediction to poll
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+	default:
+		// The body usually explains the failure, e.g. a safety rejection
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		c.recordResponse(resp, body)
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	output, err := c.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if pred := output.prediction; pred != nil {
+		return c.Resume(ctx, Pending{
+			ID:          pred.ID,
+			PollURL:     pred.pollURL(),
+			Prompt:      prompt,
+			Seed:        *opts.Seed,
+			NumOutputs:  opts.NumOutputs,
+			AspectRatio: opts.AspectRatio,
+			CreatedAt:   time.Now(),
+		})
+	}
+	if len(output.URLs) == 0 {
+		return nil, ErrEmptyResult
 	}
 
-	var urls []string
-	if err := json.NewDecoder(resp.Body).Decode(&urls); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// Prefer the seed reported by the backend when it includes one
+	result := &GenerateResult{URLs: output.URLs, Seed: *opts.Seed, Seeds: output.Seeds, Flagged: output.Flagged}
+	if output.Seed != nil {
+		result.Seed = *output.Seed
 	}
 
-	return urls, nil
+	return result, nil
+}
+
+// readResponse extracts the image URLs and optional seeds from a generation
+// response. Multipart and image responses carry the images inline; anything
+// else is handed to the configured output parser. A prediction that is still running
+// is returned to be polled.
+func (c *Client) readResponse(resp *http.Response) (*Output, error) {
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(mediaType, "multipart/") {
+		c.recordResponse(resp, nil)
+		urls, seed, err := decodeMultipartResponse(resp.Body, params["boundary"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: multipart: %w", ErrDecode, err)
+		}
+		return &Output{URLs: urls, Seed: seed}, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	c.recordResponse(resp, body)
+
+	// Backends answering with the image itself return a single image
+	if strings.HasPrefix(mediaType, "image/") {
+		return &Output{URLs: []string{imageDataURL(mediaType, body)}}, nil
+	}
+	if err := htmlResponseError(resp, body, "JSON"); err != nil {
+		return nil, err
+	}
+
+	output, err := c.parser.Parse(body)
+	if err != nil {
+		// Failed predictions are reported as such, not as decode errors
+		if errors.Is(err, ErrPredictionFailed) || errors.Is(err, ErrSafetyRejected) || errors.Is(err, ErrEmptyResult) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+	return output, nil
 }
@@ -0,0 +1,204 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testConfig is a Config with fixed values for tests
+type testConfig struct {
+	endpoint  string
+	imageAuth string
+}
+
+func (c testConfig) GetAPIEndpoint() string              { return c.endpoint }
+func (testConfig) GetDefaultNumOutputs() int             { return 1 }
+func (testConfig) GetDefaultAspectRatio() string         { return "1:1" }
+func (testConfig) GetDefaultFormat() string              { return "png" }
+func (testConfig) GetDefaultQuality() int                { return 90 }
+func (testConfig) GetDisableSafetyCheck() bool           { return false }
+func (testConfig) GetAPIHeaders() map[string]string      { return nil }
+func (testConfig) GetHealthURL() string                  { return "" }
+func (testConfig) GetWebhookURL() string                 { return "" }
+func (testConfig) GetSendDimensions() bool               { return false }
+func (testConfig) GetDimensions(string) (int, int, bool) { return 0, 0, false }
+func (testConfig) GetImageTimeout() time.Duration        { return 5 * time.Second }
+func (testConfig) GetMaxImageSize() int64                { return 1 << 20 }
+func (c testConfig) GetImageAuthorization() string       { return c.imageAuth }
+func (testConfig) GetResponseFormat() string             { return ResponseFormatAuto }
+func (testConfig) GetEmptyRetries() int                  { return 0 }
+func (testConfig) GetMaxIdleConnsPerHost() int           { return 0 }
+func (testConfig) GetIdleConnTimeout() time.Duration     { return 0 }
+func (testConfig) GetKeepAlive() time.Duration           { return 0 }
+func (testConfig) GetDebug() bool                        { return false }
+
+// newTestClient returns a client sending its requests to a server
+// answering with handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClientWithHTTP(testConfig{endpoint: server.URL}, server.Client()), server
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantURLs []string
+		wantCode int   // Status code of an expected APIStatusError
+		wantErr  error // Expected error, matched with errors.Is
+	}{
+		{
+			name:     "urls",
+			status:   http.StatusOK,
+			body:     `{"output": ["https://cdn.example.com/a.png", "https://cdn.example.com/b.png"]}`,
+			wantURLs: []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+		},
+		{
+			name:     "array",
+			status:   http.StatusOK,
+			body:     `["https://cdn.example.com/a.png"]`,
+			wantURLs: []string{"https://cdn.example.com/a.png"},
+		},
+		{
+			name:     "bad request",
+			status:   http.StatusBadRequest,
+			body:     `{"error": "invalid aspect_ratio"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			body:     "internal error",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "malformed body",
+			status:  http.StatusOK,
+			body:    `{"output": [`,
+			wantErr: ErrDecode,
+		},
+		{
+			name:    "empty output",
+			status:  http.StatusOK,
+			body:    `{"output": []}`,
+			wantErr: ErrEmptyResult,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			seed := 42
+			result, err := client.Generate(context.Background(), "a cat", GenerateOptions{NumOutputs: 1, Seed: &seed})
+
+			switch {
+			case tt.wantCode != 0:
+				var statusErr *APIStatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("got error %v, want APIStatusError", err)
+				}
+				if statusErr.Code != tt.wantCode {
+					t.Errorf("got status code %d, want %d", statusErr.Code, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !slices.Equal(result.URLs, tt.wantURLs) {
+					t.Errorf("got URLs %v, want %v", result.URLs, tt.wantURLs)
+				}
+				if result.Seed != seed {
+					t.Errorf("got seed %d, want %d", result.Seed, seed)
+				}
+			}
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\nimage data")
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		want     []byte
+		wantCode int   // Status code of an expected APIStatusError
+		wantErr  error // Expected error, matched with errors.Is
+	}{
+		{
+			name: "complete",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.Write(image)
+			},
+			want: image,
+		},
+		{
+			name: "short body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Advertise more than is sent, so the transfer is cut off
+				w.Header().Set("Content-Type", "image/png")
+				w.Header().Set("Content-Length", "1000")
+				w.Write(image)
+			},
+			wantErr: ErrIncompleteDownload,
+		},
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t, tt.handler)
+
+			data, contentType, err := client.Download(context.Background(), server.URL+"/image.png")
+
+			switch {
+			case tt.wantCode != 0:
+				var statusErr *APIStatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("got error %v, want APIStatusError", err)
+				}
+				if statusErr.Code != tt.wantCode {
+					t.Errorf("got status code %d, want %d", statusErr.Code, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(data) != string(tt.want) {
+					t.Errorf("got %q, want %q", data, tt.want)
+				}
+				if contentType != "image/png" {
+					t.Errorf("got content type %q, want image/png", contentType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxDownloadResumes is how many times an interrupted image download is
+// continued, or started over when the server cannot resume it
+const maxDownloadResumes = 3
+
+// download is an image download that may take several requests. The body
+// received so far is kept in a temporary file, so an interrupted download
+// of a large image does not hold on to it in memory while resuming.
+type download struct {
+	file        *os.File
+	written     int64 // Bytes of the body in file
+	writeErr    error // First error writing to file
+	contentType string
+	resumable   bool // The server accepts byte range requests
+}
+
+// Write appends p to the body received so far
+func (d *download) Write(p []byte) (int, error) {
+	n, err := d.file.Write(p)
+	d.written += int64(n)
+	if err != nil && d.writeErr == nil {
+		d.writeErr = err
+	}
+	return n, err
+}
+
+// restart drops the body received so far
+func (d *download) restart() error {
+	d.written = 0
+	if err := d.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := d.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// Download fetches the image at url and returns its bytes and content type.
+// A download cut off partway is resumed with a Range request when the
+// server accepts them, and started over otherwise.
+func (c *Client) Download(ctx context.Context, url string) ([]byte, string, error) {
+	if isMockURL(url) {
+		data, err := renderMockImage(url)
+		return data, "image/png", err
+	}
+
+	// Images from multipart responses are already in memory
+	if isDataURL(url) {
+		return decodeDataURL(url)
+	}
+
+	// The timeout covers every attempt together
+	timeout := c.config.GetImageTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	file, err := os.CreateTemp("", "fluxxxer-download-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	d := download{file: file}
+	for attempt := 0; ; attempt++ {
+		err := c.fetchImage(ctx, url, &d)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, "", fmt.Errorf("%w after %s: %s", ErrDownloadTimeout, timeout, url)
+		}
+		if err == nil {
+			data, err := os.ReadFile(file.Name())
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read download file: %w", err)
+			}
+			return data, d.contentType, nil
+		}
+		if !errors.Is(err, ErrIncompleteDownload) || attempt == maxDownloadResumes {
+			return nil, "", err
+		}
+
+		if d.resumable {
+			fmt.Fprintf(os.Stderr, "Download of %s interrupted, resuming after %d bytes: %v\n", url, d.written, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Download of %s interrupted, starting over: %v\n", url, err)
+			if err := d.restart(); err != nil {
+				return nil, "", fmt.Errorf("failed to clear download file: %w", err)
+			}
+		}
+	}
+}
+
+// fetchImage requests the image at url and appends the body to d, asking
+// only for the rest of the image when d already holds part of it. A body
+// cut off partway is an ErrIncompleteDownload, with what arrived kept in d.
+func (c *Client) fetchImage(ctx context.Context, url string, d *download) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	offset := d.written
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	c.authorizeImage(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+
+	// size is the full size of the image, -1 when unknown
+	size := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		var start, end int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil || start != offset {
+			// Not the part asked for, so start over
+			d.resumable = false
+			if err := d.restart(); err != nil {
+				return fmt.Errorf("failed to clear download file: %w", err)
+			}
+			return fmt.Errorf("%w: unexpected range %q", ErrIncompleteDownload, resp.Header.Get("Content-Range"))
+		}
+	case (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && req.Header.Get("Authorization") == "":
+		return fmt.Errorf("failed to download image: %w (set FLUX_IMAGE_AUTH or FLUX_IMAGE_TOKEN if the backend requires a token for its images)", &APIStatusError{Code: resp.StatusCode})
+	case resp.StatusCode == http.StatusOK:
+		// Image hosts that need a login redirect to it, as the backend may
+		if err := htmlResponseError(resp, nil, "an image"); err != nil {
+			return err
+		}
+
+		// The whole image, even if a range was asked for
+		if err := d.restart(); err != nil {
+			return fmt.Errorf("failed to clear download file: %w", err)
+		}
+		d.contentType = resp.Header.Get("Content-Type")
+		d.resumable = resp.Header.Get("Accept-Ranges") == "bytes"
+		if resp.ContentLength >= 0 {
+			size = resp.ContentLength
+		}
+	default:
+		return fmt.Errorf("failed to download image: %w", &APIStatusError{Code: resp.StatusCode})
+	}
+
+	// Read one byte past the limit to tell a full-size image from a larger one
+	maxSize := c.config.GetMaxImageSize()
+	if size > maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d: %s", ErrImageTooLarge, size, maxSize, url)
+	}
+	_, err = io.Copy(d, io.LimitReader(resp.Body, maxSize+1-d.written))
+	if d.writeErr != nil {
+		return fmt.Errorf("failed to save image download: %w", d.writeErr)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if d.written > maxSize {
+		return fmt.Errorf("%w: over the limit of %d bytes: %s", ErrImageTooLarge, maxSize, url)
+	}
+
+	// A body shorter than the advertised length means the transfer was cut off
+	if err != nil || (size >= 0 && d.written != size) {
+		message := fmt.Sprintf("received %d bytes", d.written)
+		if size >= 0 {
+			message = fmt.Sprintf("received %d of %d bytes", d.written, size)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrIncompleteDownload, message, err)
+		}
+		return fmt.Errorf("%w: %s", ErrIncompleteDownload, message)
+	}
+	return nil
+}
+
+// authorizeImage adds the configured Authorization header to an image
+// request for the backend's own origin. Images on other hosts, such as
+// CDNs, never receive the token, and checkRedirect drops it when a redirect
+// leads elsewhere.
+func (c *Client) authorizeImage(req *http.Request) {
+	auth := c.config.GetImageAuthorization()
+	if auth == "" {
+		return
+	}
+	api, err := url.Parse(c.apiURL)
+	if err != nil || !sameOrigin(req.URL, api) {
+		return
+	}
+	req.Header.Set("Authorization", auth)
+}
+
+// maxRedirects is how many redirects a request follows, as in net/http
+const maxRedirects = 10
+
+// checkRedirect returns the redirect policy of the client's requests. The
+// Authorization header is kept only on redirects to the backend's own
+// origin: Go's client keeps it on redirects to the same host or its
+// subdomains even when the scheme or port changes. next is the policy of
+// the client passed in, if any, applied afterwards.
+func (c *Client) checkRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		api, err := url.Parse(c.apiURL)
+		if err != nil || !sameOrigin(req.URL, api) {
+			req.Header.Del("Authorization")
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+}
+
+// sameOrigin reports whether a and b share scheme, host and port, with
+// default ports made explicit
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		originPort(a) == originPort(b)
+}
+
+// originPort returns the port of u, or the default port of its scheme
+func originPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
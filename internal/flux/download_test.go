@@ -0,0 +1,139 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://api.example.com/v1", "https://api.example.com/images/a.png", true},
+		{"https://api.example.com", "https://API.example.com:443/a.png", true},
+		{"http://api.example.com", "http://api.example.com:80/a.png", true},
+		{"http://localhost:8080", "http://localhost:8080/a.png", true},
+		{"https://api.example.com", "http://api.example.com/a.png", false},
+		{"https://api.example.com", "https://api.example.com:8443/a.png", false},
+		{"https://api.example.com", "https://cdn.api.example.com/a.png", false},
+		{"https://api.example.com", "https://example.com/a.png", false},
+		{"http://localhost:8080", "http://localhost:9090/a.png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, _ := url.Parse(tt.a)
+			b, _ := url.Parse(tt.b)
+			if got := sameOrigin(a, b); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadRedirectAuthorization(t *testing.T) {
+	const token = "Bearer secret"
+	image := []byte("\x89PNG\r\n\x1a\nimage data")
+
+	// Another origin on the same host, which Go's client would send the
+	// token to
+	var elsewhereAuth string
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		elsewhereAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	}))
+	t.Cleanup(elsewhere.Close)
+
+	var backendAuth string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/image.png", http.StatusFound)
+		case "/elsewhere":
+			http.Redirect(w, r, elsewhere.URL+"/image.png", http.StatusFound)
+		default:
+			backendAuth = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(image)
+		}
+	}))
+	t.Cleanup(backend.Close)
+
+	client := NewClientWithHTTP(testConfig{endpoint: backend.URL, imageAuth: token}, backend.Client())
+
+	if _, _, err := client.Download(context.Background(), backend.URL+"/moved"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backendAuth != token {
+		t.Errorf("got Authorization %q on a redirect to the backend, want %q", backendAuth, token)
+	}
+
+	if _, _, err := client.Download(context.Background(), backend.URL+"/elsewhere"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elsewhereAuth != "" {
+		t.Errorf("got Authorization %q on a redirect to another origin, want none", elsewhereAuth)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	image := bytes.Repeat([]byte("\x89PNG\r\n\x1a\nimage data "), 100)
+	half := len(image) / 2
+
+	tests := []struct {
+		name    string
+		partial bool // The server answers the Range request with 206
+	}{
+		{"206 with the rest", true},
+		{"200 with the whole image", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Header.Get("Range"))
+				w.Header().Set("Content-Type", "image/png")
+				w.Header().Set("Accept-Ranges", "bytes")
+
+				switch {
+				case len(requests) == 1:
+					// Cut the transfer off halfway through
+					w.Header().Set("Content-Length", fmt.Sprint(len(image)))
+					w.Write(image[:half])
+				case tt.partial:
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(image)-1, len(image)))
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write(image[half:])
+				default:
+					w.Write(image)
+				}
+			})
+
+			data, contentType, err := client.Download(context.Background(), server.URL+"/image.png")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(data, image) {
+				t.Errorf("got %d bytes, want the %d bytes of the image", len(data), len(image))
+			}
+			if contentType != "image/png" {
+				t.Errorf("got content type %q, want image/png", contentType)
+			}
+
+			if len(requests) != 2 {
+				t.Fatalf("got %d requests, want 2", len(requests))
+			}
+			if wantRange := fmt.Sprintf("bytes=%d-", half); requests[1] != wantRange {
+				t.Errorf("got Range %q on the second request, want %q", requests[1], wantRange)
+			}
+		})
+	}
+}
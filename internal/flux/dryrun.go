@@ -0,0 +1,42 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DryRun returns the request a generation would send, for checking it
+// without sending it: the request line, the headers with secrets redacted
+// and the pretty-printed payload. Without a seed in opts, Generate picks a
+// random one when sending, which the payload leaves out.
+func (c *Client) DryRun(prompt string, opts GenerateOptions) (string, error) {
+	if prompt == "" {
+		return "", errors.New("prompt cannot be empty")
+	}
+	if c.apiURL == "" {
+		return "", errors.New("API URL not configured")
+	}
+
+	req, body, err := c.newGenerateRequest(context.Background(), prompt, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL.Redacted())
+	for _, line := range redactedHeaders(req.Header) {
+		fmt.Fprintln(&b, line)
+	}
+	b.WriteString("\n")
+	b.Write(pretty.Bytes())
+	return b.String(), nil
+}
@@ -0,0 +1,226 @@
+package flux
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrSafetyRejected is returned when the backend refuses a prompt because of
+// its content filter
+var ErrSafetyRejected = errors.New("prompt rejected by safety filter")
+
+// ErrIncompleteDownload is returned when an image download ends before the
+// advertised Content-Length was received
+var ErrIncompleteDownload = errors.New("incomplete download")
+
+// ErrDownloadTimeout is returned when an image download takes longer than
+// the configured image timeout
+var ErrDownloadTimeout = errors.New("image download timed out")
+
+// ErrImageTooLarge is returned when an image exceeds the configured size limit
+var ErrImageTooLarge = errors.New("image too large")
+
+// ErrPredictionFailed is returned when an asynchronous backend reports that
+// a prediction failed or was canceled
+var ErrPredictionFailed = errors.New("prediction failed")
+
+// ErrNetwork is returned when the backend could not be reached or the
+// connection failed before a response arrived
+var ErrNetwork = errors.New("request failed")
+
+// ErrDecode is returned when a response could not be understood
+var ErrDecode = errors.New("failed to decode response")
+
+// ErrEmptyResult is returned when a generation succeeded without images
+var ErrEmptyResult = errors.New("no images returned")
+
+// emptyResultError is returned when a generation still returned no images
+// after being retried
+type emptyResultError struct {
+	retries int
+}
+
+func (e *emptyResultError) Error() string {
+	return fmt.Sprintf("%v after %d retries", ErrEmptyResult, e.retries)
+}
+
+func (e *emptyResultError) Unwrap() error {
+	return ErrEmptyResult
+}
+
+// EmptyRetries returns how many times a generation that failed with err was
+// sent again because it returned no images
+func EmptyRetries(err error) int {
+	var emptyErr *emptyResultError
+	if !errors.As(err, &emptyErr) {
+		return 0
+	}
+	return emptyErr.retries
+}
+
+// ErrAuthRequired is returned when the backend answers with a login page,
+// usually after redirecting a request without valid credentials
+var ErrAuthRequired = errors.New("authentication required")
+
+// APIStatusError is returned when the backend answers with an unexpected
+// status code. Safety rejections also match ErrSafetyRejected.
+type APIStatusError struct {
+	Code int
+	Body string // Readable message from the response body, if any
+
+	safety bool
+}
+
+func (e *APIStatusError) Error() string {
+	switch {
+	case e.safety:
+		return fmt.Sprintf("%v: %s", ErrSafetyRejected, e.Body)
+	case e.Body != "":
+		return fmt.Sprintf("API returned non-200 status code: %d: %s", e.Code, e.Body)
+	default:
+		return fmt.Sprintf("API returned non-200 status code: %d", e.Code)
+	}
+}
+
+// Unwrap lets errors.Is recognize safety rejections
+func (e *APIStatusError) Unwrap() error {
+	if e.safety {
+		return ErrSafetyRejected
+	}
+	return nil
+}
+
+// safetyKeywords identify content filter rejections in error responses
+var safetyKeywords = []string{"nsfw", "safety", "content policy", "flagged"}
+
+// apiError builds the error for a non-200 response, recognizing safety
+// rejections so callers can offer to edit the prompt
+func apiError(statusCode int, body []byte) error {
+	message := errorMessage(body)
+	return &APIStatusError{
+		Code:   statusCode,
+		Body:   message,
+		safety: isSafetyMessage(message),
+	}
+}
+
+// formatFieldPattern finds the output format field in validation errors
+// such as "input.output_format: must be one of png, jpg" or FastAPI's
+// {"loc": ["body", "output_format"]}
+var formatFieldPattern = regexp.MustCompile(`\boutput_format\b`)
+
+// isFormatRejection reports whether err is the backend refusing the output
+// format, as opposed to another invalid input. Only errors naming the
+// output_format field count: the format name alone may be part of the
+// prompt or another value echoed back.
+func isFormatRejection(err error, format string) bool {
+	var statusErr *APIStatusError
+	if format == "" || !errors.As(err, &statusErr) || statusErr.safety {
+		return false
+	}
+	if statusErr.Code != http.StatusBadRequest && statusErr.Code != http.StatusUnprocessableEntity {
+		return false
+	}
+	return formatFieldPattern.MatchString(strings.ToLower(statusErr.Body))
+}
+
+// outputLimitPattern finds the largest allowed number of outputs in
+// validation errors such as "num_outputs must be less than or equal to 4"
+var outputLimitPattern = regexp.MustCompile(`num_outputs\D*?(?:less than or equal to|<=|at most|maximum(?: of| is)?|max(?: of| is)?)\D*?(\d+)`)
+
+// OutputLimit returns the most outputs the backend allows per request when
+// err is the backend rejecting the number of outputs
+func OutputLimit(err error) (int, bool) {
+	var statusErr *APIStatusError
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+	if statusErr.Code != http.StatusBadRequest && statusErr.Code != http.StatusUnprocessableEntity {
+		return 0, false
+	}
+	m := outputLimitPattern.FindStringSubmatch(strings.ToLower(statusErr.Body))
+	if m == nil {
+		return 0, false
+	}
+	limit, err := strconv.Atoi(m[1])
+	if err != nil || limit < 1 {
+		return 0, false
+	}
+	return limit, true
+}
+
+// isSafetyMessage reports whether an error message describes a content
+// filter rejection
+func isSafetyMessage(message string) bool {
+	lower := strings.ToLower(message)
+	for _, keyword := range safetyKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// loginMarkers identify login forms in HTML pages
+var loginMarkers = []string{`type="password"`, "type=password", "log in", "login", "sign in", "signin"}
+
+// htmlResponseError returns an error when a successful response is an HTML
+// page instead of the JSON or image expected, which the decoders would only
+// report as unreadable. Login pages, or any page reached by following a
+// redirect, mean the request was not authenticated. Without a body only the
+// Content-Type header is checked. It returns nil for other responses.
+func htmlResponseError(resp *http.Response, body []byte, expected string) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && (body == nil || !strings.HasPrefix(http.DetectContentType(body), "text/html")) {
+		return nil
+	}
+
+	// The request carries the response that redirected to it, if any
+	redirected := resp.Request != nil && resp.Request.Response != nil
+	lower := strings.ToLower(string(body))
+	login := redirected
+	for _, marker := range loginMarkers {
+		login = login || strings.Contains(lower, marker)
+	}
+	if !login {
+		return fmt.Errorf("%w: got an HTML page instead of %s", ErrDecode, expected)
+	}
+
+	page := "a login page"
+	if resp.Request != nil {
+		// Leave out the query, which may hold a token
+		u := *resp.Request.URL
+		u.RawQuery, u.Fragment = "", ""
+		page = fmt.Sprintf("a login page at %s", u.String())
+	}
+	return fmt.Errorf("%w: the backend answered with %s, check the Authorization header in FLUX_API_HEADERS", ErrAuthRequired, page)
+}
+
+// errorMessage extracts a readable message from an error response body,
+// which may be JSON with an "error" or "detail" field or plain text
+func errorMessage(body []byte) string {
+	var obj struct {
+		Error  string `json:"error"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &obj); err == nil {
+		if obj.Error != "" {
+			return obj.Error
+		}
+		if obj.Detail != "" {
+			return obj.Detail
+		}
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > 200 {
+		message = message[:200] + "..."
+	}
+	return message
+}
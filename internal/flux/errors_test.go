@@ -0,0 +1,38 @@
+package flux
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsFormatRejection(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{"field in message", http.StatusBadRequest, `{"detail": "input.output_format: output_format must be one of: png, jpg"}`, true},
+		{"FastAPI validation location", http.StatusUnprocessableEntity, `{"detail": [{"loc": ["body", "output_format"], "msg": "unexpected value"}]}`, true},
+		{"plain text", http.StatusBadRequest, "Invalid OUTPUT_FORMAT", true},
+		{"format name only", http.StatusBadRequest, `{"error": "webp is not a valid aspect_ratio"}`, false},
+		{"prompt with the format name", http.StatusBadRequest, `{"error": "prompt too long: a webp sticker of a cat, ..."}`, false},
+		{"other field", http.StatusUnprocessableEntity, `{"detail": [{"loc": ["body", "num_outputs"], "msg": "must be at most 4"}]}`, false},
+		{"longer field name", http.StatusBadRequest, `{"error": "invalid preview_output_format"}`, false},
+		{"server error", http.StatusInternalServerError, `{"error": "output_format conversion failed"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := apiError(tt.status, []byte(tt.body))
+			if got := isFormatRejection(err, "webp"); got != tt.want {
+				t.Errorf("isFormatRejection(%s) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+
+	if isFormatRejection(errors.New("output_format"), "webp") {
+		t.Error("isFormatRejection matched an error that is not an APIStatusError")
+	}
+}
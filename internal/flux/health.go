@@ -0,0 +1,67 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// healthTimeout bounds a single health check
+const healthTimeout = 5 * time.Second
+
+// CheckHealth reports whether the backend is reachable. It requests the
+// configured health URL, or the API endpoint's base URL when none is set.
+// Any response below 500 counts as reachable, since a base URL may well
+// answer 404 or 405 while the generation endpoint works.
+func (c *Client) CheckHealth(ctx context.Context) error {
+	if c.apiURL == "" {
+		return errors.New("API URL not configured")
+	}
+
+	if isMockURL(c.apiURL) {
+		return nil
+	}
+
+	healthURL, err := c.healthURL()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range c.config.GetAPIHeaders() {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("backend unreachable: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("backend unhealthy: status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// healthURL returns the URL used for health checks
+func (c *Client) healthURL() (string, error) {
+	if u := c.config.GetHealthURL(); u != "" {
+		return u, nil
+	}
+
+	u, err := url.Parse(c.apiURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q", c.apiURL)
+	}
+	return u.Scheme + "://" + u.Host + "/", nil
+}
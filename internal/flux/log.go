@@ -0,0 +1,59 @@
+package flux
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// sensitiveHeaderParts are substrings marking a header or field name as secret
+var sensitiveHeaderParts = []string{"auth", "token", "key", "secret", "password", "cookie", "signature"}
+
+// logRequest prints the request line and headers with secrets redacted,
+// for FLUX_DEBUG. It writes to stderr so CLI output on stdout stays clean.
+func logRequest(req *http.Request) {
+	fmt.Fprintf(os.Stderr, "Flux request: %s %s\n", req.Method, req.URL)
+	for _, line := range redactedHeaders(req.Header) {
+		fmt.Fprintf(os.Stderr, "- %s\n", line)
+	}
+}
+
+// redactedHeaders returns the headers as "Name: value" lines sorted by
+// name, with secrets redacted
+func redactedHeaders(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range header[name] {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, redactHeaderValue(name, value)))
+		}
+	}
+	return lines
+}
+
+// redactHeaderValue hides the value of headers that look sensitive
+func redactHeaderValue(name, value string) string {
+	if isSensitiveName(name) {
+		return "[redacted]"
+	}
+	return value
+}
+
+// isSensitiveName reports whether a header or field name looks like it
+// holds a secret
+func isSensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
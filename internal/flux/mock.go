@@ -0,0 +1,142 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mockScheme selects the built-in mock backend when used as the API URL,
+// e.g. FLUX_API_URL=mock://
+const mockScheme = "mock://"
+
+// Mock backend behaviour
+const (
+	mockMinDelay    = 500 * time.Millisecond
+	mockMaxDelay    = 1500 * time.Millisecond
+	mockFailureRate = 0.1 // Fraction of generations that fail
+	mockFlagRate    = 0.1 // Fraction of images flagged as NSFW
+	mockImageSize   = 512 // Length of the longer image side in pixels
+	mockSteps       = 10  // Progress steps reported while waiting
+)
+
+// isMockURL reports whether u is served by the mock backend
+func isMockURL(u string) bool {
+	return strings.HasPrefix(u, mockScheme)
+}
+
+// generateMock simulates a generation without touching the network.
+// It waits a short random time, occasionally fails, and returns one
+// mock:// URL per requested output, now and then flagged as NSFW.
+func (c *Client) generateMock(ctx context.Context, opts GenerateOptions) (*GenerateResult, error) {
+	// Progress is reported in steps, like a backend counting them
+	delay := mockMinDelay + time.Duration(rand.Int64N(int64(mockMaxDelay-mockMinDelay)))
+	for step := 1; step <= mockSteps; step++ {
+		select {
+		case <-time.After(delay / mockSteps):
+			reportProgress(ctx, float64(step)/mockSteps)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrNetwork, ctx.Err())
+		}
+	}
+
+	// Fail with either a server error or a safety rejection
+	if rand.Float64() < mockFailureRate {
+		if rand.IntN(2) == 0 {
+			return nil, apiError(400, []byte(`{"detail": "NSFW content detected (simulated mock failure)"}`))
+		}
+		return nil, apiError(500, []byte("simulated mock failure"))
+	}
+
+	numOutputs := opts.NumOutputs
+	if numOutputs < 1 {
+		numOutputs = 1
+	}
+
+	urls := make([]string, numOutputs)
+	flagged := make([]bool, numOutputs)
+	for i := range urls {
+		flagged[i] = rand.Float64() < mockFlagRate
+		query := url.Values{}
+		query.Set("seed", strconv.Itoa(*opts.Seed))
+		query.Set("index", strconv.Itoa(i))
+		query.Set("aspect", opts.AspectRatio)
+		urls[i] = mockScheme + "image?" + query.Encode()
+	}
+
+	result := &GenerateResult{URLs: urls, Seed: *opts.Seed}
+	if slices.Contains(flagged, true) {
+		result.Flagged = flagged
+	}
+	return result, nil
+}
+
+// renderMockImage draws the placeholder PNG described by a mock:// URL
+func renderMockImage(mockURL string) ([]byte, error) {
+	u, err := url.Parse(mockURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock URL: %w", err)
+	}
+	query := u.Query()
+
+	seed, _ := strconv.Atoi(query.Get("seed"))
+	index, _ := strconv.Atoi(query.Get("index"))
+	width, height := mockImageDimensions(query.Get("aspect"))
+
+	// Each seed and index gets its own pair of gradient colors
+	rng := rand.New(rand.NewPCG(uint64(seed), uint64(index)))
+	from := color.RGBA{uint8(rng.IntN(256)), uint8(rng.IntN(256)), uint8(rng.IntN(256)), 255}
+	to := color.RGBA{uint8(rng.IntN(256)), uint8(rng.IntN(256)), uint8(rng.IntN(256)), 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := float64(x+y) / float64(width+height-2)
+			img.SetRGBA(x, y, color.RGBA{
+				R: lerp(from.R, to.R, t),
+				G: lerp(from.G, to.G, t),
+				B: lerp(from.B, to.B, t),
+				A: 255,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode mock image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mockImageDimensions returns the image size for an aspect ratio such as "16:9",
+// falling back to a square for anything it cannot parse
+func mockImageDimensions(aspect string) (int, int) {
+	w, h, ok := strings.Cut(aspect, ":")
+	if !ok {
+		return mockImageSize, mockImageSize
+	}
+	rw, errW := strconv.Atoi(w)
+	rh, errH := strconv.Atoi(h)
+	if errW != nil || errH != nil || rw <= 0 || rh <= 0 {
+		return mockImageSize, mockImageSize
+	}
+
+	if rw >= rh {
+		return mockImageSize, max(1, mockImageSize*rh/rw)
+	}
+	return max(1, mockImageSize*rw/rh), mockImageSize
+}
+
+// lerp interpolates between two color channel values
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
@@ -0,0 +1,262 @@
+package flux
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Output is a parsed generation response
+type Output struct {
+	URLs  []string
+	Seed  *int  // Seed reported by the backend, if any
+	Seeds []int // Seed of each image, when the backend reports one per image
+
+	// Whether each image was flagged as NSFW, nil when none was
+	Flagged []bool
+
+	// Prediction still running on an asynchronous backend, to be polled
+	// for the URLs
+	prediction *prediction
+}
+
+// OutputParser turns a generation response body into image URLs. Each
+// implementation handles one response format.
+type OutputParser interface {
+	// Detect reports whether body looks like the parser's format
+	Detect(body []byte) bool
+	// Parse extracts the output from body
+	Parse(body []byte) (*Output, error)
+}
+
+// ResponseFormatAuto detects the format of each response
+const ResponseFormatAuto = "auto"
+
+// parsersMu guards parsers and detectOrder, as formats may be registered
+// while generations parse responses
+var parsersMu sync.RWMutex
+
+// parsers holds the response formats by name, in addition to "auto"
+var parsers = map[string]OutputParser{
+	"array":     arrayParser{},
+	"object":    objectParser{},
+	"replicate": predictionParser{},
+	"base64":    base64Parser{},
+}
+
+// detectOrder is the order in which auto-detection tries the formats, most
+// specific first. Registered formats are tried before the built-in ones.
+var detectOrder = []string{"replicate", "base64", "object", "array"}
+
+// RegisterParser adds a response format that can be selected by name and
+// is tried first when detecting the format
+func RegisterParser(name string, parser OutputParser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	if _, exists := parsers[name]; !exists {
+		detectOrder = append([]string{name}, detectOrder...)
+	}
+	parsers[name] = parser
+}
+
+// ParserFor returns the parser for a response format name. An empty name
+// selects auto-detection.
+func ParserFor(name string) (OutputParser, error) {
+	if name == "" || name == ResponseFormatAuto {
+		return autoParser{}, nil
+	}
+
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	if parser, ok := parsers[name]; ok {
+		return parser, nil
+	}
+
+	names := make([]string, 0, len(parsers))
+	for n := range parsers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown response format %q (use %s or %s)", name, ResponseFormatAuto, strings.Join(names, ", "))
+}
+
+// detectionParsers returns the parsers in detection order
+func detectionParsers() []OutputParser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	ordered := make([]OutputParser, len(detectOrder))
+	for i, name := range detectOrder {
+		ordered[i] = parsers[name]
+	}
+	return ordered
+}
+
+// autoParser picks the parser by looking at each response
+type autoParser struct{}
+
+func (autoParser) Detect(body []byte) bool {
+	return true
+}
+
+func (autoParser) Parse(body []byte) (*Output, error) {
+	for _, parser := range detectionParsers() {
+		if parser.Detect(body) {
+			return parser.Parse(body)
+		}
+	}
+	return nil, errors.New("unrecognized response format")
+}
+
+// arrayParser handles a plain array of image URLs, or of objects with the
+// "url", "seed" and "nsfw" flag of each image
+type arrayParser struct{}
+
+func (arrayParser) Detect(body []byte) bool {
+	return firstByte(body) == '['
+}
+
+func (arrayParser) Parse(body []byte) (*Output, error) {
+	var outputs imageOutputs
+	if err := json.Unmarshal(body, &outputs); err != nil {
+		return nil, err
+	}
+	return &Output{URLs: outputs.urls(), Seeds: outputs.seeds(), Flagged: outputs.flags()}, nil
+}
+
+// objectParser handles an object with an "output" array of image URLs, or
+// of objects with the "url", "seed" and "nsfw" flag of each image, and an
+// optional "seed" and "has_nsfw_concepts"
+type objectParser struct{}
+
+func (objectParser) Detect(body []byte) bool {
+	return firstByte(body) == '{'
+}
+
+func (objectParser) Parse(body []byte) (*Output, error) {
+	var obj objectResponse
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+	return &Output{URLs: obj.Output.urls(), Seed: obj.Seed, Seeds: obj.Output.seeds(), Flagged: obj.flags()}, nil
+}
+
+// predictionParser handles Replicate-style predictions, which are polled
+// until they finish unless the response already has the result
+type predictionParser struct{}
+
+func (predictionParser) Detect(body []byte) bool {
+	_, ok := decodePrediction(body)
+	return ok
+}
+
+func (predictionParser) Parse(body []byte) (*Output, error) {
+	pred, ok := decodePrediction(body)
+	if !ok {
+		return nil, errors.New("response is not a prediction")
+	}
+	if !pred.done() {
+		return &Output{prediction: pred}, nil
+	}
+
+	urls, err := pred.result()
+	if err != nil {
+		return nil, err
+	}
+	return &Output{URLs: urls, Seed: pred.Seed}, nil
+}
+
+// base64Parser handles images sent inline as base64, either in an "images"
+// or "output" field of an object or as a plain array. The images become
+// data: URLs.
+type base64Parser struct{}
+
+// base64Response is an object carrying base64 encoded images
+type base64Response struct {
+	Images []string `json:"images"`
+	Output []string `json:"output"`
+	Seed   *int     `json:"seed,omitempty"`
+}
+
+func (base64Parser) Detect(body []byte) bool {
+	images, _, err := decodeBase64Response(body)
+	if err != nil || len(images) == 0 {
+		return false
+	}
+	for _, image := range images {
+		if !isBase64Image(image) {
+			return false
+		}
+	}
+	return true
+}
+
+func (base64Parser) Parse(body []byte) (*Output, error) {
+	images, seed, err := decodeBase64Response(body)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(images))
+	for i, image := range images {
+		if isDataURL(image) {
+			urls = append(urls, image)
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(image)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i+1, err)
+		}
+		urls = append(urls, "data:"+http.DetectContentType(data)+";base64,"+image)
+	}
+	return &Output{URLs: urls, Seed: seed}, nil
+}
+
+// decodeBase64Response returns the encoded images of a base64 response
+func decodeBase64Response(body []byte) ([]string, *int, error) {
+	if firstByte(body) == '[' {
+		var images []string
+		err := json.Unmarshal(body, &images)
+		return images, nil, err
+	}
+
+	var obj base64Response
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, nil, err
+	}
+	if len(obj.Images) > 0 {
+		return obj.Images, obj.Seed, nil
+	}
+	return obj.Output, obj.Seed, nil
+}
+
+// isBase64Image reports whether s is an image inline in the response rather
+// than a link to one. Relative links can be valid base64 too, so s must
+// decode to data starting like an image.
+func isBase64Image(s string) bool {
+	if isDataURL(s) {
+		return true
+	}
+	if strings.Contains(s, "://") {
+		return false
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(http.DetectContentType(data), "image/")
+}
+
+// firstByte returns the first non-space byte of body, or 0 if it is empty
+func firstByte(body []byte) byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return 0
+	}
+	return trimmed[0]
+}
@@ -0,0 +1,203 @@
+package flux
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pngBase64 is the base64 encoding of data starting like a PNG image
+var pngBase64 = base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+
+func TestParserDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser OutputParser
+		body   string
+		want   bool
+	}{
+		{"array of urls", arrayParser{}, `["https://cdn.example.com/a.png"]`, true},
+		{"array rejects object", arrayParser{}, `{"output": []}`, false},
+		{"object", objectParser{}, ` {"output": ["https://cdn.example.com/a.png"]}`, true},
+		{"object rejects array", objectParser{}, `["https://cdn.example.com/a.png"]`, false},
+		{"prediction", predictionParser{}, `{"id": "p1", "status": "starting", "urls": {"get": "https://api.example.com/p1"}}`, true},
+		{"prediction without poll url", predictionParser{}, `{"id": "p1", "status": "starting"}`, false},
+		{"prediction rejects object", predictionParser{}, `{"output": ["https://cdn.example.com/a.png"]}`, false},
+		{"base64 images", base64Parser{}, `{"images": ["` + pngBase64 + `"]}`, true},
+		{"base64 array", base64Parser{}, `["` + pngBase64 + `"]`, true},
+		{"base64 data url", base64Parser{}, `{"output": ["data:image/png;base64,` + pngBase64 + `"]}`, true},
+		{"base64 rejects urls", base64Parser{}, `{"output": ["https://cdn.example.com/a.png"]}`, false},
+		{"base64 rejects relative url", base64Parser{}, `{"output": ["outputs/abcdEFGH12345678"]}`, false},
+		{"base64 rejects non-image data", base64Parser{}, `{"output": ["` + base64.StdEncoding.EncodeToString([]byte("plain text, not an image")) + `"]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parser.Detect([]byte(tt.body)); got != tt.want {
+				t.Errorf("Detect(%s) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParserParse(t *testing.T) {
+	seed := 7
+
+	tests := []struct {
+		name       string
+		parser     OutputParser
+		body       string
+		wantURLs   []string
+		wantSeed   *int
+		wantSeeds  []int
+		wantFlags  []bool // Expected NSFW flags, nil when none is flagged
+		wantErr    error  // Expected error, matched with errors.Is
+		wantPolled bool   // The output is a prediction still to be polled
+	}{
+		{
+			name:     "array of urls",
+			parser:   arrayParser{},
+			body:     `["https://cdn.example.com/a.png", "https://cdn.example.com/b.png"]`,
+			wantURLs: []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+		},
+		{
+			name:      "array of objects",
+			parser:    arrayParser{},
+			body:      `[{"url": "https://cdn.example.com/a.png", "seed": 1}, {"url": "https://cdn.example.com/b.png", "seed": 2}]`,
+			wantURLs:  []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+			wantSeeds: []int{1, 2},
+		},
+		{
+			name:     "object with seed",
+			parser:   objectParser{},
+			body:     `{"output": ["https://cdn.example.com/a.png"], "seed": 7}`,
+			wantURLs: []string{"https://cdn.example.com/a.png"},
+			wantSeed: &seed,
+		},
+		{
+			name:      "array with flagged image",
+			parser:    arrayParser{},
+			body:      `[{"url": "https://cdn.example.com/a.png", "nsfw": true}, {"url": "https://cdn.example.com/b.png", "nsfw": false}]`,
+			wantURLs:  []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+			wantFlags: []bool{true, false},
+		},
+		{
+			name:     "array with nothing flagged",
+			parser:   arrayParser{},
+			body:     `[{"url": "https://cdn.example.com/a.png", "nsfw": false}, "https://cdn.example.com/b.png"]`,
+			wantURLs: []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+		},
+		{
+			name:      "object with nsfw concepts",
+			parser:    objectParser{},
+			body:      `{"output": ["https://cdn.example.com/a.png", "https://cdn.example.com/b.png"], "has_nsfw_concepts": [false, true]}`,
+			wantURLs:  []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+			wantFlags: []bool{false, true},
+		},
+		{
+			name:     "object with no nsfw concepts",
+			parser:   objectParser{},
+			body:     `{"output": ["https://cdn.example.com/a.png"], "has_nsfw_concepts": [false]}`,
+			wantURLs: []string{"https://cdn.example.com/a.png"},
+		},
+		{
+			name:     "object with nsfw concepts not matching the images",
+			parser:   objectParser{},
+			body:     `{"output": ["https://cdn.example.com/a.png", "https://cdn.example.com/b.png"], "has_nsfw_concepts": [true]}`,
+			wantURLs: []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+		},
+		{
+			name:      "object prefers the flags of the images",
+			parser:    objectParser{},
+			body:      `{"output": [{"url": "https://cdn.example.com/a.png", "nsfw": true}, {"url": "https://cdn.example.com/b.png"}], "has_nsfw_concepts": [false, true]}`,
+			wantURLs:  []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"},
+			wantFlags: []bool{true, false},
+		},
+		{
+			name:     "finished prediction",
+			parser:   predictionParser{},
+			body:     `{"id": "p1", "status": "succeeded", "output": "https://cdn.example.com/a.png", "urls": {"get": "https://api.example.com/p1"}}`,
+			wantURLs: []string{"https://cdn.example.com/a.png"},
+		},
+		{
+			name:       "running prediction",
+			parser:     predictionParser{},
+			body:       `{"id": "p1", "status": "processing", "urls": {"get": "https://api.example.com/p1"}}`,
+			wantPolled: true,
+		},
+		{
+			name:    "failed prediction",
+			parser:  predictionParser{},
+			body:    `{"id": "p1", "status": "failed", "error": "out of memory", "urls": {"get": "https://api.example.com/p1"}}`,
+			wantErr: ErrPredictionFailed,
+		},
+		{
+			name:     "base64 images",
+			parser:   base64Parser{},
+			body:     `{"images": ["` + pngBase64 + `"], "seed": 7}`,
+			wantURLs: []string{"data:image/png;base64," + pngBase64},
+			wantSeed: &seed,
+		},
+		{
+			name:     "auto keeps relative urls",
+			parser:   autoParser{},
+			body:     `{"output": ["outputs/abcdEFGH12345678"]}`,
+			wantURLs: []string{"outputs/abcdEFGH12345678"},
+		},
+		{
+			name:     "auto finds base64",
+			parser:   autoParser{},
+			body:     `["` + pngBase64 + `"]`,
+			wantURLs: []string{"data:image/png;base64," + pngBase64},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.parser.Parse([]byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := output.prediction != nil; got != tt.wantPolled {
+				t.Fatalf("got prediction to poll %v, want %v", got, tt.wantPolled)
+			}
+			if !slices.Equal(output.URLs, tt.wantURLs) {
+				t.Errorf("got URLs %v, want %v", output.URLs, tt.wantURLs)
+			}
+			if !slices.Equal(output.Seeds, tt.wantSeeds) {
+				t.Errorf("got seeds %v, want %v", output.Seeds, tt.wantSeeds)
+			}
+			if !slices.Equal(output.Flagged, tt.wantFlags) {
+				t.Errorf("got flags %v, want %v", output.Flagged, tt.wantFlags)
+			}
+			switch {
+			case tt.wantSeed == nil && output.Seed != nil:
+				t.Errorf("got seed %d, want none", *output.Seed)
+			case tt.wantSeed != nil && (output.Seed == nil || *output.Seed != *tt.wantSeed):
+				t.Errorf("got seed %v, want %d", output.Seed, *tt.wantSeed)
+			}
+		})
+	}
+}
+
+func TestParserFor(t *testing.T) {
+	for _, name := range []string{"", ResponseFormatAuto, "array", "object", "replicate", "base64"} {
+		if _, err := ParserFor(name); err != nil {
+			t.Errorf("ParserFor(%q): %v", name, err)
+		}
+	}
+
+	_, err := ParserFor("xml")
+	if err == nil || !strings.Contains(err.Error(), "xml") {
+		t.Errorf("got error %v for an unknown format", err)
+	}
+}
@@ -0,0 +1,105 @@
+package flux
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fluxxxer/internal/config"
+)
+
+// pendingFile is the name of the pending predictions file
+const pendingFile = "pending.json"
+
+// Pending is a prediction started on an asynchronous backend that has not
+// finished yet, with the settings needed to show its results
+type Pending struct {
+	ID          string    `json:"id"`
+	PollURL     string    `json:"poll_url"`
+	Prompt      string    `json:"prompt"`
+	Seed        int       `json:"seed"`
+	NumOutputs  int       `json:"num_outputs"`
+	AspectRatio string    `json:"aspect_ratio"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PendingStore keeps the pending predictions in a file so they survive
+// restarts and can be resumed without starting the generation again
+type PendingStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewPendingStore creates a store keeping its file in dir
+func NewPendingStore(dir string) *PendingStore {
+	return &PendingStore{dir: dir}
+}
+
+// Load returns the recorded pending predictions.
+// A missing file is not an error.
+func (s *PendingStore) Load() ([]Pending, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// add records p, replacing an earlier record of the same prediction
+func (s *PendingStore) add(p Pending) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, err := s.read()
+	if err != nil {
+		return err
+	}
+	pending = withoutPending(pending, p.ID)
+	return s.write(append(pending, p))
+}
+
+// remove forgets the prediction with the given ID
+func (s *PendingStore) remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(withoutPending(pending, id))
+}
+
+// withoutPending returns pending without the prediction with the given ID
+func withoutPending(pending []Pending, id string) []Pending {
+	kept := pending[:0]
+	for _, p := range pending {
+		if p.ID != id {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func (s *PendingStore) read() ([]Pending, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, pendingFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending predictions: %w", err)
+	}
+
+	var pending []Pending
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse pending predictions: %w", err)
+	}
+	return pending, nil
+}
+
+// write saves pending, keeping the previous records if it fails
+func (s *PendingStore) write(pending []Pending) error {
+	return config.WriteJSONFile(s.dir, pendingFile, pending)
+}
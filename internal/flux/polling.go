@@ -0,0 +1,265 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Polling timing for asynchronous backends. Failed polls back off up to
+// pollMaxInterval so a dropped connection is retried rather than lost.
+const (
+	pollInterval       = 2 * time.Second
+	pollMaxInterval    = 30 * time.Second
+	pollTimeout        = 10 * time.Minute
+	pollRequestTimeout = 30 * time.Second // Bounds a single status request
+)
+
+// prediction is the response of an asynchronous backend, which starts the
+// generation and returns a URL to poll for its status
+type prediction struct {
+	ID       string          `json:"id"`
+	Status   string          `json:"status"`
+	Output   json.RawMessage `json:"output"`
+	Error    any             `json:"error"`
+	Seed     *int            `json:"seed,omitempty"`
+	PollURL  string          `json:"poll_url"`
+	Logs     any             `json:"logs"`     // Text holding step counts on Replicate-style backends
+	Progress any             `json:"progress"` // Fraction or percentage done; its type varies by backend
+	URLs     struct {
+		Get string `json:"get"`
+	} `json:"urls"`
+}
+
+// decodePrediction parses body as a prediction. It reports false when the
+// body is not a prediction, i.e. has no status or nothing to poll.
+func decodePrediction(body []byte) (*prediction, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+
+	var pred prediction
+	if err := json.Unmarshal(trimmed, &pred); err != nil {
+		return nil, false
+	}
+	if pred.Status == "" || pred.pollURL() == "" {
+		return nil, false
+	}
+	return &pred, true
+}
+
+// pollURL returns the URL reporting the prediction's status
+func (p *prediction) pollURL() string {
+	if p.URLs.Get != "" {
+		return p.URLs.Get
+	}
+	return p.PollURL
+}
+
+// done reports whether the prediction has finished, successfully or not
+func (p *prediction) done() bool {
+	switch p.Status {
+	case "succeeded", "failed", "canceled":
+		return true
+	}
+	return false
+}
+
+// result returns the image URLs of a finished prediction. The output may be
+// a list of URLs or a single URL.
+func (p *prediction) result() ([]string, error) {
+	if p.Status != "succeeded" {
+		message := fmt.Sprint(p.Error)
+		if p.Error == nil {
+			message = p.Status
+		}
+		if isSafetyMessage(message) {
+			return nil, fmt.Errorf("%w: %s", ErrSafetyRejected, message)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPredictionFailed, message)
+	}
+
+	var urls []string
+	if err := json.Unmarshal(p.Output, &urls); err == nil {
+		return urls, nil
+	}
+	var url string
+	if err := json.Unmarshal(p.Output, &url); err == nil && url != "" {
+		return []string{url}, nil
+	}
+	return nil, ErrEmptyResult
+}
+
+// Resume polls a started prediction until it finishes. The prediction is
+// recorded in the pending store while it runs, so a generation interrupted
+// by a restart or lost connection can be resumed later.
+func (c *Client) Resume(ctx context.Context, pending Pending) (*GenerateResult, error) {
+	if c.pending != nil {
+		if err := c.pending.add(pending); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record pending prediction %s: %v\n", pending.ID, err)
+		}
+	}
+
+	urls, seed, err := c.pollPrediction(ctx, pending.PollURL)
+
+	// Keep the prediction for later unless it finished one way or another
+	// or the backend no longer has it. A canceled prediction was stopped on
+	// purpose, so it is not kept.
+	if c.pending != nil && !resumable(err) {
+		if err := c.pending.remove(pending.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove pending prediction %s: %v\n", pending.ID, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, ErrEmptyResult
+	}
+
+	result := &GenerateResult{URLs: urls, Seed: pending.Seed}
+	if seed != nil {
+		result.Seed = *seed
+	}
+	return result, nil
+}
+
+// resumable reports whether a prediction whose polling ended with err may
+// still finish, so it is worth polling again later
+func resumable(err error) bool {
+	var statusErr *APIStatusError
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrAuthRequired) || errors.As(err, &statusErr)
+}
+
+// pollPrediction fetches the prediction at pollURL until it finishes.
+// Network errors, server errors, timeouts and rate limits are retried with
+// backoff.
+func (c *Client) pollPrediction(ctx context.Context, pollURL string) ([]string, *int, error) {
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	delay := pollInterval
+	for {
+		pred, err := c.fetchPrediction(ctx, pollURL)
+		var gone *predictionGoneError
+		var statusErr *APIStatusError
+		var retry *pollRetryError
+		switch {
+		case errors.As(err, &gone), errors.As(err, &statusErr), errors.Is(err, ErrAuthRequired), errors.Is(err, errInvalidPollURL):
+			// None goes away by polling again
+			return nil, nil, err
+		case errors.As(err, &retry):
+			delay = min(max(delay*2, retry.after), pollMaxInterval)
+			fmt.Fprintf(os.Stderr, "Polling %s failed, retrying in %s: %v\n", pollURL, delay, err)
+		case err != nil:
+			delay = min(delay*2, pollMaxInterval)
+			fmt.Fprintf(os.Stderr, "Polling %s failed, retrying in %s: %v\n", pollURL, delay, err)
+		case pred.done():
+			urls, err := pred.result()
+			return urls, pred.Seed, err
+		default:
+			delay = pollInterval
+			if fraction, ok := pred.progress(); ok {
+				reportProgress(ctx, fraction)
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("polling stopped: %w", ctx.Err())
+		}
+	}
+}
+
+// predictionGoneError reports that the backend no longer knows a prediction
+type predictionGoneError struct {
+	statusCode int
+}
+
+func (e *predictionGoneError) Error() string {
+	return fmt.Sprintf("prediction not found: status code %d", e.statusCode)
+}
+
+// pollRetryError reports a poll answered with a status worth retrying, such
+// as a rate limit or a server error. after is how long the backend asked to
+// wait, 0 if it did not say.
+type pollRetryError struct {
+	statusCode int
+	after      time.Duration
+}
+
+func (e *pollRetryError) Error() string {
+	return fmt.Sprintf("status code %d", e.statusCode)
+}
+
+// errInvalidPollURL is returned when a prediction's poll URL cannot be
+// requested at all
+var errInvalidPollURL = errors.New("invalid poll URL")
+
+// retryAfter returns the wait asked for by the Retry-After header of resp
+// in seconds, or 0 when there is none
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// fetchPrediction requests the current state of a prediction
+func (c *Client) fetchPrediction(ctx context.Context, pollURL string) (*prediction, error) {
+	ctx, cancel := context.WithTimeout(ctx, pollRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pollURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", errInvalidPollURL, pollURL, err)
+	}
+	for name, value := range c.config.GetAPIHeaders() {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Only a missing prediction is gone for good. Timeouts, rate limits and
+	// server errors pass, while other client errors, such as rejected
+	// credentials, need the user to act before the prediction is polled again.
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, &predictionGoneError{statusCode: resp.StatusCode}
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &pollRetryError{statusCode: resp.StatusCode, after: retryAfter(resp)}
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		c.recordResponse(resp, body)
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.recordResponse(resp, body)
+	if err := htmlResponseError(resp, body, "JSON"); err != nil {
+		return nil, err
+	}
+
+	var pred prediction
+	if err := json.Unmarshal(body, &pred); err != nil {
+		return nil, fmt.Errorf("%w: prediction: %w", ErrDecode, err)
+	}
+	return &pred, nil
+}
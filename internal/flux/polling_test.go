@@ -0,0 +1,136 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchPredictionStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantGone  bool
+		wantRetry bool
+		wantCode  int // Status code of an expected APIStatusError
+	}{
+		{status: http.StatusNotFound, wantGone: true},
+		{status: http.StatusGone, wantGone: true},
+		{status: http.StatusRequestTimeout, wantRetry: true},
+		{status: http.StatusTooManyRequests, wantRetry: true},
+		{status: http.StatusBadGateway, wantRetry: true},
+		{status: http.StatusForbidden, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(tt.status)
+			})
+
+			_, err := client.fetchPrediction(context.Background(), server.URL)
+
+			var gone *predictionGoneError
+			var retry *pollRetryError
+			var statusErr *APIStatusError
+			if got := errors.As(err, &gone); got != tt.wantGone {
+				t.Errorf("got error %v, gone = %v, want %v", err, got, tt.wantGone)
+			}
+			if got := errors.As(err, &retry); got != tt.wantRetry {
+				t.Errorf("got error %v, retry = %v, want %v", err, got, tt.wantRetry)
+			}
+			if tt.wantRetry && retry.after != 3*time.Second {
+				t.Errorf("got retry after %s, want 3s", retry.after)
+			}
+			if tt.wantCode != 0 && (!errors.As(err, &statusErr) || statusErr.Code != tt.wantCode) {
+				t.Errorf("got error %v, want APIStatusError %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFetchPredictionInvalidURL(t *testing.T) {
+	client := NewClient(testConfig{})
+
+	_, err := client.fetchPrediction(context.Background(), "http://[::1")
+
+	if !errors.Is(err, errInvalidPollURL) {
+		t.Fatalf("got error %v, want %v", err, errInvalidPollURL)
+	}
+	var gone *predictionGoneError
+	if errors.As(err, &gone) {
+		t.Errorf("got %v, an invalid URL is not a missing prediction", err)
+	}
+}
+
+// resumeTest resumes a prediction polled at a server answering with
+// handler and returns the result and the predictions still pending
+func resumeTest(t *testing.T, handler http.HandlerFunc) (*GenerateResult, []Pending, error) {
+	t.Helper()
+	client, server := newTestClient(t, handler)
+	store := NewPendingStore(t.TempDir())
+	client.SetPendingStore(store)
+
+	result, err := client.Resume(context.Background(), Pending{ID: "abc", PollURL: server.URL, Seed: 7})
+
+	pending, loadErr := store.Load()
+	if loadErr != nil {
+		t.Fatalf("failed to load pending predictions: %v", loadErr)
+	}
+	return result, pending, err
+}
+
+func TestResumeRetriesRateLimit(t *testing.T) {
+	var polls atomic.Int32
+	result, pending, err := resumeTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"status": "succeeded", "output": ["https://cdn.example.com/a.png"]}`))
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.URLs) != 1 || result.Seed != 7 {
+		t.Errorf("got result %+v, want one URL and seed 7", result)
+	}
+	if polls.Load() != 2 {
+		t.Errorf("got %d polls, want 2", polls.Load())
+	}
+	if len(pending) != 0 {
+		t.Errorf("got pending %v, want none after success", pending)
+	}
+}
+
+func TestResumeForgetsMissingPrediction(t *testing.T) {
+	_, pending, err := resumeTest(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	var gone *predictionGoneError
+	if !errors.As(err, &gone) {
+		t.Fatalf("got error %v, want predictionGoneError", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("got pending %v, want the missing prediction removed", pending)
+	}
+}
+
+func TestResumeKeepsRejectedPrediction(t *testing.T) {
+	_, pending, err := resumeTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var statusErr *APIStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("got error %v, want APIStatusError", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "abc" {
+		t.Errorf("got pending %v, want the prediction kept", pending)
+	}
+}
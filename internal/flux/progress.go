@@ -0,0 +1,54 @@
+package flux
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+// progressKey is the context key of the function receiving progress
+type progressKey struct{}
+
+// stepLog matches the step counter of progress bars in prediction logs,
+// e.g. " 54%|█████▍    | 15/28 [00:02<00:01, 6.12it/s]"
+var stepLog = regexp.MustCompile(`(\d+)/(\d+) \[`)
+
+// WithProgress returns a context whose generations call report with how
+// far along they are, as a fraction from 0 to 1. Only backends that tell
+// do so: asynchronous ones with a progress value or step counts in their
+// logs, and the mock backend.
+func WithProgress(ctx context.Context, report func(fraction float64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress passes fraction to the progress function of ctx, if any
+func reportProgress(ctx context.Context, fraction float64) {
+	if report, ok := ctx.Value(progressKey{}).(func(float64)); ok {
+		report(min(max(fraction, 0), 1))
+	}
+}
+
+// progress returns how far along a running prediction is, from its
+// progress value, taken as a percentage when above 1, or else the last step
+// count in its logs. It reports false when the backend tells neither.
+func (p *prediction) progress() (float64, bool) {
+	if value, ok := p.Progress.(float64); ok && value >= 0 {
+		if value > 1 {
+			value /= 100
+		}
+		return value, true
+	}
+
+	logs, _ := p.Logs.(string)
+	matches := stepLog.FindAllStringSubmatch(logs, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	last := matches[len(matches)-1]
+	step, _ := strconv.Atoi(last[1])
+	total, _ := strconv.Atoi(last[2])
+	if total == 0 {
+		return 0, false
+	}
+	return float64(step) / float64(total), true
+}
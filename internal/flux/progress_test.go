@@ -0,0 +1,60 @@
+package flux
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestPredictionProgress(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   float64
+		wantOK bool
+	}{
+		{"fraction", `{"progress": 0.25}`, 0.25, true},
+		{"zero", `{"progress": 0}`, 0, true},
+		{"one", `{"progress": 1}`, 1, true},
+		{"percentage", `{"progress": 40}`, 0.4, true},
+		{"percentage done", `{"progress": 100}`, 1, true},
+		{"negative progress falls back to logs", `{"progress": -1, "logs": "3/4 [00:01<00:00]"}`, 0.75, true},
+		{"progress as string falls back to logs", `{"progress": "50%", "logs": "1/4 [00:01<00:03]"}`, 0.25, true},
+		{"progress over logs", `{"progress": 0.5, "logs": "1/4 [00:01<00:03]"}`, 0.5, true},
+		{"log step", `{"logs": " 54%|█████▍    | 15/28 [00:02<00:01, 6.12it/s]"}`, 15.0 / 28, true},
+		{"last log step", `{"logs": "  0%|          | 0/28 [00:00<?, ?it/s]\n 50%|█████     | 14/28 [00:02<00:02, 6.10it/s]\n"}`, 0.5, true},
+		{"count without bar", `{"logs": "Generating 2/4 images"}`, 0, false},
+		{"zero total", `{"logs": "0/0 [00:00<?, ?it/s]"}`, 0, false},
+		{"no logs", `{"status": "processing"}`, 0, false},
+		{"logs not text", `{"logs": ["1/4 [00:01<00:03]"]}`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pred prediction
+			if err := json.Unmarshal([]byte(tt.body), &pred); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+			got, ok := pred.progress()
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("progress() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	var got []float64
+	ctx := WithProgress(context.Background(), func(fraction float64) {
+		got = append(got, fraction)
+	})
+	for _, fraction := range []float64{-0.5, 0.5, 1.5} {
+		reportProgress(ctx, fraction)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 0.5 || got[2] != 1 {
+		t.Errorf("got %v, want [0 0.5 1]", got)
+	}
+
+	// Contexts without a progress function are fine
+	reportProgress(context.Background(), 0.5)
+}
@@ -0,0 +1,95 @@
+package flux
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fluxxxer/internal/config"
+)
+
+// queueFile is the name of the file keeping the generation queue
+const queueFile = "queue.json"
+
+// QueuedGeneration is a generation waiting in the queue, kept on disk so
+// the queue survives restarts
+type QueuedGeneration struct {
+	Label string `json:"label,omitempty"`
+	Input Input  `json:"input"`
+
+	// ImageSource is the URL or file path of the base image, which is
+	// loaded again on resume instead of keeping its data in the file
+	ImageSource string `json:"image_source,omitempty"`
+
+	// Interrupted is set for a generation that was running when the queue
+	// was saved. The backend may have finished it already.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// NewInput returns the request input for a generation of prompt with opts
+func NewInput(prompt string, opts GenerateOptions) Input {
+	return Input{
+		Prompt:            prompt,
+		NumOutputs:        opts.NumOutputs,
+		AspectRatio:       opts.AspectRatio,
+		OutputFormat:      opts.OutputFormat,
+		OutputQuality:     opts.Quality,
+		Seed:              opts.Seed,
+		Image:             opts.Image,
+		Guidance:          opts.Guidance,
+		NumInferenceSteps: opts.Steps,
+		Model:             opts.Model,
+	}
+}
+
+// Options returns the generation options the input was created from
+func (in Input) Options() GenerateOptions {
+	return GenerateOptions{
+		NumOutputs:   in.NumOutputs,
+		AspectRatio:  in.AspectRatio,
+		OutputFormat: in.OutputFormat,
+		Quality:      in.OutputQuality,
+		Seed:         in.Seed,
+		Image:        in.Image,
+		Guidance:     in.Guidance,
+		Steps:        in.NumInferenceSteps,
+		Model:        in.Model,
+	}
+}
+
+// LoadQueue reads the generation queue saved in dir.
+// A missing queue file is not an error.
+func LoadQueue(dir string) ([]QueuedGeneration, error) {
+	data, err := os.ReadFile(filepath.Join(dir, queueFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	var queue []QueuedGeneration
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("failed to parse queue: %w", err)
+	}
+	return queue, nil
+}
+
+// SaveQueue writes the generation queue to dir, removing the file once the
+// queue is empty
+func SaveQueue(dir string, queue []QueuedGeneration) error {
+	path := filepath.Join(dir, queueFile)
+	if len(queue) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to clear queue: %w", err)
+		}
+		return nil
+	}
+
+	if err := config.WriteJSONFile(dir, queueFile, queue); err != nil {
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+	return nil
+}
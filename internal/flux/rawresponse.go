@@ -0,0 +1,126 @@
+package flux
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of what is kept of a response for inspection
+const (
+	maxRecordedBody   = 1 << 20 // Bytes of a body kept
+	maxRecordedString = 256     // Characters of a JSON string shown, e.g. base64 images
+)
+
+// RawResponse is the last response from the backend as received, kept to
+// see why it was read the way it was
+type RawResponse struct {
+	URL         string // Without the query, which may hold credentials
+	Status      string
+	ContentType string
+	Body        []byte // Nil when the body was streamed, as multipart bodies are
+	Truncated   bool   // Body holds only the start of a larger body
+	ReceivedAt  time.Time
+}
+
+// recordResponse keeps resp and its body as the last response
+func (c *Client) recordResponse(resp *http.Response, body []byte) {
+	raw := &RawResponse{
+		Status:      resp.Status,
+		ContentType: resp.Header.Get("Content-Type"),
+		ReceivedAt:  time.Now(),
+	}
+	if resp.Request != nil {
+		u := *resp.Request.URL
+		u.RawQuery, u.User = "", nil
+		raw.URL = u.String()
+	}
+	if body != nil {
+		if len(body) > maxRecordedBody {
+			body, raw.Truncated = body[:maxRecordedBody], true
+		}
+		raw.Body = bytes.Clone(body)
+	}
+
+	c.lastMu.Lock()
+	c.last = raw
+	c.lastMu.Unlock()
+}
+
+// LastResponse returns the last response received for a generation or a
+// prediction poll, or nil before the first
+func (c *Client) LastResponse() *RawResponse {
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	return c.last
+}
+
+// Format returns the response for reading: its status and content type,
+// then the body with JSON pretty-printed. Fields that look like secrets are
+// redacted and long strings such as embedded images shortened.
+func (r *RawResponse) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\nContent-Type: %s\nReceived: %s\n\n", r.URL, r.Status, r.ContentType, r.ReceivedAt.Format(time.RFC3339))
+
+	switch {
+	case r.Body == nil:
+		b.WriteString("[body not kept]")
+	case json.Valid(r.Body):
+		b.WriteString(redactJSON(r.Body))
+	case utf8.Valid(r.Body):
+		b.Write(r.Body)
+	default:
+		fmt.Fprintf(&b, "[%d bytes of binary data]", len(r.Body))
+	}
+	if r.Truncated {
+		fmt.Fprintf(&b, "\n[cut off after %d bytes]", maxRecordedBody)
+	}
+	return b.String()
+}
+
+// redactJSON pretty-prints a JSON document with the values of sensitive
+// fields hidden and long strings shortened
+func redactJSON(data []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return string(data)
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(redactValue(v)); err != nil {
+		return string(data)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// redactValue returns v with sensitive fields and long strings replaced
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitiveName(key) {
+				v[key] = "[redacted]"
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	case string:
+		if n := utf8.RuneCountInString(v); n > maxRecordedString {
+			return fmt.Sprintf("%s... [%d characters]", string([]rune(v)[:maxRecordedString]), n)
+		}
+	}
+	return v
+}
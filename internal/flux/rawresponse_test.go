@@ -0,0 +1,108 @@
+package flux
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsSensitiveName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Authorization", true},
+		{"authorization", true},
+		{"X-API-Key", true},
+		{"api_key", true},
+		{"apiKey", true},
+		{"access_token", true},
+		{"refreshToken", true},
+		{"client_secret", true},
+		{"PASSWORD", true},
+		{"Set-Cookie", true},
+		{"x-amz-signature", true},
+		{"prompt", false},
+		{"seed", false},
+		{"output", false},
+		{"urls", false},
+		{"Content-Type", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSensitiveName(tt.name); got != tt.want {
+				t.Errorf("isSensitiveName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactValue(t *testing.T) {
+	long := strings.Repeat("a", maxRecordedString+10)
+	shortened := strings.Repeat("a", maxRecordedString) + "... [266 characters]"
+
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{"scalar", "fox", "fox"},
+		{"long string", long, shortened},
+		{"long multibyte string", strings.Repeat("ü", maxRecordedString+1), strings.Repeat("ü", maxRecordedString) + "... [257 characters]"},
+		{
+			"sensitive field",
+			map[string]any{"token": "abc", "seed": 42.0},
+			map[string]any{"token": "[redacted]", "seed": 42.0},
+		},
+		{
+			"sensitive object replaced whole",
+			map[string]any{"auth": map[string]any{"user": "me"}},
+			map[string]any{"auth": "[redacted]"},
+		},
+		{
+			"nested objects",
+			map[string]any{"metrics": map[string]any{"predict_time": 1.5, "credentials": map[string]any{"API_KEY": "abc"}}},
+			map[string]any{"metrics": map[string]any{"predict_time": 1.5, "credentials": map[string]any{"API_KEY": "[redacted]"}}},
+		},
+		{
+			"arrays",
+			[]any{map[string]any{"url": "a.png", "signature": "xyz"}, long, []any{map[string]any{"Password": "p"}}},
+			[]any{map[string]any{"url": "a.png", "signature": "[redacted]"}, shortened, []any{map[string]any{"Password": "[redacted]"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			"pretty-printed with secrets hidden",
+			`{"output":["https://example.com/a.png?x=1&y=2"],"accessToken":"abc"}`,
+			"{\n  \"accessToken\": \"[redacted]\",\n  \"output\": [\n    \"https://example.com/a.png?x=1&y=2\"\n  ]\n}",
+		},
+		{"large numbers kept", `{"seed":12345678901234567890}`, "{\n  \"seed\": 12345678901234567890\n}"},
+		{"top-level array", `[{"key":"k"},1]`, "[\n  {\n    \"key\": \"[redacted]\"\n  },\n  1\n]"},
+		{"invalid JSON returned as is", `{"token":`, `{"token":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactJSON([]byte(tt.data)); got != tt.want {
+				t.Errorf("redactJSON(%s) =\n%s\nwant\n%s", tt.data, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package flux
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// objectResponse is a response wrapping the image URLs in an object,
+// optionally reporting the effective seed and which images are NSFW
+type objectResponse struct {
+	Output       imageOutputs `json:"output"`
+	Seed         *int         `json:"seed,omitempty"`
+	NSFWConcepts []bool       `json:"has_nsfw_concepts,omitempty"` // One per image, as fal.ai reports them
+}
+
+// flags returns whether each image was flagged as NSFW, from the images
+// themselves or from the list beside them, or nil when none is reported
+func (obj *objectResponse) flags() []bool {
+	if flags := obj.Output.flags(); flags != nil {
+		return flags
+	}
+	if len(obj.NSFWConcepts) == len(obj.Output) && slices.Contains(obj.NSFWConcepts, true) {
+		return obj.NSFWConcepts
+	}
+	return nil
+}
+
+// imageOutput is an image in a response, given either as its URL or as an
+// object with the URL, the seed and the safety flag of that image
+type imageOutput struct {
+	URL  string `json:"url"`
+	Seed *int   `json:"seed,omitempty"`
+	NSFW bool   `json:"nsfw,omitempty"`
+}
+
+func (o *imageOutput) UnmarshalJSON(data []byte) error {
+	if firstByte(data) != '{' {
+		return json.Unmarshal(data, &o.URL)
+	}
+	type object imageOutput // Without this method
+	return json.Unmarshal(data, (*object)(o))
+}
+
+// imageOutputs is the list of images in a response
+type imageOutputs []imageOutput
+
+// urls returns the image URLs in order
+func (outputs imageOutputs) urls() []string {
+	urls := make([]string, len(outputs))
+	for i, o := range outputs {
+		urls[i] = o.URL
+	}
+	return urls
+}
+
+// seeds returns the seed of each image in order, or nil unless every
+// image reports one
+func (outputs imageOutputs) seeds() []int {
+	if len(outputs) == 0 {
+		return nil
+	}
+	seeds := make([]int, len(outputs))
+	for i, o := range outputs {
+		if o.Seed == nil {
+			return nil
+		}
+		seeds[i] = *o.Seed
+	}
+	return seeds
+}
+
+// flags returns whether each image was flagged as NSFW in order, or nil
+// when none was
+func (outputs imageOutputs) flags() []bool {
+	flags := make([]bool, len(outputs))
+	for i, o := range outputs {
+		flags[i] = o.NSFW
+	}
+	if !slices.Contains(flags, true) {
+		return nil
+	}
+	return flags
+}
+
+// decodeResponse parses a generation response, which is either a plain
+// array of image URLs or an object with an "output" array and a "seed"
+func decodeResponse(body []byte) ([]string, *int, error) {
+	trimmed := bytes.TrimSpace(body)
+
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var obj objectResponse
+		if err := json.Unmarshal(trimmed, &obj); err != nil {
+			return nil, nil, err
+		}
+		return obj.Output.urls(), obj.Seed, nil
+	}
+
+	var outputs imageOutputs
+	if err := json.Unmarshal(trimmed, &outputs); err != nil {
+		return nil, nil, err
+	}
+	return outputs.urls(), nil, nil
+}
+
+// decodeMultipartResponse reads a multipart response carrying the images
+// themselves. Each image part becomes a data: URL so no further download is
+// needed; JSON parts are decoded like a regular response for extra URLs and
+// the seed.
+func decodeMultipartResponse(body io.Reader, boundary string) ([]string, *int, error) {
+	if boundary == "" {
+		return nil, nil, errors.New("multipart response without boundary")
+	}
+
+	var urls []string
+	var seed *int
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read part: %w", err)
+		}
+
+		data, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read part: %w", err)
+		}
+
+		contentType := part.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+
+		switch {
+		case mediaType == "application/json":
+			partURLs, partSeed, err := decodeResponse(data)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode JSON part: %w", err)
+			}
+			urls = append(urls, partURLs...)
+			if partSeed != nil {
+				seed = partSeed
+			}
+		case strings.HasPrefix(mediaType, "image/"):
+			urls = append(urls, imageDataURL(mediaType, data))
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, nil, errors.New("multipart response contained no images")
+	}
+	return urls, seed, nil
+}
+
+// imageDataURL returns a data: URL holding an image of mediaType
+func imageDataURL(mediaType string, data []byte) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// outputFormatTypes maps the output formats backends accept to the media
+// types of their images
+var outputFormatTypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"webp": "image/webp",
+}
+
+// acceptHeader returns the Accept header of a generation request. A named
+// response format is JSON, so only JSON is asked for; when the format is
+// detected, multipart responses and the image itself in the requested
+// output format are welcome too, with JSON preferred.
+func acceptHeader(responseFormat, outputFormat string) string {
+	if responseFormat != "" && responseFormat != ResponseFormatAuto {
+		return "application/json"
+	}
+	imageType, ok := outputFormatTypes[strings.ToLower(outputFormat)]
+	if !ok {
+		imageType = "image/*"
+	}
+	return "application/json, multipart/mixed;q=0.9, " + imageType + ";q=0.8"
+}
+
+// isDataURL reports whether u carries its content inline as a data: URL
+func isDataURL(u string) bool {
+	return strings.HasPrefix(u, "data:")
+}
+
+// decodeDataURL returns the content and media type of a base64 data: URL
+func decodeDataURL(u string) ([]byte, string, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(u, "data:"), ",")
+	if !ok {
+		return nil, "", errors.New("invalid data URL")
+	}
+
+	mediaType, isBase64 := strings.CutSuffix(header, ";base64")
+	if !isBase64 {
+		return nil, "", errors.New("unsupported data URL encoding")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid data URL: %w", err)
+	}
+	return data, mediaType, nil
+}
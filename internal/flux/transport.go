@@ -0,0 +1,32 @@
+package flux
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// dialTimeout bounds establishing a connection, as in http.DefaultTransport
+const dialTimeout = 30 * time.Second
+
+// newTransport returns the transport shared by the client's requests, with
+// connection reuse tuned by config. Batches send many requests to a single
+// backend, so more idle connections to it are kept than Go's default of 2.
+func newTransport(config Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.GetMaxIdleConnsPerHost()
+	if transport.MaxIdleConns < transport.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = transport.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = config.GetIdleConnTimeout()
+
+	// A keep-alive of 0 opens a new connection for every request
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: config.GetKeepAlive()}
+	if config.GetKeepAlive() == 0 {
+		dialer.KeepAlive = -1
+		transport.DisableKeepAlives = true
+	}
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
@@ -1,11 +1,17 @@
 package flux
 
 type Input struct {
-	Prompt             string `json:"prompt"`
-	Seed               *int   `json:"seed,omitempty"`
-	NumOutputs         int    `json:"num_outputs"`
-	AspectRatio        string `json:"aspect_ratio"`
-	OutputFormat       string `json:"output_format"`
-	OutputQuality      int    `json:"output_quality"`
-	DisableSafetyCheck bool   `json:"disable_safety_checker"`
+	Prompt             string  `json:"prompt"`
+	Model              string  `json:"model,omitempty"`
+	Seed               *int    `json:"seed,omitempty"`
+	NumOutputs         int     `json:"num_outputs"`
+	AspectRatio        string  `json:"aspect_ratio,omitempty"`
+	Width              int     `json:"width,omitempty"`
+	Height             int     `json:"height,omitempty"`
+	Image              string  `json:"image,omitempty"`
+	Guidance           float64 `json:"guidance,omitempty"`
+	NumInferenceSteps  int     `json:"num_inference_steps,omitempty"`
+	OutputFormat       string  `json:"output_format"`
+	OutputQuality      int     `json:"output_quality"`
+	DisableSafetyCheck bool    `json:"disable_safety_checker"`
 }
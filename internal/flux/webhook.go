@@ -0,0 +1,88 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds a single webhook notification, so a slow receiver
+// cannot hold up anything else
+const webhookTimeout = 10 * time.Second
+
+// Completion is the JSON body posted to the webhook when a generation
+// completes
+type Completion struct {
+	Prompt       string    `json:"prompt"`
+	URLs         []string  `json:"urls"`
+	Seed         int       `json:"seed"`
+	AspectRatio  string    `json:"aspect_ratio,omitempty"`
+	OutputFormat string    `json:"output_format,omitempty"`
+	Guidance     float64   `json:"guidance,omitempty"`
+	Steps        int       `json:"steps,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// NewCompletion describes a finished generation of prompt. Images the
+// backend returned inline have no URL to share and are left out.
+func NewCompletion(prompt string, opts GenerateOptions, result *GenerateResult) Completion {
+	urls := make([]string, 0, len(result.URLs))
+	for _, url := range result.URLs {
+		if !isDataURL(url) {
+			urls = append(urls, url)
+		}
+	}
+
+	format := opts.OutputFormat
+	if result.FormatFallback != "" {
+		format = fallbackFormat
+	}
+
+	return Completion{
+		Prompt:       prompt,
+		URLs:         urls,
+		Seed:         result.Seed,
+		AspectRatio:  opts.AspectRatio,
+		OutputFormat: format,
+		Guidance:     opts.Guidance,
+		Steps:        opts.Steps,
+		CompletedAt:  time.Now(),
+	}
+}
+
+// NotifyWebhook posts completion to the configured webhook URL. It does
+// nothing when no webhook is configured.
+func (c *Client) NotifyWebhook(ctx context.Context, completion Completion) error {
+	webhookURL := c.config.GetWebhookURL()
+	if webhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook failed: %w", &APIStatusError{Code: resp.StatusCode})
+	}
+	return nil
+}
@@ -0,0 +1,502 @@
+package i18n
+
+// german is the German translation
+var german = map[string]string{
+	// Generator controls
+	"Enter your prompt...": "Prompt eingeben...",
+	"Surprise Me":          "Überrasch mich",
+	"Fill in a random prompt. Edit wordbanks.json in the config directory to change the words.": "Einen zufälligen Prompt einfügen. Die Wörter stehen in wordbanks.json im Konfigurationsordner.",
+	"Generate":       "Generieren",
+	"Duplicate Last": "Letzte wiederholen",
+	"Run the last generation again with the same seed and settings (Ctrl+D)": "Die letzte Generierung mit demselben Seed und denselben Einstellungen wiederholen (Strg+D)",
+	"Aspect Ratio:": "Seitenverhältnis:",
+	"Images:":       "Bilder:",
+	"Append":        "Anhängen",
+	"Add new images to the existing results instead of replacing them": "Neue Bilder an die bisherigen Ergebnisse anhängen, statt sie zu ersetzen",
+	"Numbers": "Nummern",
+	"Label each image with its number in the batch": "Jedes Bild mit seiner Nummer im Durchlauf beschriften",
+	"Per Row:":              "Pro Zeile:",
+	"Sweep:":                "Serie:",
+	"Values, e.g. 1,42,100": "Werte, z. B. 1,42,100",
+	"None":                  "Keine",
+	"Seed per Image":        "Seed pro Bild",
+	"Aspect Ratio":          "Seitenverhältnis",
+	"Auto":                  "Auto",
+	"Comma-separated seeds or aspect ratios. Leave empty to sweep all aspect ratios. Seed per Image gives each image the next seed, random once the list runs out.": "Kommagetrennte Seeds oder Seitenverhältnisse. Leer lassen, um alle Seitenverhältnisse durchzugehen. Seed pro Bild gibt jedem Bild den nächsten Seed, danach zufällige.",
+	"Seed %d":          "Seed %d",
+	"Seed %d (random)": "Seed %d (zufällig)",
+	"Aspect ratio %s":  "Seitenverhältnis %s",
+
+	// Modes
+	"Generator": "Generator",
+	"Upscaler":  "Hochskalierer",
+	"Gallery":   "Galerie",
+	"Upscaler not configured. Set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.": "Hochskalierer nicht eingerichtet. UPSCALER_API_URL und UPSCALER_API_KEY in der .env-Datei setzen.",
+
+	// Menu
+	"More actions":             "Weitere Aktionen",
+	"Reset to Defaults":        "Auf Standardwerte zurücksetzen",
+	"Stop All":                 "Alle anhalten",
+	"Copy Request JSON":        "Anfrage-JSON kopieren",
+	"Show Last Response":       "Letzte Antwort zeigen",
+	"Last Response (%s)":       "Letzte Antwort (%s)",
+	"No response received yet": "Noch keine Antwort erhalten",
+	"Dry Run":                  "Probelauf",
+	"Dry run: Generate shows the request without sending it":                                 "Probelauf: Generieren zeigt die Anfrage, ohne sie zu senden",
+	"Dry run off: Generate sends requests again":                                             "Probelauf aus: Generieren sendet wieder Anfragen",
+	"Dry run: nothing was sent":                                                              "Probelauf: nichts wurde gesendet",
+	"A random seed is added to the request when it is sent.":                                 "Beim Senden wird der Anfrage ein zufälliger Seed hinzugefügt.",
+	"Request copied to clipboard":                                                            "Anfrage in die Zwischenablage kopiert",
+	"Request JSON copied to clipboard":                                                       "Anfrage-JSON in die Zwischenablage kopiert",
+	"Request JSON copied to clipboard without a seed; a random one is added when it is sent": "Anfrage-JSON ohne Seed in die Zwischenablage kopiert; beim Senden wird ein zufälliger hinzugefügt",
+	"Save Image":                              "Bild speichern",
+	"There are no results to save":            "Es gibt keine Ergebnisse zum Speichern",
+	"Response copied to clipboard":            "Antwort in die Zwischenablage kopiert",
+	"Export Session...":                       "Sitzung exportieren...",
+	"Import Session...":                       "Sitzung importieren...",
+	"Open Config Folder":                      "Konfigurationsordner öffnen",
+	"Open Cache Folder":                       "Cache-Ordner öffnen",
+	"Statistics":                              "Statistik",
+	"Keyboard Shortcuts":                      "Tastenkürzel",
+	"Prompt After Generating":                 "Prompt nach dem Generieren",
+	"Leave As Is":                             "Unverändert lassen",
+	"Clear":                                   "Leeren",
+	"Select All":                              "Alles auswählen",
+	"When a File Exists":                      "Wenn eine Datei existiert",
+	"Ask Before Replacing":                    "Vor dem Ersetzen fragen",
+	"Save with a Number":                      "Mit Nummer speichern",
+	"Also Save As":                            "Zusätzlich speichern als",
+	"Low Memory Mode":                         "Speichersparmodus",
+	"Auto-save All Generations":               "Alle Generierungen automatisch speichern",
+	"New images are saved to %s as they load": "Neue Bilder werden beim Laden in %s gespeichert",
+	"New images are only saved when you click Save": "Neue Bilder werden nur mit Speichern gespeichert",
+	"Error auto-saving image: %v":                   "Fehler beim automatischen Speichern des Bildes: %v",
+	"Saved":                                         "Gespeichert",
+	"Re-downloads images to save or copy them":      "Lädt Bilder zum Speichern oder Kopieren erneut herunter",
+	"Wheel Scrolls Results Sideways":                "Mausrad scrollt Ergebnisse seitwärts",
+	"UI Scale":                                      "Skalierung der Oberfläche",
+	"UI Scale %d%%":                                 "Oberfläche auf %d%% skalieren",
+	"Blur Sensitive Images":                         "Heikle Bilder weichzeichnen",
+	"New images flagged as sensitive are blurred until clicked": "Neue als heikel markierte Bilder bleiben bis zum Klick weichgezeichnet",
+	"Compare with Previous Batch":                               "Mit vorherigem Durchgang vergleichen",
+	"Generate twice to compare a batch with the previous one":   "Zweimal generieren, um einen Durchgang mit dem vorherigen zu vergleichen",
+	"Previous":                 "Vorher",
+	"Current":                  "Aktuell",
+	"Same prompt and settings": "Gleicher Prompt und gleiche Einstellungen",
+	"Changed: %s":              "Geändert: %s",
+	"Prompt":                   "Prompt",
+	"Aspect ratio":             "Seitenverhältnis",
+	"Images":                   "Bilder",
+	"Seed":                     "Seed",
+	"Guidance":                 "Guidance",
+	"Steps":                    "Schritte",
+	"Format":                   "Format",
+	"Base image":               "Ausgangsbild",
+	"yes":                      "ja",
+	"Play":                     "Abspielen",
+	"Pause":                    "Pausieren",
+	"Error playing %s: %v":     "Fehler beim Abspielen von %s: %v",
+	"Not available for animations and videos": "Für Animationen und Videos nicht verfügbar",
+	"Verify": "Überprüfen",
+	"Generate this image again from its saved seed and settings to check that the backend reproduces it": "Dieses Bild mit gespeichertem Seed und Einstellungen erneut generieren, um zu prüfen, ob das Backend es reproduziert",
+	"This image has no saved prompt to generate it again from":                                           "Zu diesem Bild ist kein Prompt gespeichert, mit dem es erneut generiert werden könnte",
+	"No seed was saved with this image, so it cannot be reproduced":                                      "Zu diesem Bild ist kein Seed gespeichert, daher kann es nicht reproduziert werden",
+	"Verifying %s with seed %d...":                                                                       "%s wird mit Seed %d überprüft...",
+	"Error verifying %s: %v":                                                                             "Fehler beim Überprüfen von %s: %v",
+	"Verify %s":                                                                                          "%s überprüfen",
+	"Regenerated":                                                                                        "Neu generiert",
+	"Differences":                                                                                        "Unterschiede",
+	"Close":                                                                                              "Schließen",
+	"Reproduced exactly: the regenerated file is identical, so the backend is deterministic for this seed.":                                                     "Exakt reproduziert: Die neu generierte Datei ist identisch, das Backend ist für diesen Seed deterministisch.",
+	"Reproduced: every pixel is identical, only the file encoding differs.":                                                                                     "Reproduziert: Alle Pixel sind identisch, nur die Kodierung der Datei unterscheidet sich.",
+	"Reproduced with small differences: %.2f%% of pixels changed slightly, likely from compression.":                                                            "Mit kleinen Abweichungen reproduziert: %.2f%% der Pixel haben sich leicht verändert, vermutlich durch Kompression.",
+	"Not reproduced: %.1f%% of pixels changed and the images look different (hash distance %d of 64). The backend does not reproduce this image from its seed.": "Nicht reproduziert: %.1f%% der Pixel haben sich verändert und die Bilder sehen verschieden aus (Hash-Abstand %d von 64). Das Backend reproduziert dieses Bild nicht aus seinem Seed.",
+	"The regenerated image had a different size and was scaled to compare it.":                                                                                  "Das neu generierte Bild hatte eine andere Größe und wurde für den Vergleich skaliert.",
+	"%d%% done":                       "%d%% erledigt",
+	"PNG Compression":                 "PNG-Kompression",
+	"Fastest Saves":                   "Schnellstes Speichern",
+	"Default":                         "Standard",
+	"Smallest Files":                  "Kleinste Dateien",
+	"PNG Compression: Fastest Saves":  "PNG-Kompression: Schnellstes Speichern",
+	"PNG Compression: Default":        "PNG-Kompression: Standard",
+	"PNG Compression: Smallest Files": "PNG-Kompression: Kleinste Dateien",
+	"Finalize Selected...":            "Auswahl finalisieren...",
+	"Finalize %d Selected...":         "%d ausgewählte finalisieren...",
+	"Generate the selected images again from their metadata with more steps and higher quality": "Die ausgewählten Bilder aus ihren Metadaten mit mehr Schritten und höherer Qualität erneut generieren",
+	"Select for finalizing at higher quality":                                                   "Zum Finalisieren in höherer Qualität auswählen",
+	"Images saved with more steps keep theirs":                                                  "Mit mehr Schritten gespeicherte Bilder behalten ihre",
+	"Output quality of lossy formats":                                                           "Ausgabequalität verlustbehafteter Formate",
+	"Ask for lossless PNG":                                                                      "Verlustfreies PNG anfordern",
+	"Replace the originals":                                                                     "Originale ersetzen",
+	"Otherwise the finalized images are saved beside the originals":                             "Sonst werden die finalisierten Bilder neben den Originalen gespeichert",
+	"Quality": "Qualität",
+	"Generates %d images again with their saved prompts and seeds, one at a time. Backends that ignore these settings return the same images, which are kept as they are.": "Generiert %d Bilder nacheinander erneut mit ihren gespeicherten Prompts und Seeds. Backends, die diese Einstellungen ignorieren, liefern dieselben Bilder, die unverändert bleiben.",
+	"Finalize at Higher Quality": "In höherer Qualität finalisieren",
+	"Finalize":                   "Finalisieren",
+	"Finalizing %d of %d: %s...": "Finalisiere %d von %d: %s...",
+	"Finalized %d images":        "%d Bilder finalisiert",
+	", %d unchanged because the backend ignored the higher settings": ", %d unverändert, weil das Backend die höheren Einstellungen ignoriert hat",
+	"Error finalizing %d images: %v":                                 "Fehler beim Finalisieren von %d Bildern: %v",
+	"API Headers...":                                                 "API-Header...",
+	"Edit API Headers":                                               "API-Header bearbeiten",
+	"API Headers":                                                    "API-Header",
+	"One per line, like X-Org-ID: my-org. Saved for the profile %q; FLUX_API_HEADERS overrides them when set.": "Einer pro Zeile, etwa X-Org-ID: my-org. Gespeichert für das Profil %q; FLUX_API_HEADERS hat Vorrang, wenn gesetzt.",
+	"Error loading headers: %v":                          "Fehler beim Laden der Header: %v",
+	"Error saving headers: %v":                           "Fehler beim Speichern der Header: %v",
+	"Saved %d API headers for the profile %q":            "%d API-Header für das Profil %q gespeichert",
+	"Image Loader":                                       "Bildlader",
+	"GDK, Falling Back to Go":                            "GDK, ersatzweise Go",
+	"GDK Only":                                           "Nur GDK",
+	"Go Only":                                            "Nur Go",
+	"Images load with the new choice from now on":        "Bilder werden ab jetzt mit der neuen Auswahl geladen",
+	"Load Images with GDK, Falling Back to Go":           "Bilder mit GDK laden, ersatzweise mit Go",
+	"Load Images with GDK Only":                          "Bilder nur mit GDK laden",
+	"Load Images with Go Only":                           "Bilder nur mit Go laden",
+	"New images flagged as sensitive are shown directly": "Neue als heikel markierte Bilder werden direkt gezeigt",
+	"Command Palette":                                    "Befehlspalette",
+
+	// Multiple prompts
+	"Generate Several Prompts...": "Mehrere Prompts generieren...",
+	"Generate Several Prompts":    "Mehrere Prompts generieren",
+	"Each prompt is generated with the current settings and its results are grouped under it.": "Jeder Prompt wird mit den aktuellen Einstellungen generiert, seine Ergebnisse werden darunter gruppiert.",
+	"Remove prompt": "Prompt entfernen",
+	"Add Prompt":    "Prompt hinzufügen",
+	"Prompt %d":     "Prompt %d",
+
+	// Command palette
+	"Commands":       "Befehle",
+	"Type a command": "Befehl eingeben",
+	"Focus Prompt":   "Zum Prompt",
+	"Open Generator": "Generator öffnen",
+	"Open Upscaler":  "Hochskalierer öffnen",
+	"Open Gallery":   "Galerie öffnen",
+
+	// Favorite prompts
+	"Pin or Unpin Prompt":                 "Prompt anheften oder lösen",
+	"Pin the prompt to the favorites bar": "Den Prompt an die Favoritenleiste anheften",
+	"Remove the prompt from favorites":    "Den Prompt aus den Favoriten entfernen",
+	"Remove from favorites":               "Aus den Favoriten entfernen",
+	"Enter a prompt to pin it":            "Einen Prompt eingeben, um ihn anzuheften",
+	"Pinned the prompt to favorites":      "Prompt an die Favoriten angeheftet",
+	"Removed the prompt from favorites":   "Prompt aus den Favoriten entfernt",
+	"Error saving favorites: %v":          "Fehler beim Speichern der Favoriten: %v",
+
+	// Statistics
+	"Images generated":        "Generierte Bilder",
+	"API calls":               "API-Aufrufe",
+	"Downloaded":              "Heruntergeladen",
+	"Average generation time": "Durchschnittliche Generierungsdauer",
+	"Most used aspect ratio":  "Häufigstes Seitenverhältnis",
+	"Reset the statistics?":   "Statistik zurücksetzen?",
+	"Every count and the average generation time start again from zero. This cannot be undone.": "Alle Zähler und die durchschnittliche Generierungsdauer beginnen wieder bei null. Dies kann nicht rückgängig gemacht werden.",
+
+	// Keyboard shortcuts
+	"Generation":                      "Generierung",
+	"Results":                         "Ergebnisse",
+	"Navigation":                      "Navigation",
+	"General":                         "Allgemein",
+	"Generate images from the prompt": "Bilder aus dem Prompt generieren",
+	"Generate from the expanded prompt editor":        "Aus dem erweiterten Prompt-Editor generieren",
+	"Run the last generation again":                   "Die letzte Generierung wiederholen",
+	"Reset the prompt and controls to their defaults": "Prompt und Einstellungen auf die Standardwerte zurücksetzen",
+	"Save the focused result, or the first":           "Das ausgewählte oder das erste Ergebnis speichern",
+	"Focus the prompt":                                "Den Prompt fokussieren",
+	"Show keyboard shortcuts":                         "Tastenkürzel anzeigen",
+	"Open the command palette":                        "Die Befehlspalette öffnen",
+
+	// Tray icon
+	"Show Tray Icon": "Symbol im Infobereich zeigen",
+	"Closing the window keeps generating in the background": "Schließen des Fensters generiert im Hintergrund weiter",
+	"Show Fluxxxer":                 "Fluxxxer zeigen",
+	"Quit":                          "Beenden",
+	"No generations pending":        "Keine Generierungen ausstehend",
+	"%d of %d generations finished": "%d von %d Generierungen fertig",
+	"Tray icon shown, closing the window keeps generating in the background": "Symbol im Infobereich gezeigt, Schließen des Fensters generiert im Hintergrund weiter",
+	"Tray icon removed, closing the window quits":                            "Symbol im Infobereich entfernt, Schließen des Fensters beendet",
+	"Error: no session bus to show the tray icon on: %v":                     "Fehler: kein Sitzungsbus für das Symbol im Infobereich: %v",
+	"Error: the tray icon lost its bus name":                                 "Fehler: das Symbol im Infobereich hat seinen Busnamen verloren",
+	"Error: no system tray found: %v":                                        "Fehler: kein Infobereich gefunden: %v",
+
+	// Results
+	"Save":        "Speichern",
+	"Copy":        "Kopieren",
+	"View":        "Ansehen",
+	"Upscale":     "Hochskalieren",
+	"Keep":        "Behalten",
+	"Copy Prompt": "Prompt kopieren",
+	"Copy Seed":   "Seed kopieren",
+	"Use Seed":    "Seed verwenden",
+	"Seed: ":      "Seed: ",
+	"Generate from this result's seed and settings until cleared": "Mit dem Seed und den Einstellungen dieses Ergebnisses generieren, bis es aufgehoben wird",
+	"Generate from this seed next, as a seed sweep of one":        "Als Nächstes mit diesem Seed generieren, als Serie mit einem Seed",
+	"Rotate":                     "Drehen",
+	"Rotate 90° clockwise":       "Um 90° im Uhrzeigersinn drehen",
+	"Flip H":                     "Spiegeln H",
+	"Flip horizontally":          "Horizontal spiegeln",
+	"Flip V":                     "Spiegeln V",
+	"Flip vertically":            "Vertikal spiegeln",
+	"Restore the original image": "Das ursprüngliche Bild wiederherstellen",
+
+	// Session archives
+	"Export Session":                  "Sitzung exportieren",
+	"Import Session":                  "Sitzung importieren",
+	"_Export":                         "_Exportieren",
+	"_Import":                         "_Importieren",
+	"_Cancel":                         "_Abbrechen",
+	"Session archives":                "Sitzungsarchive",
+	"There are no results to export":  "Keine Ergebnisse zum Exportieren",
+	"Exporting %d images...":          "%d Bilder werden exportiert...",
+	"Error exporting session: %v":     "Fehler beim Exportieren der Sitzung: %v",
+	"Session exported to: %s":         "Sitzung exportiert nach: %s",
+	"Error importing session: %v":     "Fehler beim Importieren der Sitzung: %v",
+	"Imported %d images into %s":      "%d Bilder nach %s importiert",
+	"Restore the session's controls?": "Einstellungen der Sitzung übernehmen?",
+	"The prompt, aspect ratio, seed and advanced settings of the session's latest result can replace the current ones.": "Prompt, Seitenverhältnis, Seed und erweiterte Einstellungen des neuesten Ergebnisses der Sitzung können die aktuellen ersetzen.",
+	"Keep Current": "Aktuelle behalten",
+	"Restore":      "Übernehmen",
+	"Restored the controls of the session's latest result": "Einstellungen des neuesten Ergebnisses der Sitzung übernommen",
+
+	// Annotations
+	"Annotate":                           "Beschriften",
+	"Annotate Image":                     "Bild beschriften",
+	"Arrow":                              "Pfeil",
+	"Rectangle":                          "Rechteck",
+	"Text":                               "Text",
+	"Undo":                               "Rückgängig",
+	"Cancel":                             "Abbrechen",
+	"Done":                               "Fertig",
+	"Label text, placed where you click": "Beschriftung, wird beim Klicken platziert",
+	"Mark up the image with arrows, rectangles and labels, added to it when saving": "Das Bild mit Pfeilen, Rechtecken und Beschriftungen markieren, die beim Speichern eingefügt werden",
+	"Annotations are added to the image when it is saved":                           "Die Beschriftungen werden beim Speichern in das Bild eingefügt",
+
+	// Tags
+	"Tags: %s":                   "Tags: %s",
+	"Add Tags":                   "Tags hinzufügen",
+	"Edit Tags":                  "Tags bearbeiten",
+	"No tags yet":                "Noch keine Tags",
+	"Remove tag":                 "Tag entfernen",
+	"Type a tag and press Enter": "Tag eingeben und Enter drücken",
+	"Error saving tags: %v":      "Fehler beim Speichern der Tags: %v",
+	"Tag this generation. Tags are saved with its images and can be searched in the gallery.": "Diese Generierung taggen. Tags werden mit den Bildern gespeichert und lassen sich in der Galerie durchsuchen.",
+
+	// Upscaler
+	"Drag and drop an image here to upscale it":         "Ein Bild hierher ziehen, um es hochzuskalieren",
+	"Or click the button below to select an image file": "Oder mit der Schaltfläche unten eine Bilddatei auswählen",
+	"Select Image":    "Bild auswählen",
+	"Upscale Options": "Hochskalierungsoptionen",
+	"Upscale Type:":   "Art:",
+	"Prompt:":         "Prompt:",
+	"Enter a prompt to guide upscaling (for conservative/creative modes)": "Prompt zur Steuerung der Hochskalierung (für die Modi conservative/creative)",
+
+	// Sensitive images
+	"Flagged as sensitive by the backend": "Vom Backend als heikel markiert",
+	"Click to reveal":                     "Zum Anzeigen klicken",
+	"Hide":                                "Verbergen",
+	"Blur the image again":                "Das Bild wieder weichzeichnen",
+
+	// Generation errors
+	"The backend allows at most %d images per request, the Images slider now stops there (%v)": "Das Backend erlaubt höchstens %d Bilder pro Anfrage, der Bilder-Regler endet jetzt dort (%v)",
+	"The backend refused the request, check FLUX_API_HEADERS (%v)":                             "Das Backend hat die Anfrage abgelehnt, FLUX_API_HEADERS prüfen (%v)",
+	"The backend is rate limiting requests, try again shortly (%v)":                            "Das Backend begrenzt die Anfragen, gleich noch einmal versuchen (%v)",
+	"The backend failed, try again later (%v)":                                                 "Das Backend ist fehlgeschlagen, später noch einmal versuchen (%v)",
+	"The backend wants you to log in, set the Authorization header in FLUX_API_HEADERS (%v)":   "Das Backend verlangt eine Anmeldung, den Authorization-Header in FLUX_API_HEADERS setzen (%v)",
+	"Could not reach the backend, check FLUX_API_URL and your connection (%v)":                 "Das Backend ist nicht erreichbar, FLUX_API_URL und die Verbindung prüfen (%v)",
+	"The backend sent a response that could not be read (%v)":                                  "Das Backend hat eine unlesbare Antwort gesendet (%v)",
+	"The backend returned no images":                                                           "Das Backend hat keine Bilder zurückgegeben",
+	"The backend returned no images, also after %d retries":                                    "Das Backend hat keine Bilder zurückgegeben, auch nach %d Wiederholungen",
+
+	// Status bar
+	"Copy Error":     "Fehler kopieren",
+	"Show in Folder": "Im Ordner zeigen",
+	"Open the file manager at the saved file": "Die gespeicherte Datei im Dateimanager zeigen",
+	"Error":                         "Fehler",
+	"Error: ":                       "Fehler: ",
+	"Error: %v":                     "Fehler: %v",
+	"Error: No file selected":       "Fehler: Keine Datei ausgewählt",
+	"Error loading image again: %v": "Fehler beim erneuten Laden des Bildes: %v",
+	"Error loading image #%d: %v":   "Fehler beim Laden von Bild #%d: %v",
+	"Error preparing image for upscaling: %v":               "Fehler beim Vorbereiten des Bildes zum Hochskalieren: %v",
+	"Error saving image: %v":                                "Fehler beim Speichern des Bildes: %v",
+	"Please enter a prompt":                                 "Bitte einen Prompt eingeben",
+	"Error copying to the clipboard: %v":                    "Fehler beim Kopieren in die Zwischenablage: %v",
+	"Image copied to clipboard":                             "Bild in die Zwischenablage kopiert",
+	"Prompt copied to clipboard":                            "Prompt in die Zwischenablage kopiert",
+	"Seed %s copied to clipboard":                           "Seed %s in die Zwischenablage kopiert",
+	"Generated %d images":                                   "%d Bilder generiert",
+	"Generated %d images%s":                                 "%d Bilder generiert%s",
+	"Sweep finished: %d runs%s":                             "Serie fertig: %d Durchläufe%s",
+	"Sweep finished: %d of %d runs failed%s":                "Serie fertig: %d von %d Durchläufen fehlgeschlagen%s",
+	" (%d duplicate results hidden)":                        " (%d doppelte Ergebnisse ausgeblendet)",
+	" (%s not supported by this backend, used PNG instead)": " (%s wird vom Backend nicht unterstützt, stattdessen PNG verwendet)",
+	" (retried %d times after empty results)":               " (%d Mal nach leeren Ergebnissen wiederholt)",
+
+	// Advanced settings and base image
+	"How closely images follow the prompt":  "Wie genau die Bilder dem Prompt folgen",
+	"More steps add detail but take longer": "Mehr Schritte bringen mehr Details, dauern aber länger",
+	"in steps of":                           "in Schritten von",
+	"Guidance:":                             "Guidance:",
+	"Steps:":                                "Schritte:",
+	"Advanced":                              "Erweitert",
+	"Guidance and inference steps":          "Guidance und Inferenzschritte",
+	"Load URL":                              "URL laden",
+	"Choose File...":                        "Datei wählen...",
+	"Base Image":                            "Ausgangsbild",
+	"Base Image ✓":                          "Ausgangsbild ✓",
+	"Start from an existing image (image-to-image)": "Von einem vorhandenen Bild ausgehen (Bild-zu-Bild)",
+	"Error: enter an http or https image URL":       "Fehler: Eine http- oder https-Bild-URL eingeben",
+	"Loading base image...":                         "Lade Ausgangsbild...",
+	"Error loading base image: %v":                  "Fehler beim Laden des Ausgangsbilds: %v",
+	"Select Base Image":                             "Ausgangsbild wählen",
+	"_Open":                                         "_Öffnen",
+	"Generating from base image %s":                 "Generiere aus dem Ausgangsbild %s",
+
+	// Generator status
+	"Error saving settings: %v": "Fehler beim Speichern der Einstellungen: %v",
+	"Offline mode - set FLUX_API_URL in your .env file to generate images": "Offline-Modus - FLUX_API_URL in der .env-Datei setzen, um Bilder zu generieren",
+	"Image Generator Mode": "Bildgenerator-Modus",
+	"Image Upscaler Mode - Drag and drop an image to upscale": "Hochskalier-Modus - Ein Bild zum Hochskalieren hineinziehen",
+	"Double-click to edit the prompt and regenerate":          "Doppelklicken, um den Prompt zu bearbeiten und neu zu generieren",
+	"Press Ctrl+Enter to generate":                            "Strg+Eingabe zum Generieren",
+	"Expand":                                                  "Erweitern",
+	"Edit the prompt over several lines, with emphasis and $variables highlighted": "Den Prompt über mehrere Zeilen bearbeiten, mit hervorgehobener Betonung und $Variablen",
+	"Checking backend connection...":                                               "Prüfe die Verbindung zum Backend...",
+	"Backend is reachable again":                                                   "Das Backend ist wieder erreichbar",
+	"Backend responded in %s (checked at %s)":                                      "Das Backend hat in %s geantwortet (geprüft um %s)",
+	"Generations take %s on average":                                               "Generierungen dauern im Schnitt %s",
+	"This will generate %d images (~%s)":                                           "Dies generiert %d Bilder (~%s)",
+	"Stop reusing the kept seed":                                                   "Den behaltenen Seed nicht mehr verwenden",
+	"Keeping seed %d":                                                              "Behalte Seed %d",
+	"Kept seed %d - edit the prompt and generate to refine it":                     "Seed %d behalten - Prompt bearbeiten und generieren, um das Bild zu verfeinern",
+	"Seed %d will be used for the next generation":                                 "Seed %d wird für die nächste Generierung verwendet",
+	"Images per row in the results":                                                "Bilder pro Zeile in den Ergebnissen",
+	"Low memory mode: new results are shown at up to %d pixels and downloaded again to save, copy, view or transform them": "Speichersparmodus: Neue Ergebnisse werden mit höchstens %d Pixeln angezeigt und zum Speichern, Kopieren, Ansehen oder Transformieren erneut heruntergeladen",
+	"Low memory mode off: new results are kept in memory for instant saving":                                               "Speichersparmodus aus: Neue Ergebnisse bleiben zum sofortigen Speichern im Speicher",
+	"Controls reset to their defaults":                 "Bedienelemente auf die Standardwerte zurückgesetzt",
+	"Nothing to duplicate yet":                         "Noch nichts zu wiederholen",
+	"Error: %v, using the bundled words":               "Fehler: %v, verwende die mitgelieferten Wörter",
+	"Retry":                                            "Erneut versuchen",
+	"Generate again with the edited prompt":            "Mit dem bearbeiteten Prompt erneut generieren",
+	"Dismiss":                                          "Ausblenden",
+	"Error: %v. Edit the prompt and press Retry.":      "Fehler: %v. Den Prompt bearbeiten und Erneut versuchen drücken.",
+	"The mouse wheel now scrolls the results sideways": "Das Mausrad blättert die Ergebnisse jetzt seitwärts",
+	"The mouse wheel now scrolls the results up and down, hold Shift to scroll sideways": "Das Mausrad blättert die Ergebnisse jetzt auf und ab, mit gedrückter Umschalttaste seitwärts",
+
+	// Comparing, viewing and saving results
+	"Compare": "Vergleichen",
+	"Compare with another result using a slider":                  "Mit einem anderen Ergebnis über einen Schieberegler vergleichen",
+	"Click Compare on another result to compare it with this one": "Bei einem anderen Ergebnis auf Vergleichen klicken, um es mit diesem zu vergleichen",
+	"Comparison cancelled":                                        "Vergleich abgebrochen",
+	"Left: %s    Right: %s":                                       "Links: %s    Rechts: %s",
+	"Compare Results":                                             "Ergebnisse vergleichen",
+	"Click to view full size":                                     "Klicken für volle Größe",
+	"Scroll or pinch to zoom, drag to pan":                        "Zum Zoomen scrollen oder Finger spreizen, zum Verschieben ziehen",
+	"copying":                                                     "Kopieren",
+	"viewing":                                                     "Anzeigen",
+	"Error %s image: %v":                                          "Fehler beim %s des Bildes: %v",
+	"Error transforming image: %v":                                "Fehler beim Transformieren des Bildes: %v",
+	"All images":                                                  "Alle Bilder",
+	"Image files":                                                 "Bilddateien",
+	"_Save":                                                       "_Speichern",
+	"Image saved to: %s":                                          "Bild gespeichert unter: %s",
+	"Replace %q?":                                                 "%q ersetzen?",
+	"A file with this name already exists. Replacing it overwrites its contents.": "Eine Datei mit diesem Namen existiert bereits. Beim Ersetzen wird ihr Inhalt überschrieben.",
+	"Replace":        "Ersetzen",
+	"Save cancelled": "Speichern abgebrochen",
+	"Error: No user directory is available for this folder": "Fehler: Für diesen Ordner ist kein Benutzerverzeichnis verfügbar",
+	"Error creating folder %s: %v":                          "Fehler beim Erstellen des Ordners %s: %v",
+	"Error opening folder %s: %v":                           "Fehler beim Öffnen des Ordners %s: %v",
+
+	// Gallery
+	"Refresh":                               "Aktualisieren",
+	"Open Folder":                           "Ordner öffnen",
+	"Error reading gallery: %v":             "Fehler beim Lesen der Galerie: %v",
+	"Gallery - no saved images in %s":       "Galerie - keine gespeicherten Bilder in %s",
+	"Tags":                                  "Tags",
+	"Edit the tags saved with this image":   "Die mit diesem Bild gespeicherten Tags bearbeiten",
+	"Error loading image: %v":               "Fehler beim Laden des Bildes: %v",
+	"Filter by prompt, name or tag":         "Nach Prompt, Name oder Tag filtern",
+	"Any Ratio":                             "Jedes Verhältnis",
+	"Any Format":                            "Jedes Format",
+	"Any Tag":                               "Jeder Tag",
+	"Any Time":                              "Jederzeit",
+	"Today":                                 "Heute",
+	"Past Week":                             "Letzte Woche",
+	"Past Month":                            "Letzter Monat",
+	"Gallery - %d saved images":             "Galerie - %d gespeicherte Bilder",
+	"Gallery - %d of %d saved images match": "Galerie - %d von %d gespeicherten Bildern passen",
+
+	// Presets and variables
+	"Preset name":  "Name der Vorlage",
+	"Save Current": "Aktuelle speichern",
+	"Presets":      "Vorlagen",
+	"Save or apply the prompt and every generation setting together": "Prompt und alle Generierungseinstellungen gemeinsam speichern oder anwenden",
+	"No presets saved yet":                "Noch keine Vorlagen gespeichert",
+	"%s — %s, %d images":                  "%s — %s, %d Bilder",
+	"Delete":                              "Löschen",
+	"Enter a name for the preset":         "Einen Namen für die Vorlage eingeben",
+	"Error loading presets: %v":           "Fehler beim Laden der Vorlagen: %v",
+	"Error saving preset: %v":             "Fehler beim Speichern der Vorlage: %v",
+	"Saved preset %q":                     "Vorlage %q gespeichert",
+	"Error deleting preset: %v":           "Fehler beim Löschen der Vorlage: %v",
+	"Deleted preset %q":                   "Vorlage %q gelöscht",
+	"Applied preset %q":                   "Vorlage %q angewendet",
+	"Error saving variables: %v":          "Fehler beim Speichern der Variablen: %v",
+	"Variables":                           "Variablen",
+	"Define $variables to use in prompts": "$Variablen für Prompts festlegen",
+
+	// Queue
+	"Queue":              "Warteschlange",
+	"Queue (%d pending)": "Warteschlange (%d offen)",
+	"Queued":             "Wartend",
+	"Running":            "Läuft",
+	"Failed":             "Fehlgeschlagen",
+	"Canceled":           "Abgebrochen",
+	"Unknown":            "Unbekannt",
+	"Clear Finished":     "Fertige entfernen",
+	"Clear Queue":        "Warteschlange leeren",
+	"Cancel the running generation and remove every queued one, keeping the results shown": "Die laufende Generierung abbrechen und alle wartenden entfernen; angezeigte Ergebnisse bleiben erhalten",
+	"Remove":                                "Entfernen",
+	"Generating %s (%d/%d)...":              "Generiere %s (%d/%d)...",
+	"Generating images...":                  "Generiere Bilder...",
+	"Error loading pending generations: %v": "Fehler beim Laden der offenen Generierungen: %v",
+	"Resuming %d unfinished generation(s)":  "Setze %d unfertige Generierung(en) fort",
+	"Error loading queue: %v":               "Fehler beim Laden der Warteschlange: %v",
+	"Error saving queue: %v":                "Fehler beim Speichern der Warteschlange: %v",
+	"Resume the queue?":                     "Warteschlange fortsetzen?",
+	"%d generation(s) were still queued when Fluxxxer last closed.": "%d Generierung(en) warteten noch, als Fluxxxer zuletzt geschlossen wurde.",
+	"Discard": "Verwerfen",
+	"Resume":  "Fortsetzen",
+	"Error: %d queued generation(s) left out, their base image could not be loaded: %v": "Fehler: %d Generierung(en) der Warteschlange ausgelassen, ihr Ausgangsbild konnte nicht geladen werden: %v",
+	"Resuming %d queued generation(s)":                                                  "Setze %d wartende Generierung(en) fort",
+	"Removed %d queued generation(s)":                                                   "%d wartende Generierung(en) entfernt",
+	"Nothing to stop":                                                                   "Nichts anzuhalten",
+	"Stop all %d queued generations?":                                                   "Alle %d wartenden Generierungen anhalten?",
+	"The running generation is canceled and the queue is cleared. Results already shown are kept.": "Die laufende Generierung wird abgebrochen und die Warteschlange geleert. Bereits angezeigte Ergebnisse bleiben erhalten.",
+	"Keep Generating": "Weiter generieren",
+	"Stopped the running generation and removed %d queued generation(s)": "Laufende Generierung angehalten und %d wartende Generierung(en) entfernt",
+	"Reset": "Zurücksetzen",
+
+	// Upscaler dialogs
+	"Select Image to Upscale": "Bild zum Hochskalieren wählen",
+	"Upscaler not configured. Please set UPSCALER_API_URL and UPSCALER_API_KEY in your .env file.": "Hochskalierer nicht eingerichtet. Bitte UPSCALER_API_URL und UPSCALER_API_KEY in der .env-Datei setzen.",
+	"File is not a supported image format: %s":                                                     "Die Datei hat kein unterstütztes Bildformat: %s",
+	"Upscale Image":      "Bild hochskalieren",
+	"Image Preview":      "Bildvorschau",
+	"Output Format:":     "Ausgabeformat:",
+	"Upscaling image...": "Skaliere Bild hoch...",
+	"Warning: Image is large (%d MB). Server may run out of memory.": "Warnung: Das Bild ist groß (%d MB). Dem Server könnte der Speicher ausgehen.",
+	"Error loading upscaled image: %v":                               "Fehler beim Laden des hochskalierten Bildes: %v",
+	"Error: No upscaled image URL returned":                          "Fehler: Keine URL für das hochskalierte Bild erhalten",
+	"Loading upscaled image...":                                      "Lade hochskaliertes Bild...",
+	"Downloading upscaled image...":                                  "Lade hochskaliertes Bild herunter...",
+	"Error creating temporary file: %v":                              "Fehler beim Erstellen der temporären Datei: %v",
+	"Error downloading upscaled image: %v":                           "Fehler beim Herunterladen des hochskalierten Bildes: %v",
+	"Error saving upscaled image: %v":                                "Fehler beim Speichern des hochskalierten Bildes: %v",
+	"Upscaled Image":                                                 "Hochskaliertes Bild",
+	"Save As...":                                                     "Speichern unter...",
+	"Copy to Clipboard":                                              "In die Zwischenablage kopieren",
+	"Upscaled image copied to clipboard":                             "Hochskaliertes Bild in die Zwischenablage kopiert",
+	"Upscaling completed successfully":                               "Hochskalieren erfolgreich abgeschlossen",
+	"Save Upscaled Image":                                            "Hochskaliertes Bild speichern",
+	"Upscaled image saved to: %s":                                    "Hochskaliertes Bild gespeichert unter: %s",
+}
@@ -0,0 +1,60 @@
+// Package i18n translates user-facing strings into the user's language.
+// Strings are looked up by their English text in a catalog per locale,
+// falling back to English when no translation exists.
+package i18n
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// catalogs holds the translations of each locale, keyed by English text.
+// Locales are language codes such as "de", optionally with a region such
+// as "de_AT" for regional differences.
+var catalogs = map[string]map[string]string{
+	"de": german,
+}
+
+// catalog returns the translation catalog of the user's locale, nil for
+// English. It is looked up on first use, after the .env files have set
+// FLUX_LANG.
+var catalog = sync.OnceValue(userCatalog)
+
+// userCatalog returns the catalog of the locale set in the environment
+func userCatalog() map[string]string {
+	return catalogFor(Locale())
+}
+
+// Locale returns the user's locale from the environment, like gettext:
+// FLUX_LANG first, then LANGUAGE, LC_ALL, LC_MESSAGES and LANG
+func Locale() string {
+	for _, name := range []string{"FLUX_LANG", "LANGUAGE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			// LANGUAGE may list several locales in order of preference
+			return strings.Split(value, ":")[0]
+		}
+	}
+	return ""
+}
+
+// catalogFor returns the catalog of locale, such as "de_DE.UTF-8", trying
+// the locale with its region before the language alone
+func catalogFor(locale string) map[string]string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if c, ok := catalogs[locale]; ok {
+		return c
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	return catalogs[language]
+}
+
+// Tr returns message in the user's language. Format strings are translated
+// before formatting, so pass the format rather than the formatted text.
+func Tr(message string) string {
+	if translated, ok := catalog()[message]; ok {
+		return translated
+	}
+	return message
+}
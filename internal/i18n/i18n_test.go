@@ -0,0 +1,36 @@
+package i18n
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCatalogFor(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   bool // A German catalog is expected
+	}{
+		{"de", true},
+		{"de_DE.UTF-8", true},
+		{"de_AT@euro", true},
+		{"en_US.UTF-8", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := catalogFor(tt.locale) != nil; got != tt.want {
+			t.Errorf("catalogFor(%q) found a catalog: %v, want %v", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestTrReadsLocaleOnFirstUse(t *testing.T) {
+	defer func(c func() map[string]string) { catalog = c }(catalog)
+	catalog = sync.OnceValue(userCatalog)
+
+	// Set after the package was initialized, as loading .env files does
+	t.Setenv("FLUX_LANG", "de")
+	if got := Tr("Generate"); got != "Generieren" {
+		t.Errorf("got %q, want the German translation", got)
+	}
+}